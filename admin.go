@@ -0,0 +1,329 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/blinklabs-io/dingo/connmanager"
+	"github.com/blinklabs-io/dingo/peergov"
+)
+
+const (
+	adminApiReadHeaderTimeout = 10 * time.Second
+	adminApiShutdownTimeout   = 5 * time.Second
+	adminApiMaxBodySize       = 1 << 16
+)
+
+type adminApiTip struct {
+	Slot        uint64 `json:"slot"`
+	Hash        string `json:"hash"`
+	BlockNumber uint64 `json:"block_number"`
+}
+
+type adminApiPeer struct {
+	Address        string    `json:"address"`
+	ConnectionId   string    `json:"connection_id"`
+	Sharable       bool      `json:"sharable"`
+	ConnectedSince time.Time `json:"connected_since"`
+}
+
+type adminApiPeers struct {
+	Inbound  []adminApiPeer `json:"inbound"`
+	Outbound []adminApiPeer `json:"outbound"`
+}
+
+type adminApiMempoolTx struct {
+	Hash     string    `json:"hash"`
+	Type     uint      `json:"type"`
+	Size     int       `json:"size"`
+	Fee      uint64    `json:"fee"`
+	LastSeen time.Time `json:"last_seen"`
+}
+
+type adminApiIntegrityCheck struct {
+	Ok       bool     `json:"ok"`
+	Problems []string `json:"problems"`
+}
+
+type adminApiAddPeerRequest struct {
+	Address string `json:"address"`
+}
+
+type adminApiCloseConnectionRequest struct {
+	RemoteAddr string `json:"remote_addr"`
+	Reason     string `json:"reason"`
+}
+
+type adminApiError struct {
+	Error string `json:"error"`
+}
+
+// startAdminApi starts the embedded HTTP admin API, if configured. The server is stopped gracefully on node shutdown
+func (n *Node) startAdminApi() error {
+	if n.config.adminApiAddress == "" {
+		return nil
+	}
+	server := &http.Server{
+		Handler:           n.adminApiHandler(),
+		ReadHeaderTimeout: adminApiReadHeaderTimeout,
+	}
+	listener, err := net.Listen("tcp", n.config.adminApiAddress)
+	if err != nil {
+		return fmt.Errorf("failed to start admin API listener: %w", err)
+	}
+	n.config.logger.Info(
+		"serving admin API on "+listener.Addr().String(),
+		"component", "admin",
+	)
+	go func() {
+		if err := server.Serve(listener); err != nil &&
+			!errors.Is(err, http.ErrServerClosed) {
+			n.config.logger.Error(
+				fmt.Sprintf("admin API server failed: %s", err),
+				"component", "admin",
+			)
+		}
+	}()
+	n.shutdownFuncs = append(
+		n.shutdownFuncs,
+		func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, adminApiShutdownTimeout)
+			defer cancel()
+			return server.Shutdown(ctx)
+		},
+	)
+	return nil
+}
+
+// adminApiHandler returns the admin API request handler, including authentication
+func (n *Node) adminApiHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /tip", n.adminApiHandleTip)
+	mux.HandleFunc("GET /peers", n.adminApiHandlePeers)
+	mux.HandleFunc("POST /peers", n.adminApiHandleAddPeer)
+	mux.HandleFunc("DELETE /peers/{address}", n.adminApiHandleRemovePeer)
+	mux.HandleFunc("GET /mempool", n.adminApiHandleMempool)
+	mux.HandleFunc("POST /connections/close", n.adminApiHandleCloseConnection)
+	mux.HandleFunc("GET /db/integrity", n.adminApiHandleIntegrityCheck)
+	return n.adminApiAuth(mux)
+}
+
+// adminApiValidateAddress checks that the admin API isn't exposed beyond the local host without a token
+func adminApiValidateAddress(address string, token string) error {
+	if address == "" || token != "" {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return fmt.Errorf("a token is required for non-loopback address %q", address)
+}
+
+// adminApiAuth requires the configured bearer token, if any, on all requests
+func (n *Node) adminApiAuth(next http.Handler) http.Handler {
+	if n.config.adminApiToken == "" {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok ||
+			subtle.ConstantTimeCompare([]byte(token), []byte(n.config.adminApiToken)) != 1 {
+			adminApiWriteError(w, http.StatusUnauthorized, errors.New("unauthorized"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (n *Node) adminApiHandleTip(w http.ResponseWriter, r *http.Request) {
+	tip, err := n.Tip()
+	if err != nil {
+		adminApiWriteError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	adminApiWriteJson(
+		w,
+		http.StatusOK,
+		adminApiTip{
+			Slot:        tip.Point.Slot,
+			Hash:        hex.EncodeToString(tip.Point.Hash),
+			BlockNumber: tip.BlockNumber,
+		},
+	)
+}
+
+func (n *Node) adminApiHandlePeers(w http.ResponseWriter, r *http.Request) {
+	resp := adminApiPeers{
+		Inbound:  adminApiPeerList(n.InboundPeers()),
+		Outbound: adminApiPeerList(n.OutboundPeers()),
+	}
+	adminApiWriteJson(w, http.StatusOK, resp)
+}
+
+func (n *Node) adminApiHandleAddPeer(w http.ResponseWriter, r *http.Request) {
+	var req adminApiAddPeerRequest
+	if err := adminApiReadJson(w, r, &req); err != nil {
+		adminApiWriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if _, _, err := net.SplitHostPort(req.Address); err != nil {
+		adminApiWriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := n.AddPeer(req.Address); err != nil {
+		adminApiWriteError(w, adminApiErrorStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+}
+
+func (n *Node) adminApiHandleRemovePeer(w http.ResponseWriter, r *http.Request) {
+	if err := n.RemovePeer(r.PathValue("address")); err != nil {
+		adminApiWriteError(w, adminApiErrorStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (n *Node) adminApiHandleMempool(w http.ResponseWriter, r *http.Request) {
+	txs := n.MempoolTransactions()
+	resp := make([]adminApiMempoolTx, 0, len(txs))
+	for _, tx := range txs {
+		resp = append(
+			resp,
+			adminApiMempoolTx{
+				Hash:     tx.Hash,
+				Type:     tx.Type,
+				Size:     len(tx.Cbor),
+				Fee:      tx.Fee,
+				LastSeen: tx.LastSeen,
+			},
+		)
+	}
+	adminApiWriteJson(w, http.StatusOK, resp)
+}
+
+func (n *Node) adminApiHandleCloseConnection(w http.ResponseWriter, r *http.Request) {
+	var req adminApiCloseConnectionRequest
+	if err := adminApiReadJson(w, r, &req); err != nil {
+		adminApiWriteError(w, http.StatusBadRequest, err)
+		return
+	}
+	if req.Reason == "" {
+		req.Reason = "closed via admin API"
+	}
+	if n.connManager == nil {
+		adminApiWriteError(w, http.StatusServiceUnavailable, ErrNodeNotRunning)
+		return
+	}
+	conn, ok := n.connManager.GetConnectionByRemoteAddr(req.RemoteAddr)
+	if !ok {
+		adminApiWriteError(
+			w,
+			http.StatusNotFound,
+			connmanager.ErrConnectionNotFound,
+		)
+		return
+	}
+	if err := n.CloseConnection(conn.Id(), req.Reason); err != nil {
+		adminApiWriteError(w, adminApiErrorStatus(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (n *Node) adminApiHandleIntegrityCheck(w http.ResponseWriter, r *http.Request) {
+	problems, err := n.CheckMetadataIntegrity()
+	if err != nil {
+		adminApiWriteError(w, adminApiErrorStatus(err), err)
+		return
+	}
+	adminApiWriteJson(
+		w,
+		http.StatusOK,
+		adminApiIntegrityCheck{
+			Ok:       len(problems) == 0,
+			Problems: problems,
+		},
+	)
+}
+
+func adminApiPeerList(peers []PeerInfo) []adminApiPeer {
+	ret := make([]adminApiPeer, 0, len(peers))
+	for _, peer := range peers {
+		ret = append(
+			ret,
+			adminApiPeer{
+				Address:        peer.Address,
+				ConnectionId:   peer.ConnectionId.String(),
+				Sharable:       peer.Sharable,
+				ConnectedSince: peer.ConnectedSince,
+			},
+		)
+	}
+	return ret
+}
+
+// adminApiErrorStatus maps errors from the Node methods to HTTP status codes
+func adminApiErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ErrNodeNotRunning):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, peergov.ErrPeerNotFound),
+		errors.Is(err, connmanager.ErrConnectionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, peergov.ErrPeerExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func adminApiReadJson(w http.ResponseWriter, r *http.Request, dest any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, adminApiMaxBodySize)
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(dest); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func adminApiWriteJson(w http.ResponseWriter, status int, data any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(data)
+}
+
+func adminApiWriteError(w http.ResponseWriter, status int, err error) {
+	adminApiWriteJson(w, status, adminApiError{Error: err.Error()})
+}
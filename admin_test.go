@@ -0,0 +1,126 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/peergov"
+)
+
+func TestAdminApiValidateAddress(t *testing.T) {
+	testDefs := []struct {
+		address     string
+		token       string
+		expectError bool
+	}{
+		{address: ""},
+		{address: "127.0.0.1:8080"},
+		{address: "[::1]:8080"},
+		{address: "localhost:8080"},
+		{address: "0.0.0.0:8080", expectError: true},
+		{address: ":8080", expectError: true},
+		{address: "192.0.2.1:8080", expectError: true},
+		{address: "0.0.0.0:8080", token: "secret"},
+		{address: "bad-address", expectError: true},
+	}
+	for _, testDef := range testDefs {
+		err := adminApiValidateAddress(testDef.address, testDef.token)
+		if testDef.expectError && err == nil {
+			t.Errorf("did not get expected error for address %q", testDef.address)
+		} else if !testDef.expectError && err != nil {
+			t.Errorf("unexpected error for address %q: %s", testDef.address, err)
+		}
+	}
+}
+
+func TestAdminApiAuth(t *testing.T) {
+	n := &Node{
+		config: Config{
+			adminApiToken: "secret",
+		},
+	}
+	handler := n.adminApiHandler()
+	testDefs := []struct {
+		authHeader     string
+		expectedStatus int
+	}{
+		{expectedStatus: http.StatusUnauthorized},
+		{authHeader: "Bearer wrong", expectedStatus: http.StatusUnauthorized},
+		{authHeader: "secret", expectedStatus: http.StatusUnauthorized},
+		{authHeader: "Bearer secret", expectedStatus: http.StatusOK},
+	}
+	for _, testDef := range testDefs {
+		req := httptest.NewRequest(http.MethodGet, "/peers", nil)
+		if testDef.authHeader != "" {
+			req.Header.Set("Authorization", testDef.authHeader)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != testDef.expectedStatus {
+			t.Errorf(
+				"did not get expected status with auth header %q: got %d, expected %d",
+				testDef.authHeader,
+				rec.Code,
+				testDef.expectedStatus,
+			)
+		}
+	}
+}
+
+func TestAdminApiHandlers(t *testing.T) {
+	n := &Node{
+		peerGov: peergov.NewPeerGovernor(peergov.PeerGovernorConfig{}),
+	}
+	handler := n.adminApiHandler()
+	testDefs := []struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		// The ledger isn't running
+		{method: http.MethodGet, path: "/tip", expectedStatus: http.StatusServiceUnavailable},
+		{method: http.MethodGet, path: "/peers", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/mempool", expectedStatus: http.StatusOK},
+		{method: http.MethodPost, path: "/peers", body: `{"address":"192.0.2.1:3001"}`, expectedStatus: http.StatusCreated},
+		{method: http.MethodPost, path: "/peers", body: `{"address":"192.0.2.1:3001"}`, expectedStatus: http.StatusConflict},
+		{method: http.MethodPost, path: "/peers", body: `{"address":"192.0.2.1"}`, expectedStatus: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/peers", body: `{"bogus":true}`, expectedStatus: http.StatusBadRequest},
+		{method: http.MethodDelete, path: "/peers/192.0.2.1:3001", expectedStatus: http.StatusNoContent},
+		{method: http.MethodDelete, path: "/peers/192.0.2.1:3001", expectedStatus: http.StatusNotFound},
+		// The connection manager isn't running
+		{method: http.MethodPost, path: "/connections/close", body: `{"remote_addr":"192.0.2.1:3001"}`, expectedStatus: http.StatusServiceUnavailable},
+		{method: http.MethodGet, path: "/unknown", expectedStatus: http.StatusNotFound},
+	}
+	for _, testDef := range testDefs {
+		req := httptest.NewRequest(testDef.method, testDef.path, strings.NewReader(testDef.body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		if rec.Code != testDef.expectedStatus {
+			t.Errorf(
+				"did not get expected status for %s %s: got %d, expected %d: %s",
+				testDef.method,
+				testDef.path,
+				rec.Code,
+				testDef.expectedStatus,
+				rec.Body.String(),
+			)
+		}
+	}
+}
@@ -0,0 +1,150 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/blinklabs-io/dingo/event"
+	"github.com/blinklabs-io/dingo/ledger"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	gledger "github.com/blinklabs-io/gouroboros/ledger"
+)
+
+// Size of the channel buffer for filtered block subscriptions
+const blockSubscriptionQueueSize = 100
+
+type BlockEvent = ledger.BlockEvent
+
+// BlockFilter specifies which blocks are delivered by Node.SubscribeBlocks. A block matches if any transaction in it
+// matches any of the specified criteria. An empty filter matches all blocks
+type BlockFilter struct {
+	// Addresses matches transactions with an output to any of the bech32 addresses
+	Addresses []string
+	// PolicyIds matches transactions that mint or output assets under any of the hex-encoded policy IDs
+	PolicyIds []string
+	// MetadataLabels matches transactions with metadata under any of the labels
+	MetadataLabels []uint64
+}
+
+func (f BlockFilter) isEmpty() bool {
+	return len(f.Addresses) == 0 &&
+		len(f.PolicyIds) == 0 &&
+		len(f.MetadataLabels) == 0
+}
+
+// matchBlock returns whether any transaction in the block matches the filter
+func (f BlockFilter) matchBlock(block gledger.Block) bool {
+	if f.isEmpty() {
+		return true
+	}
+	if block == nil {
+		return false
+	}
+	for _, tx := range block.Transactions() {
+		if f.matchTx(tx) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f BlockFilter) matchTx(tx gledger.Transaction) bool {
+	if len(f.Addresses) > 0 || len(f.PolicyIds) > 0 {
+		for _, output := range tx.Outputs() {
+			if len(f.Addresses) > 0 &&
+				slices.Contains(f.Addresses, output.Address().String()) {
+				return true
+			}
+			if assets := output.Assets(); assets != nil &&
+				f.matchPolicies(assets.Policies()) {
+				return true
+			}
+		}
+		if mint := tx.AssetMint(); mint != nil &&
+			f.matchPolicies(mint.Policies()) {
+			return true
+		}
+	}
+	if len(f.MetadataLabels) > 0 {
+		if metadata := tx.Metadata(); metadata != nil {
+			// Only decode the metadata labels and not the values
+			var tmpMetadata map[uint64]cbor.RawMessage
+			if _, err := cbor.Decode(metadata.Cbor(), &tmpMetadata); err == nil {
+				for _, label := range f.MetadataLabels {
+					if _, ok := tmpMetadata[label]; ok {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+func (f BlockFilter) matchPolicies(policies []gledger.Blake2b224) bool {
+	for _, policy := range policies {
+		if slices.Contains(f.PolicyIds, policy.String()) {
+			return true
+		}
+	}
+	return false
+}
+
+// SubscribeBlocks returns a channel that receives an event for each block applied to the ledger that matches the
+// filter, along with a function to cancel the subscription. Blocks are only decoded and filtered while there is an
+// active subscription. A consumer that falls too far behind is detached rather than holding up block processing, in
+// which case the channel is closed
+func (n *Node) SubscribeBlocks(filter BlockFilter) (<-chan BlockEvent, func()) {
+	retCh := make(chan BlockEvent, blockSubscriptionQueueSize)
+	doneCh := make(chan struct{})
+	subId, evtCh := n.eventBus.Subscribe(
+		ledger.BlockEventType,
+		event.WithOverflowPolicy(event.OverflowDetach),
+	)
+	go func() {
+		defer close(retCh)
+		for {
+			var evt event.Event
+			var ok bool
+			select {
+			case <-doneCh:
+				return
+			case evt, ok = <-evtCh:
+				if !ok {
+					return
+				}
+			}
+			blockEvt, ok := evt.Data.(ledger.BlockEvent)
+			if !ok || !filter.matchBlock(blockEvt.Block) {
+				continue
+			}
+			select {
+			case <-doneCh:
+				return
+			case retCh <- blockEvt:
+			}
+		}
+	}()
+	var cancelOnce sync.Once
+	cancelFunc := func() {
+		cancelOnce.Do(func() {
+			n.eventBus.Unsubscribe(ledger.BlockEventType, subId)
+			close(doneCh)
+		})
+	}
+	return retCh, cancelFunc
+}
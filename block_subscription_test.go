@@ -0,0 +1,62 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/dingo/event"
+	"github.com/blinklabs-io/dingo/ledger"
+)
+
+func TestSubscribeBlocksSlowConsumerDetached(t *testing.T) {
+	n := &Node{
+		eventBus: event.NewEventBus(nil),
+	}
+	blockCh, cancelFunc := n.SubscribeBlocks(BlockFilter{})
+	defer cancelFunc()
+	// Publish more events than the subscription can buffer without reading any of them
+	publishDone := make(chan struct{})
+	go func() {
+		defer close(publishDone)
+		for i := range blockSubscriptionQueueSize + event.EventQueueSize + 10 {
+			n.eventBus.Publish(
+				ledger.BlockEventType,
+				event.NewEvent(
+					ledger.BlockEventType,
+					ledger.BlockEvent{BlockNumber: uint64(i)}, // #nosec G115
+				),
+			)
+		}
+	}()
+	select {
+	case <-publishDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("publishing was blocked by a slow block subscriber")
+	}
+	// The subscription should have been detached, which closes the channel after the buffered events
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case _, ok := <-blockCh:
+			if !ok {
+				return
+			}
+		case <-timeout:
+			t.Fatal("block subscription channel was not closed after detach")
+		}
+	}
+}
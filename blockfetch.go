@@ -15,9 +15,12 @@
 package dingo
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
+	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/dingo/database/immutable"
 	"github.com/blinklabs-io/dingo/event"
 	"github.com/blinklabs-io/dingo/ledger"
 	ouroboros "github.com/blinklabs-io/gouroboros"
@@ -26,6 +29,10 @@ import (
 	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
 )
 
+const (
+	maxBlockfetchBatchSize = 5000
+)
+
 func (n *Node) blockfetchServerConnOpts() []blockfetch.BlockFetchOptionFunc {
 	return []blockfetch.BlockFetchOptionFunc{
 		blockfetch.WithRequestRangeFunc(n.blockfetchServerRequestRange),
@@ -39,8 +46,15 @@ func (n *Node) blockfetchClientConnOpts() []blockfetch.BlockFetchOptionFunc {
 		blockfetch.WithBatchStartTimeout(2 * time.Second),
 		blockfetch.WithBlockTimeout(2 * time.Second),
 		// Set the recv queue size to 2x our block batch size
-		blockfetch.WithRecvQueueSize(1000),
+		blockfetch.WithRecvQueueSize(n.blockfetchBatchSize() * 2),
+	}
+}
+
+func (n *Node) blockfetchBatchSize() int {
+	if n.config.blockfetchBatchSize > 0 {
+		return n.config.blockfetchBatchSize
 	}
+	return ledger.DefaultBlockfetchBatchSize
 }
 
 func (n *Node) blockfetchServerRequestRange(
@@ -48,43 +62,147 @@ func (n *Node) blockfetchServerRequestRange(
 	start ocommon.Point,
 	end ocommon.Point,
 ) error {
+	// Serve immutable blocks directly from the on-disk ImmutableDB when available
+	if n.blockfetchServerRequestRangeImmutable(ctx, start, end) {
+		return nil
+	}
+	// Send NoBlocks for ranges starting before our retained block window
+	if start.Slot < n.blockRetentionStartSlot() {
+		return ctx.Server.NoBlocks()
+	}
 	// TODO: check if we have requested block range available and send NoBlocks if not (#397)
 	chainIter, err := n.ledgerState.GetChainFromPoint(start, true)
 	if err != nil {
 		return err
 	}
 	// Start async process to send requested block range
+	go n.blockfetchServerSendRange(ctx.Server, chainIter, end)
+	return nil
+}
+
+// blockfetchRangeServer is the subset of the blockfetch server used to send a block range
+type blockfetchRangeServer interface {
+	NoBlocks() error
+	StartBatch() error
+	Block(blockType uint, blockData []byte) error
+	BatchDone() error
+}
+
+// blockfetchServerSendRange sends the blocks from the chain iterator up to the end point. Once the batch has been
+// started, it's always finished with BatchDone, even if we fail to read a block, so that the client doesn't wait on
+// us forever
+func (n *Node) blockfetchServerSendRange(
+	server blockfetchRangeServer,
+	chainIter *chain.ChainIterator,
+	end ocommon.Point,
+) {
+	defer chainIter.Cancel()
+	// Check that the first block is still available before starting the batch
+	next, err := chainIter.Next(false)
+	if errors.Is(err, chain.ErrBlockPruned) {
+		_ = server.NoBlocks()
+		return
+	}
+	if err := server.StartBatch(); err != nil {
+		return
+	}
+	for {
+		if err != nil {
+			if !errors.Is(err, chain.ErrIteratorChainTip) {
+				n.config.logger.Error(
+					"failed to read block for blockfetch",
+					"component", "node",
+					"error", err,
+				)
+			}
+			break
+		}
+		if next == nil {
+			break
+		}
+		if next.Block.Slot > end.Slot {
+			break
+		}
+		blockBytes := next.Block.Cbor
+		if err := server.Block(
+			next.Block.Type,
+			blockBytes,
+		); err != nil {
+			// TODO: push this error somewhere (#398)
+			return
+		}
+		// Make sure we don't hang waiting for the next block if we've already hit the end
+		if next.Block.Slot == end.Slot {
+			break
+		}
+		next, err = chainIter.Next(false)
+	}
+	_ = server.BatchDone()
+}
+
+// blockfetchServerRequestRangeImmutable serves the requested block range from the ImmutableDB, if configured. It returns
+// false without sending anything if the range isn't fully available there, in which case the caller should fall back to
+// our own block store
+func (n *Node) blockfetchServerRequestRangeImmutable(
+	ctx blockfetch.CallbackContext,
+	start ocommon.Point,
+	end ocommon.Point,
+) bool {
+	if n.immutableDb == nil {
+		return false
+	}
+	immutableTip, err := n.immutableDb.GetTip()
+	if err != nil || immutableTip == nil || end.Slot > immutableTip.Slot {
+		return false
+	}
+	blockIter, err := n.immutableDb.BlocksFromPoint(start)
+	if err != nil {
+		return false
+	}
+	// Find the start block, skipping any EBB sharing its slot
+	var firstBlock *immutable.Block
+	for {
+		firstBlock, err = blockIter.Next()
+		if err != nil || firstBlock == nil || firstBlock.Slot != start.Slot {
+			_ = blockIter.Close()
+			return false
+		}
+		if string(firstBlock.Hash) == string(start.Hash) {
+			break
+		}
+	}
+	// Start async process to send requested block range
 	go func() {
+		defer blockIter.Close() //nolint:errcheck
 		if err := ctx.Server.StartBatch(); err != nil {
 			return
 		}
-		for {
-			next, _ := chainIter.Next(false)
-			if next == nil {
+		next := firstBlock
+		for next != nil {
+			if next.Slot > end.Slot {
 				break
 			}
-			if next.Block.Slot > end.Slot {
+			if err := ctx.Server.Block(next.Type, next.Cbor); err != nil {
+				return
+			}
+			if next.Slot == end.Slot && string(next.Hash) == string(end.Hash) {
 				break
 			}
-			blockBytes := next.Block.Cbor
-			err := ctx.Server.Block(
-				next.Block.Type,
-				blockBytes,
-			)
+			next, err = blockIter.Next()
 			if err != nil {
-				// TODO: push this error somewhere (#398)
+				n.config.logger.Error(
+					"failed to read block from immutable DB",
+					"component", "node",
+					"error", err,
+				)
 				return
 			}
-			// Make sure we don't hang waiting for the next block if we've already hit the end
-			if next.Block.Slot == end.Slot {
-				break
-			}
 		}
 		if err := ctx.Server.BatchDone(); err != nil {
 			return
 		}
 	}()
-	return nil
+	return true
 }
 
 // blockfetchClientRequestRange is called by the ledger when it needs to request a range of block bodies
@@ -95,19 +213,31 @@ func (n *Node) blockfetchClientRequestRange(
 ) error {
 	conn := n.connManager.GetConnectionById(connId)
 	if conn == nil {
+		n.blockfetchPeers.requestFailed(connId)
 		return fmt.Errorf("failed to lookup connection ID: %s", connId.String())
 	}
 	if err := conn.BlockFetch().Client.GetBlockRange(start, end); err != nil {
+		n.blockfetchPeers.requestFailed(connId)
 		return err
 	}
+	n.blockfetchPeers.requestStarted(connId)
 	return nil
 }
 
+// blockfetchClientConnectionIds is called by the ledger to find connections that it can request block ranges from
+func (n *Node) blockfetchClientConnectionIds() []ouroboros.ConnectionId {
+	if n.peerGov == nil {
+		return nil
+	}
+	return n.blockfetchPeers.order(n.peerGov.ClientConnectionIds())
+}
+
 func (n *Node) blockfetchClientBlock(
 	ctx blockfetch.CallbackContext,
 	blockType uint,
 	block gledger.Block,
 ) error {
+	n.blockfetchPeers.blockReceived(ctx.ConnectionId)
 	// Generate event
 	n.eventBus.Publish(
 		ledger.BlockfetchEventType,
@@ -118,8 +248,9 @@ func (n *Node) blockfetchClientBlock(
 					block.SlotNumber(),
 					block.Hash().Bytes(),
 				),
-				Type:  blockType,
-				Block: block,
+				ConnectionId: ctx.ConnectionId,
+				Type:         blockType,
+				Block:        block,
 			},
 		),
 	)
@@ -129,6 +260,7 @@ func (n *Node) blockfetchClientBlock(
 func (n *Node) blockfetchClientBatchDone(
 	ctx blockfetch.CallbackContext,
 ) error {
+	n.blockfetchPeers.batchDone(ctx.ConnectionId)
 	// Generate event
 	n.eventBus.Publish(
 		ledger.BlockfetchEventType,
@@ -0,0 +1,227 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	ouroboros "github.com/blinklabs-io/gouroboros"
+)
+
+// BlockFetchStrategy determines which peer a block range is fetched from when multiple peers are available
+type BlockFetchStrategy string
+
+const (
+	// BlockFetchStrategyMostAhead prefers the peer reporting the furthest-ahead chain tip
+	BlockFetchStrategyMostAhead BlockFetchStrategy = "most-ahead"
+	// BlockFetchStrategyLowestLatency prefers the peer with the lowest observed blockfetch response time
+	BlockFetchStrategyLowestLatency BlockFetchStrategy = "lowest-latency"
+	// BlockFetchStrategyRoundRobin rotates between available peers for each request
+	BlockFetchStrategyRoundRobin BlockFetchStrategy = "round-robin"
+)
+
+const (
+	// Number of consecutive failures before a peer is deprioritized for blockfetch
+	blockfetchPeerMaxFailures = 3
+
+	// How long a peer stays deprioritized after its last failure
+	blockfetchPeerPenaltyDuration = 1 * time.Minute
+
+	// How long to wait for the first block of a requested range before counting it as a failure
+	blockfetchPeerRequestTimeout = 5 * time.Second
+
+	// Weight given to the newest sample when updating a peer's average latency
+	blockfetchPeerLatencyWeight = 0.2
+)
+
+func (s BlockFetchStrategy) valid() bool {
+	return slices.Contains(
+		[]BlockFetchStrategy{
+			BlockFetchStrategyMostAhead,
+			BlockFetchStrategyLowestLatency,
+			BlockFetchStrategyRoundRobin,
+		},
+		s,
+	)
+}
+
+type blockfetchPeer struct {
+	tipSlot     uint64
+	latency     time.Duration
+	requestTime time.Time
+	failures    int
+	lastFailure time.Time
+}
+
+// blockfetchPeers tracks per-peer blockfetch stats and orders peers according to the configured strategy
+type blockfetchPeers struct {
+	mu            sync.Mutex
+	strategy      BlockFetchStrategy
+	peers         map[ouroboros.ConnectionId]*blockfetchPeer
+	roundRobinIdx int
+}
+
+func newBlockfetchPeers(strategy BlockFetchStrategy) *blockfetchPeers {
+	if strategy == "" {
+		strategy = BlockFetchStrategyMostAhead
+	}
+	return &blockfetchPeers{
+		strategy: strategy,
+		peers:    make(map[ouroboros.ConnectionId]*blockfetchPeer),
+	}
+}
+
+func (b *blockfetchPeers) peer(connId ouroboros.ConnectionId) *blockfetchPeer {
+	peer, ok := b.peers[connId]
+	if !ok {
+		peer = &blockfetchPeer{}
+		b.peers[connId] = peer
+	}
+	return peer
+}
+
+// updateTip records the chain tip slot reported by a peer
+func (b *blockfetchPeers) updateTip(connId ouroboros.ConnectionId, slot uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.peer(connId).tipSlot = slot
+}
+
+// requestStarted records the start of a block range request to a peer
+func (b *blockfetchPeers) requestStarted(connId ouroboros.ConnectionId) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.peer(connId).requestTime = time.Now()
+}
+
+// blockReceived records a block received from a peer. The time since the start of the request is used as a latency
+// sample
+func (b *blockfetchPeers) blockReceived(connId ouroboros.ConnectionId) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	peer := b.peer(connId)
+	if peer.requestTime.IsZero() {
+		return
+	}
+	sample := time.Since(peer.requestTime)
+	if peer.latency == 0 {
+		peer.latency = sample
+	} else {
+		peer.latency = time.Duration(
+			blockfetchPeerLatencyWeight*float64(sample) +
+				(1-blockfetchPeerLatencyWeight)*float64(peer.latency),
+		)
+	}
+	peer.requestTime = time.Time{}
+}
+
+// batchDone records the successful completion of a block range request to a peer
+func (b *blockfetchPeers) batchDone(connId ouroboros.ConnectionId) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	peer := b.peer(connId)
+	peer.requestTime = time.Time{}
+	peer.failures = 0
+}
+
+// requestFailed records a failed block range request to a peer
+func (b *blockfetchPeers) requestFailed(connId ouroboros.ConnectionId) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.peer(connId).recordFailure()
+}
+
+func (p *blockfetchPeer) recordFailure() {
+	p.requestTime = time.Time{}
+	p.failures++
+	p.lastFailure = time.Now()
+}
+
+func (p *blockfetchPeer) penalized() bool {
+	return p.failures >= blockfetchPeerMaxFailures &&
+		time.Since(p.lastFailure) < blockfetchPeerPenaltyDuration
+}
+
+// remove drops the stats for a peer
+func (b *blockfetchPeers) remove(connId ouroboros.ConnectionId) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.peers, connId)
+}
+
+// order returns the specified connections sorted by preference according to the configured strategy. Peers that
+// have failed repeatedly are moved to the end of the list until their penalty expires
+func (b *blockfetchPeers) order(
+	connIds []ouroboros.ConnectionId,
+) []ouroboros.ConnectionId {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ret := make([]ouroboros.ConnectionId, 0, len(connIds))
+	var penalized []ouroboros.ConnectionId
+	for _, connId := range connIds {
+		peer := b.peer(connId)
+		// Count a request that never got a response as a failure
+		if !peer.requestTime.IsZero() &&
+			time.Since(peer.requestTime) > blockfetchPeerRequestTimeout {
+			peer.recordFailure()
+		}
+		if peer.penalized() {
+			penalized = append(penalized, connId)
+			continue
+		}
+		ret = append(ret, connId)
+	}
+	switch b.strategy {
+	case BlockFetchStrategyRoundRobin:
+		if len(ret) > 0 {
+			b.roundRobinIdx = (b.roundRobinIdx + 1) % len(ret)
+			ret = slices.Concat(ret[b.roundRobinIdx:], ret[:b.roundRobinIdx])
+		}
+	case BlockFetchStrategyLowestLatency:
+		slices.SortStableFunc(ret, func(a, c ouroboros.ConnectionId) int {
+			latencyA := b.peers[a].latency
+			latencyC := b.peers[c].latency
+			// Peers without a latency sample go last
+			switch {
+			case latencyA == latencyC:
+				return 0
+			case latencyA == 0:
+				return 1
+			case latencyC == 0:
+				return -1
+			case latencyA < latencyC:
+				return -1
+			default:
+				return 1
+			}
+		})
+	case BlockFetchStrategyMostAhead:
+		slices.SortStableFunc(ret, func(a, c ouroboros.ConnectionId) int {
+			tipA := b.peers[a].tipSlot
+			tipC := b.peers[c].tipSlot
+			switch {
+			case tipA > tipC:
+				return -1
+			case tipA < tipC:
+				return 1
+			default:
+				return 0
+			}
+		})
+	}
+	return append(ret, penalized...)
+}
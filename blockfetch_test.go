@@ -0,0 +1,105 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/internal/test/chainsynctest"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+// testBlockfetchRangeServer records the messages sent for a block range and calls onBlock after each block
+type testBlockfetchRangeServer struct {
+	messages []string
+	onBlock  func()
+}
+
+func (s *testBlockfetchRangeServer) NoBlocks() error {
+	s.messages = append(s.messages, "NoBlocks")
+	return nil
+}
+
+func (s *testBlockfetchRangeServer) StartBatch() error {
+	s.messages = append(s.messages, "StartBatch")
+	return nil
+}
+
+func (s *testBlockfetchRangeServer) Block(blockType uint, blockData []byte) error {
+	s.messages = append(s.messages, "Block")
+	if s.onBlock != nil {
+		s.onBlock()
+	}
+	return nil
+}
+
+func (s *testBlockfetchRangeServer) BatchDone() error {
+	s.messages = append(s.messages, "BatchDone")
+	return nil
+}
+
+func TestBlockfetchServerSendRangePrunedMidRange(t *testing.T) {
+	db, err := database.New(nil, nil, "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error creating database: %s", err)
+	}
+	defer db.Close()
+	cm, err := chain.NewManager(db, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain manager: %s", err)
+	}
+	c := cm.PrimaryChain()
+	var blocks []*chainsynctest.MockBlock
+	var prevBlock *chainsynctest.MockBlock
+	for i := range 3 {
+		block := chainsynctest.NewMockBlock(prevBlock, uint64(i+1)*10) // #nosec G115
+		if err := c.AddBlock(block, nil); err != nil {
+			t.Fatalf("unexpected error adding block to chain: %s", err)
+		}
+		blocks = append(blocks, block)
+		prevBlock = block
+	}
+	pruner, ok := cm.BlockStore().(chain.BlockPruner)
+	if !ok {
+		t.Fatal("database block store does not support pruning")
+	}
+	chainIter, err := c.FromPoint(blocks[0].Point(), true)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain iterator: %s", err)
+	}
+	n := &Node{
+		config: NewConfig(),
+	}
+	server := &testBlockfetchRangeServer{
+		// Prune the rest of the range after the first block has been sent
+		onBlock: func() {
+			if _, _, err := pruner.PruneBefore(0, blocks[2].SlotNumber(), 10, nil); err != nil {
+				t.Errorf("unexpected error pruning blocks: %s", err)
+			}
+		},
+	}
+	n.blockfetchServerSendRange(
+		server,
+		chainIter,
+		ocommon.NewPoint(blocks[2].SlotNumber(), blocks[2].Hash().Bytes()),
+	)
+	expected := []string{"StartBatch", "Block", "BatchDone"}
+	if !slices.Equal(server.messages, expected) {
+		t.Fatalf("did not get expected messages: got %v, expected %v", server.messages, expected)
+	}
+}
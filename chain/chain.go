@@ -73,6 +73,38 @@ func (c *Chain) headerTip() ochainsync.Tip {
 	}
 }
 
+// ImmutableTip returns the newest block that is at least k blocks behind the chain tip. Blocks at or before this
+// point are immutable and will never be rolled back. The origin point is returned if no blocks are immutable yet or if
+// no security parameter is configured
+func (c *Chain) ImmutableTip() (ochainsync.Tip, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	c.manager.mutex.RLock()
+	defer c.manager.mutex.RUnlock()
+	immutableBlockIndex := c.immutableBlockIndex()
+	if immutableBlockIndex < initialBlockIndex {
+		return ochainsync.Tip{}, nil
+	}
+	tmpBlock, err := c.blockByIndex(immutableBlockIndex, nil)
+	if err != nil {
+		return ochainsync.Tip{}, err
+	}
+	return ochainsync.Tip{
+		Point:       ocommon.NewPoint(tmpBlock.Slot, tmpBlock.Hash),
+		BlockNumber: tmpBlock.Number,
+	}, nil
+}
+
+// immutableBlockIndex returns the index of the newest immutable block, or 0 if there is none. The caller must hold
+// the manager lock
+func (c *Chain) immutableBlockIndex() uint64 {
+	securityParam := c.manager.securityParam
+	if securityParam == 0 || c.tipBlockIndex <= securityParam {
+		return 0
+	}
+	return c.tipBlockIndex - securityParam
+}
+
 func (c *Chain) AddBlockHeader(header ledger.BlockHeader) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -242,14 +274,18 @@ func (c *Chain) Rollback(point ocommon.Point) error {
 		}
 		rollbackBlockIndex = tmpBlock.ID
 	}
+	// Only volatile blocks can be rolled back
+	if rollbackBlockIndex < c.immutableBlockIndex() {
+		return ErrRollbackBeyondImmutable
+	}
 	// Delete any rolled-back blocks
+	var rolledBackBlocks []database.Block
 	for i := c.tipBlockIndex; i > rollbackBlockIndex; i-- {
-		if c.persistent {
-			// Remove block from persistent store
-			if err := c.manager.removeBlockByIndex(i); err != nil {
-				return err
-			}
-		} else {
+		// Keep a copy of the block for the rollback event
+		if rolledBackBlock, err := c.blockByIndex(i, nil); err == nil {
+			rolledBackBlocks = append(rolledBackBlocks, rolledBackBlock)
+		}
+		if !c.persistent {
 			// Decrement our fork point block index if we rollback beyond it
 			if i < c.lastCommonBlockIndex {
 				c.lastCommonBlockIndex = i
@@ -264,6 +300,12 @@ func (c *Chain) Rollback(point ocommon.Point) error {
 			)
 		}
 	}
+	if c.persistent {
+		// Remove blocks from persistent store
+		if err := c.manager.rollbackBlocks(point, rolledBackBlocks); err != nil {
+			return err
+		}
+	}
 	// Clear out any headers
 	c.headers = slices.Delete(c.headers, 0, len(c.headers))
 	// Update tip
@@ -283,6 +325,13 @@ func (c *Chain) Rollback(point ocommon.Point) error {
 			iter.needsRollback = true
 		}
 	}
+	// Notify waiting iterators
+	c.waitingChanMutex.Lock()
+	if c.waitingChan != nil {
+		close(c.waitingChan)
+		c.waitingChan = nil
+	}
+	c.waitingChanMutex.Unlock()
 	// Generate event
 	if c.eventBus != nil {
 		c.eventBus.Publish(
@@ -290,7 +339,8 @@ func (c *Chain) Rollback(point ocommon.Point) error {
 			event.NewEvent(
 				ChainUpdateEventType,
 				ChainRollbackEvent{
-					Point: point,
+					Point:            point,
+					RolledBackBlocks: rolledBackBlocks,
 				},
 			),
 		)
@@ -344,6 +394,17 @@ func (c *Chain) FromPoint(
 	return iter, nil
 }
 
+func (c *Chain) removeIterator(iter *ChainIterator) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.iterators = slices.DeleteFunc(
+		c.iterators,
+		func(tmpIter *ChainIterator) bool {
+			return tmpIter == iter
+		},
+	)
+}
+
 func (c *Chain) BlockByPoint(
 	point ocommon.Point,
 	txn *database.Txn,
@@ -418,6 +479,12 @@ func (c *Chain) iterNext(
 	tmpBlock, err := c.blockByIndex(iter.nextBlockIndex, nil)
 	// Return immedidately if a block is found
 	if err == nil {
+		// The block content is gone, so there's nothing to return
+		if tmpBlock.Pruned {
+			c.mutex.Unlock()
+			c.manager.mutex.RUnlock()
+			return nil, ErrBlockPruned
+		}
 		ret.Point = ocommon.NewPoint(tmpBlock.Slot, tmpBlock.Hash)
 		ret.Block = tmpBlock
 		iter.nextBlockIndex++
@@ -445,8 +512,13 @@ func (c *Chain) iterNext(
 	if c.waitingChan == nil {
 		c.waitingChan = make(chan struct{})
 	}
+	waitingChan := c.waitingChan
 	c.waitingChanMutex.Unlock()
-	<-c.waitingChan
+	select {
+	case <-waitingChan:
+	case <-iter.doneChan:
+		return nil, ErrIteratorCancelled
+	}
 	// Call ourselves again now that we should have new data
 	return c.iterNext(iter, blocking)
 }
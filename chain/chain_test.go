@@ -20,6 +20,7 @@ import (
 	"reflect"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/blinklabs-io/dingo/chain"
 	"github.com/blinklabs-io/dingo/database"
@@ -300,6 +301,60 @@ func TestChainRollback(t *testing.T) {
 	}
 }
 
+func TestChainRollbackBeyondImmutable(t *testing.T) {
+	cm, err := chain.NewManager(nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain manager: %s", err)
+	}
+	cm.SetSecurityParam(3)
+	c := cm.PrimaryChain()
+	for _, testBlock := range testBlocks {
+		if err := c.AddBlock(testBlock, nil); err != nil {
+			t.Fatalf("unexpected error adding block to chain: %s", err)
+		}
+	}
+	// Check immutable tip
+	testImmutableBlock := testBlocks[len(testBlocks)-4]
+	immutableTip, err := c.ImmutableTip()
+	if err != nil {
+		t.Fatalf("unexpected error getting immutable tip: %s", err)
+	}
+	if immutableTip.Point.Slot != testImmutableBlock.SlotNumber() ||
+		string(immutableTip.Point.Hash) != string(testImmutableBlock.Hash().Bytes()) {
+		t.Fatalf(
+			"did not get expected immutable tip: got %d.%x, wanted %d.%s",
+			immutableTip.Point.Slot,
+			immutableTip.Point.Hash,
+			testImmutableBlock.SlotNumber(),
+			testImmutableBlock.Hash().String(),
+		)
+	}
+	// Rollback past the immutable tip
+	testRollbackBlock := testBlocks[1]
+	testRollbackPoint := ocommon.Point{
+		Slot: testRollbackBlock.SlotNumber(),
+		Hash: testRollbackBlock.Hash().Bytes(),
+	}
+	if err := c.Rollback(testRollbackPoint); !errors.Is(err, chain.ErrRollbackBeyondImmutable) {
+		t.Fatalf("did not get expected error: got %v, wanted %s", err, chain.ErrRollbackBeyondImmutable)
+	}
+	// Rollback to the immutable tip
+	if err := c.Rollback(immutableTip.Point); err != nil {
+		t.Fatalf("unexpected error doing chain rollback: %s", err)
+	}
+	chainTip := c.Tip()
+	if chainTip.Point.Slot != immutableTip.Point.Slot ||
+		string(chainTip.Point.Hash) != string(immutableTip.Point.Hash) {
+		t.Fatalf(
+			"chain tip does not match expected point after rollback: got %d.%x, wanted %d.%x",
+			chainTip.Point.Slot,
+			chainTip.Point.Hash,
+			immutableTip.Point.Slot,
+			immutableTip.Point.Hash,
+		)
+	}
+}
+
 func TestChainHeaderRange(t *testing.T) {
 	testBlockCount := 3
 	cm, err := chain.NewManager(nil, nil)
@@ -413,6 +468,47 @@ func TestChainHeaderWrongBlock(t *testing.T) {
 	}
 }
 
+func TestChainIteratorCancel(t *testing.T) {
+	cm, err := chain.NewManager(nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain manager: %s", err)
+	}
+	c := cm.PrimaryChain()
+	for _, testBlock := range testBlocks {
+		if err := c.AddBlock(testBlock, nil); err != nil {
+			t.Fatalf("unexpected error adding block to chain: %s", err)
+		}
+	}
+	testTipBlock := testBlocks[len(testBlocks)-1]
+	iter, err := c.FromPoint(
+		ocommon.Point{
+			Slot: testTipBlock.SlotNumber(),
+			Hash: testTipBlock.Hash().Bytes(),
+		},
+		false,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain iterator: %s", err)
+	}
+	// Wait for a new block at the chain tip, as the chainsync server does after sending AwaitReply
+	errChan := make(chan error, 1)
+	go func() {
+		_, err := iter.Next(true)
+		errChan <- err
+	}()
+	// Simulate the client disconnecting while waiting
+	time.Sleep(50 * time.Millisecond)
+	iter.Cancel()
+	select {
+	case err := <-errChan:
+		if !errors.Is(err, chain.ErrIteratorCancelled) {
+			t.Fatalf("did not get expected error: got %v, wanted %s", err, chain.ErrIteratorCancelled)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for cancelled iterator to return")
+	}
+}
+
 func TestChainHeaderRollback(t *testing.T) {
 	testBlockCount := 3
 	cm, err := chain.NewManager(nil, nil)
@@ -634,3 +730,181 @@ func TestChainFork(t *testing.T) {
 		testBlockIdx++
 	}
 }
+
+// testBlockStore is a simple in-memory chain.BlockStore
+type testBlockStore struct {
+	blocks []database.Block
+}
+
+func (s *testBlockStore) Put(block database.Block, _ *database.Txn) error {
+	s.blocks = append(s.blocks, block)
+	return nil
+}
+
+func (s *testBlockStore) Get(point ocommon.Point, _ *database.Txn) (database.Block, error) {
+	for _, block := range s.blocks {
+		if block.Slot == point.Slot && string(block.Hash) == string(point.Hash) {
+			return block, nil
+		}
+	}
+	return database.Block{}, database.ErrBlockNotFound
+}
+
+func (s *testBlockStore) GetByIndex(index uint64, _ *database.Txn) (database.Block, error) {
+	for _, block := range s.blocks {
+		if block.ID == index {
+			return block, nil
+		}
+	}
+	return database.Block{}, database.ErrBlockNotFound
+}
+
+func (s *testBlockStore) GetRange(start ocommon.Point, end ocommon.Point, _ *database.Txn) ([]database.Block, error) {
+	var ret []database.Block
+	for _, block := range s.blocks {
+		if block.Slot >= start.Slot && block.Slot <= end.Slot {
+			ret = append(ret, block)
+		}
+	}
+	return ret, nil
+}
+
+func (s *testBlockStore) GetBeforeSlot(slot uint64, _ *database.Txn) (database.Block, error) {
+	for i := len(s.blocks) - 1; i >= 0; i-- {
+		if s.blocks[i].Slot < slot {
+			return s.blocks[i], nil
+		}
+	}
+	return database.Block{}, database.ErrBlockNotFound
+}
+
+func (s *testBlockStore) Tip(_ *database.Txn) (database.Block, error) {
+	if len(s.blocks) == 0 {
+		return database.Block{}, database.ErrBlockNotFound
+	}
+	return s.blocks[len(s.blocks)-1], nil
+}
+
+func (s *testBlockStore) Rollback(point ocommon.Point, _ *database.Txn) error {
+	for i, block := range s.blocks {
+		if block.Slot == point.Slot && string(block.Hash) == string(point.Hash) {
+			s.blocks = s.blocks[:i+1]
+			return nil
+		}
+	}
+	return database.ErrBlockNotFound
+}
+
+func TestChainCustomBlockStore(t *testing.T) {
+	store := &testBlockStore{}
+	cm, err := chain.NewManagerWithBlockStore(nil, store, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain manager: %s", err)
+	}
+	c := cm.PrimaryChain()
+	for _, testBlock := range testBlocks {
+		if err := c.AddBlock(testBlock, nil); err != nil {
+			t.Fatalf("unexpected error adding block to chain: %s", err)
+		}
+	}
+	if len(store.blocks) != len(testBlocks) {
+		t.Fatalf(
+			"did not get expected block count in store: got %d, wanted %d",
+			len(store.blocks),
+			len(testBlocks),
+		)
+	}
+	// Read blocks back through a chain iterator
+	iter, err := c.FromPoint(ocommon.NewPointOrigin(), false)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain iterator: %s", err)
+	}
+	for _, testBlock := range testBlocks {
+		next, err := iter.Next(false)
+		if err != nil {
+			t.Fatalf("unexpected error getting next block from chain iterator: %s", err)
+		}
+		if hex.EncodeToString(next.Block.Hash) != testBlock.MockHash {
+			t.Fatalf(
+				"did not get expected block from iterator: got hash %s, expected %s",
+				hex.EncodeToString(next.Block.Hash),
+				testBlock.MockHash,
+			)
+		}
+	}
+	// Rollback and make sure the store was updated
+	testRollbackBlock := testBlocks[2]
+	if err := c.Rollback(ocommon.NewPoint(testRollbackBlock.SlotNumber(), testRollbackBlock.Hash().Bytes())); err != nil {
+		t.Fatalf("unexpected error doing chain rollback: %s", err)
+	}
+	if len(store.blocks) != 3 {
+		t.Fatalf("did not get expected block count in store after rollback: got %d, wanted 3", len(store.blocks))
+	}
+	// Reload chain from store
+	cm, err = chain.NewManagerWithBlockStore(nil, store, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain manager: %s", err)
+	}
+	tip := cm.PrimaryChain().Tip()
+	if tip.Point.Slot != testRollbackBlock.SlotNumber() {
+		t.Fatalf("did not get expected chain tip: got slot %d, wanted %d", tip.Point.Slot, testRollbackBlock.SlotNumber())
+	}
+}
+
+func TestChainIteratorPrunedBlock(t *testing.T) {
+	const testCacheSize int64 = 1 << 20
+	db, err := database.New(nil, nil, "", testCacheSize) // in-memory
+	if err != nil {
+		t.Fatalf("unexpected error creating database: %s", err)
+	}
+	defer db.Close()
+	cm, err := chain.NewManager(db, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain manager: %s", err)
+	}
+	c := cm.PrimaryChain()
+	for _, testBlock := range testBlocks {
+		if err := c.AddBlock(testBlock, nil); err != nil {
+			t.Fatalf("unexpected error adding block to chain: %s", err)
+		}
+	}
+	pruner, ok := cm.BlockStore().(chain.BlockPruner)
+	if !ok {
+		t.Fatal("database block store does not support pruning")
+	}
+	pruned, _, err := pruner.PruneBefore(0, testBlocks[2].SlotNumber(), 10, nil)
+	if err != nil {
+		t.Fatalf("unexpected error pruning blocks: %s", err)
+	}
+	if pruned != 2 {
+		t.Fatalf("did not get expected pruned count: got %d, wanted 2", pruned)
+	}
+	// Iterating over pruned blocks returns an explicit error
+	iter, err := c.FromPoint(ocommon.NewPointOrigin(), false)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain iterator: %s", err)
+	}
+	if _, err := iter.Next(false); !errors.Is(err, chain.ErrBlockPruned) {
+		t.Fatalf("did not get expected error from chain iterator: %v", err)
+	}
+	// Retained blocks can still be iterated from a pruned intersect point
+	testPrunedBlock := testBlocks[1]
+	iter, err = c.FromPoint(
+		ocommon.NewPoint(testPrunedBlock.SlotNumber(), testPrunedBlock.Hash().Bytes()),
+		false,
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain iterator: %s", err)
+	}
+	next, err := iter.Next(false)
+	if err != nil {
+		t.Fatalf("unexpected error getting next block from chain iterator: %s", err)
+	}
+	if hex.EncodeToString(next.Block.Hash) != testBlocks[2].MockHash {
+		t.Fatalf(
+			"did not get expected block from iterator: got hash %s, expected %s",
+			hex.EncodeToString(next.Block.Hash),
+			testBlocks[2].MockHash,
+		)
+	}
+}
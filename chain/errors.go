@@ -21,6 +21,7 @@ import (
 
 var (
 	ErrBlockNotFound                = errors.New("block not found")
+	ErrBlockPruned                  = errors.New("block has been pruned")
 	ErrIntersectNotFound            = errors.New("chain intersect not found")
 	ErrRollbackBeyondEphemeralChain = errors.New(
 		"cannot rollback ephemeral chain beyond memory buffer",
@@ -28,6 +29,12 @@ var (
 	ErrIteratorChainTip = errors.New(
 		"chain iterator is at chain tip",
 	)
+	ErrIteratorCancelled = errors.New(
+		"chain iterator was cancelled",
+	)
+	ErrRollbackBeyondImmutable = errors.New(
+		"cannot rollback beyond immutable chain boundary",
+	)
 )
 
 type BlockNotFitChainTipError struct {
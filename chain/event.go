@@ -29,5 +29,6 @@ type ChainBlockEvent struct {
 }
 
 type ChainRollbackEvent struct {
-	Point ocommon.Point
+	Point            ocommon.Point
+	RolledBackBlocks []database.Block // Blocks removed by the rollback, newest first
 }
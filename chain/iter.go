@@ -15,6 +15,8 @@
 package chain
 
 import (
+	"sync"
+
 	"github.com/blinklabs-io/dingo/database"
 	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
 )
@@ -26,6 +28,8 @@ type ChainIterator struct {
 	lastPoint      ocommon.Point
 	needsRollback  bool
 	rollbackPoint  ocommon.Point
+	doneChan       chan struct{}
+	cancelOnce     sync.Once
 }
 
 type ChainIteratorResult struct {
@@ -43,6 +47,9 @@ func newChainIterator(
 		chain:          chain,
 		startPoint:     startPoint,
 		nextBlockIndex: initialBlockIndex,
+		// Use the start point as our last point, so a rollback to before it is reported even if no blocks have been returned yet
+		lastPoint: startPoint,
+		doneChan:  make(chan struct{}),
 	}
 	// Lookup start block in metadata DB if not origin
 	if startPoint.Slot > 0 || len(startPoint.Hash) > 0 {
@@ -62,3 +69,12 @@ func newChainIterator(
 func (ci *ChainIterator) Next(blocking bool) (*ChainIteratorResult, error) {
 	return ci.chain.iterNext(ci, blocking)
 }
+
+// Cancel stops the iterator and releases it from the chain. Any pending or future blocking calls to Next will return
+// ErrIteratorCancelled
+func (ci *ChainIterator) Cancel() {
+	ci.cancelOnce.Do(func() {
+		close(ci.doneChan)
+		ci.chain.removeIterator(ci)
+	})
+}
@@ -36,15 +36,34 @@ const (
 type ChainManager struct {
 	mutex               sync.RWMutex
 	db                  *database.Database
+	store               BlockStore
 	eventBus            *event.EventBus
 	chains              map[ChainId]*Chain
 	chainRollbackEvents map[ChainId][]uint64
 	blocks              map[string]database.Block
+	securityParam       uint64
 }
 
+// NewManager creates a chain manager that stores blocks in the specified database. Blocks are only kept in memory if
+// the database is nil
 func NewManager(db *database.Database, eventBus *event.EventBus) (*ChainManager, error) {
+	var store BlockStore
+	if db != nil {
+		store = NewDatabaseBlockStore(db)
+	}
+	return NewManagerWithBlockStore(db, store, eventBus)
+}
+
+// NewManagerWithBlockStore creates a chain manager that stores blocks in the specified BlockStore. The database is
+// used to create the transactions passed to the BlockStore
+func NewManagerWithBlockStore(
+	db *database.Database,
+	store BlockStore,
+	eventBus *event.EventBus,
+) (*ChainManager, error) {
 	cm := &ChainManager{
 		db:                  db,
+		store:               store,
 		eventBus:            eventBus,
 		chains:              make(map[ChainId]*Chain),
 		chainRollbackEvents: make(map[ChainId][]uint64),
@@ -56,6 +75,11 @@ func NewManager(db *database.Database, eventBus *event.EventBus) (*ChainManager,
 	return cm, nil
 }
 
+// BlockStore returns the storage backend for the blocks on the primary chain
+func (cm *ChainManager) BlockStore() BlockStore {
+	return cm.store
+}
+
 func (cm *ChainManager) PrimaryChain() *Chain {
 	return cm.chains[primaryChainId]
 }
@@ -64,6 +88,22 @@ func (cm *ChainManager) Chain(id ChainId) *Chain {
 	return cm.chains[id]
 }
 
+// SetSecurityParam sets the security parameter (k) used to determine the boundary between the immutable and volatile
+// portions of each chain. Blocks more than k blocks behind a chain's tip are considered immutable and can no longer be
+// rolled back. A value of 0 disables the boundary
+func (cm *ChainManager) SetSecurityParam(securityParam uint64) {
+	cm.mutex.Lock()
+	defer cm.mutex.Unlock()
+	cm.securityParam = securityParam
+}
+
+// SecurityParam returns the security parameter (k) used for the immutable boundary
+func (cm *ChainManager) SecurityParam() uint64 {
+	cm.mutex.RLock()
+	defer cm.mutex.RUnlock()
+	return cm.securityParam
+}
+
 // NewChain creates a new Chain that forks from the primary chain at the specified point. This is useful for managing outbound ChainSync clients
 func (cm *ChainManager) NewChain(point ocommon.Point) (*Chain, error) {
 	cm.mutex.Lock()
@@ -186,15 +226,9 @@ func (cm *ChainManager) blockByPoint(
 			return blk, nil
 		}
 	}
-	// Query database
-	if cm.db != nil {
-		var tmpBlock database.Block
-		var err error
-		if txn == nil {
-			tmpBlock, err = database.BlockByPoint(cm.db, point)
-		} else {
-			tmpBlock, err = database.BlockByPointTxn(txn, point)
-		}
+	// Query block store
+	if cm.store != nil {
+		tmpBlock, err := cm.store.Get(point, txn)
 		if err != nil {
 			if errors.Is(err, database.ErrBlockNotFound) {
 				return database.Block{}, ErrBlockNotFound
@@ -222,9 +256,9 @@ func (cm *ChainManager) blockByIndex(
 	blockIndex uint64,
 	txn *database.Txn,
 ) (database.Block, error) {
-	// Query database
-	if cm.db != nil {
-		tmpBlock, err := cm.db.BlockByIndex(blockIndex, txn)
+	// Query block store
+	if cm.store != nil {
+		tmpBlock, err := cm.store.GetByIndex(blockIndex, txn)
 		if err != nil {
 			if errors.Is(err, database.ErrBlockNotFound) {
 				return database.Block{}, ErrBlockNotFound
@@ -237,7 +271,7 @@ func (cm *ChainManager) blockByIndex(
 }
 
 func (cm *ChainManager) loadPrimaryChain() error {
-	persistent := (cm.db != nil)
+	persistent := (cm.store != nil)
 	chain := &Chain{
 		id:         primaryChainId,
 		manager:    cm,
@@ -245,19 +279,19 @@ func (cm *ChainManager) loadPrimaryChain() error {
 		persistent: persistent,
 	}
 	if persistent {
-		recentBlocks, err := database.BlocksRecent(cm.db, 1)
-		if err != nil {
+		tipBlock, err := cm.store.Tip(nil)
+		if err != nil && !errors.Is(err, database.ErrBlockNotFound) {
 			return err
 		}
-		if len(recentBlocks) > 0 {
+		if err == nil {
 			chain.currentTip = ochainsync.Tip{
 				Point: ocommon.Point{
-					Slot: recentBlocks[0].Slot,
-					Hash: recentBlocks[0].Hash,
+					Slot: tipBlock.Slot,
+					Hash: tipBlock.Hash,
 				},
-				BlockNumber: recentBlocks[0].Number,
+				BlockNumber: tipBlock.Number,
 			}
-			chain.tipBlockIndex = recentBlocks[0].ID
+			chain.tipBlockIndex = tipBlock.ID
 		}
 	}
 	cm.chains[primaryChainId] = chain
@@ -266,8 +300,8 @@ func (cm *ChainManager) loadPrimaryChain() error {
 
 func (cm *ChainManager) addBlock(block database.Block, txn *database.Txn, persistent bool) error {
 	if persistent {
-		// Add block to database
-		if err := cm.db.BlockCreate(block, txn); err != nil {
+		// Add block to block store
+		if err := cm.store.Put(block, txn); err != nil {
 			return err
 		}
 		// TODO: trigger periodic async signal to chains to do reconcile to prune buffer
@@ -278,35 +312,26 @@ func (cm *ChainManager) addBlock(block database.Block, txn *database.Txn, persis
 	return nil
 }
 
-func (cm *ChainManager) removeBlockByIndex(blockIndex uint64) error {
-	// Record removed block event for each non-primary chain
-	for chainId := range cm.chains {
-		if chainId == primaryChainId {
-			continue
-		}
-		cm.chainRollbackEvents[chainId] = append(
-			cm.chainRollbackEvents[chainId],
-			blockIndex,
-		)
-	}
-	// Remove from database
-	txn := cm.db.BlobTxn(true)
-	err := txn.Do(func(txn *database.Txn) error {
-		tmpBlock, err := cm.db.BlockByIndex(blockIndex, txn)
-		if err != nil {
-			return err
+// rollbackBlocks removes the blocks after the specified point from the block store
+func (cm *ChainManager) rollbackBlocks(
+	point ocommon.Point,
+	rolledBackBlocks []database.Block,
+) error {
+	for _, tmpBlock := range rolledBackBlocks {
+		// Record removed block event for each non-primary chain
+		for chainId := range cm.chains {
+			if chainId == primaryChainId {
+				continue
+			}
+			cm.chainRollbackEvents[chainId] = append(
+				cm.chainRollbackEvents[chainId],
+				tmpBlock.ID,
+			)
 		}
 		// Add block to memory buffer in case other chains are using it
 		cm.blocks[string(tmpBlock.Hash)] = tmpBlock
-		if err := database.BlockDeleteTxn(txn, tmpBlock); err != nil {
-			return err
-		}
-		return nil
-	})
-	if err != nil {
-		return err
 	}
-	return nil
+	return cm.store.Rollback(point, nil)
 }
 
 func (cm *ChainManager) chainNeedsReconcile(chainId ChainId, lastCommonBlockIndex uint64) bool {
@@ -0,0 +1,210 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chain
+
+import (
+	"errors"
+
+	"github.com/blinklabs-io/dingo/database"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+// BlockStore is the storage backend for the blocks on the primary chain. Blocks are identified by their point and by
+// their index on the chain, which starts at database.BlockInitialIndex and increments by 1 for each block. Lookups
+// must return database.ErrBlockNotFound for blocks that don't exist.
+//
+// The txn argument is the node database transaction that the operation is part of, which may be nil. Implementations
+// that don't store blocks in the node database can ignore it
+type BlockStore interface {
+	// Put stores a block. Blocks are always added in chain order
+	Put(block database.Block, txn *database.Txn) error
+	// Get returns the block at the specified point
+	Get(point ocommon.Point, txn *database.Txn) (database.Block, error)
+	// GetByIndex returns the block with the specified chain index
+	GetByIndex(index uint64, txn *database.Txn) (database.Block, error)
+	// GetRange returns the blocks between the specified points, inclusive, in chain order
+	GetRange(start ocommon.Point, end ocommon.Point, txn *database.Txn) ([]database.Block, error)
+	// GetBeforeSlot returns the last block with a slot before the specified slot
+	GetBeforeSlot(slot uint64, txn *database.Txn) (database.Block, error)
+	// Tip returns the most recent block
+	Tip(txn *database.Txn) (database.Block, error)
+	// Rollback removes all blocks after the specified point. The origin point removes all blocks
+	Rollback(point ocommon.Point, txn *database.Txn) error
+}
+
+// BlockPruner is an optional interface for a BlockStore that supports pruning old block bodies
+type BlockPruner interface {
+	// PruneBefore removes the content of up to limit blocks with a slot from startSlot up to but not including slot,
+	// while keeping enough information to find them by point and index. It returns the number of blocks pruned and the
+	// slot to resume pruning from
+	PruneBefore(
+		startSlot uint64,
+		slot uint64,
+		limit int,
+		txn *database.Txn,
+	) (int, uint64, error)
+}
+
+// databaseBlockStore is the default BlockStore, which stores blocks in the node database
+type databaseBlockStore struct {
+	db *database.Database
+}
+
+// NewDatabaseBlockStore returns a BlockStore that stores blocks in the specified node database
+func NewDatabaseBlockStore(db *database.Database) BlockStore {
+	return &databaseBlockStore{db: db}
+}
+
+// do runs the specified function in the provided transaction, or in a new transaction if none was provided
+func (s *databaseBlockStore) do(
+	txn *database.Txn,
+	readWrite bool,
+	fn func(*database.Txn) error,
+) error {
+	if txn != nil {
+		return fn(txn)
+	}
+	return s.db.BlobTxn(readWrite).Do(fn)
+}
+
+func (s *databaseBlockStore) Put(block database.Block, txn *database.Txn) error {
+	return s.db.BlockCreate(block, txn)
+}
+
+func (s *databaseBlockStore) Get(
+	point ocommon.Point,
+	txn *database.Txn,
+) (database.Block, error) {
+	var ret database.Block
+	err := s.do(txn, false, func(txn *database.Txn) error {
+		var err error
+		ret, err = database.BlockByPointTxn(txn, point)
+		return err
+	})
+	return ret, err
+}
+
+func (s *databaseBlockStore) GetByIndex(
+	index uint64,
+	txn *database.Txn,
+) (database.Block, error) {
+	return s.db.BlockByIndex(index, txn)
+}
+
+func (s *databaseBlockStore) GetRange(
+	start ocommon.Point,
+	end ocommon.Point,
+	txn *database.Txn,
+) ([]database.Block, error) {
+	var ret []database.Block
+	err := s.do(txn, false, func(txn *database.Txn) error {
+		startBlock, err := database.BlockByPointTxn(txn, start)
+		if err != nil {
+			return err
+		}
+		endBlock, err := database.BlockByPointTxn(txn, end)
+		if err != nil {
+			return err
+		}
+		for index := startBlock.ID; index <= endBlock.ID; index++ {
+			tmpBlock, err := s.db.BlockByIndex(index, txn)
+			if err != nil {
+				return err
+			}
+			ret = append(ret, tmpBlock)
+		}
+		return nil
+	})
+	return ret, err
+}
+
+func (s *databaseBlockStore) GetBeforeSlot(
+	slot uint64,
+	txn *database.Txn,
+) (database.Block, error) {
+	var ret database.Block
+	err := s.do(txn, false, func(txn *database.Txn) error {
+		var err error
+		ret, err = database.BlockBeforeSlotTxn(txn, slot)
+		return err
+	})
+	return ret, err
+}
+
+func (s *databaseBlockStore) Tip(txn *database.Txn) (database.Block, error) {
+	var ret database.Block
+	err := s.do(txn, false, func(txn *database.Txn) error {
+		recentBlocks, err := database.BlocksRecentTxn(txn, 1)
+		if err != nil {
+			return err
+		}
+		if len(recentBlocks) == 0 {
+			return database.ErrBlockNotFound
+		}
+		ret = recentBlocks[0]
+		return nil
+	})
+	return ret, err
+}
+
+func (s *databaseBlockStore) PruneBefore(
+	startSlot uint64,
+	slot uint64,
+	limit int,
+	txn *database.Txn,
+) (int, uint64, error) {
+	var ret int
+	nextSlot := startSlot
+	err := s.do(txn, true, func(txn *database.Txn) error {
+		var err error
+		ret, nextSlot, err = database.BlocksPruneCborBeforeSlotTxn(
+			txn,
+			startSlot,
+			slot,
+			limit,
+		)
+		return err
+	})
+	return ret, nextSlot, err
+}
+
+func (s *databaseBlockStore) Rollback(
+	point ocommon.Point,
+	txn *database.Txn,
+) error {
+	return s.do(txn, true, func(txn *database.Txn) error {
+		var rollbackBlockIndex uint64
+		if point.Slot > 0 || len(point.Hash) > 0 {
+			rollbackBlock, err := database.BlockByPointTxn(txn, point)
+			if err != nil {
+				return err
+			}
+			rollbackBlockIndex = rollbackBlock.ID
+		}
+		for index := rollbackBlockIndex + 1; ; index++ {
+			tmpBlock, err := s.db.BlockByIndex(index, txn)
+			if err != nil {
+				if errors.Is(err, database.ErrBlockNotFound) {
+					break
+				}
+				return err
+			}
+			if err := database.BlockDeleteTxn(txn, tmpBlock); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
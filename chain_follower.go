@@ -0,0 +1,150 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/blinklabs-io/dingo/chain"
+	gledger "github.com/blinklabs-io/gouroboros/ledger"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+// ChainUpdate represents either a roll forward to a new block or a roll backward to an earlier point
+type ChainUpdate struct {
+	Rollback bool
+	Point    ocommon.Point
+	Block    gledger.Block // Decoded block for a roll forward. This is nil for a roll backward
+}
+
+// FollowChain returns a channel that receives updates for the local chain starting at the most recent of the specified
+// points, along with a function to stop following. The first update is always a roll backward to the intersection
+// point, and subsequent roll backwards should be handled by discarding any blocks after the rollback point. The cursor
+// is independent of the node's own sync and of other followers. An empty list of points starts at the chain origin. A
+// closed channel is returned if the node is not running or none of the points are on the local chain
+func (n *Node) FollowChain(
+	from []ocommon.Point,
+) (<-chan ChainUpdate, func()) {
+	retCh := make(chan ChainUpdate, blockSubscriptionQueueSize)
+	if n.ledgerState == nil {
+		close(retCh)
+		return retCh, func() {}
+	}
+	chainIter, intersectPoint, err := n.followChainIterator(from)
+	if err != nil {
+		n.config.logger.Error(
+			fmt.Sprintf("chain follower: failed to start: %s", err),
+			"component", "node",
+		)
+		close(retCh)
+		return retCh, func() {}
+	}
+	doneCh := make(chan struct{})
+	sendUpdate := func(update ChainUpdate) bool {
+		select {
+		case <-doneCh:
+			return false
+		case retCh <- update:
+			return true
+		}
+	}
+	go func() {
+		defer close(retCh)
+		defer chainIter.Cancel()
+		// Start with a rollback to the intersection, like the chainsync protocol
+		if !sendUpdate(ChainUpdate{Rollback: true, Point: intersectPoint}) {
+			return
+		}
+		for {
+			next, err := chainIter.Next(true)
+			if err != nil {
+				if errors.Is(err, chain.ErrIteratorChainTip) {
+					continue
+				}
+				// The follower was stopped
+				if errors.Is(err, chain.ErrIteratorCancelled) {
+					return
+				}
+				n.config.logger.Error(
+					fmt.Sprintf(
+						"chain follower: failed to get next block: %s",
+						err,
+					),
+					"component", "node",
+				)
+				return
+			}
+			if next == nil {
+				continue
+			}
+			update := ChainUpdate{
+				Rollback: next.Rollback,
+				Point:    next.Point,
+			}
+			if !next.Rollback {
+				tmpBlock, err := next.Block.Decode()
+				if err != nil {
+					n.config.logger.Error(
+						fmt.Sprintf(
+							"chain follower: failed to decode block: %s",
+							err,
+						),
+						"component", "node",
+					)
+					return
+				}
+				update.Block = tmpBlock
+			}
+			if !sendUpdate(update) {
+				return
+			}
+		}
+	}()
+	var cancelOnce sync.Once
+	cancelFunc := func() {
+		cancelOnce.Do(func() {
+			close(doneCh)
+			// Wake up the goroutine if it's blocked waiting for the next block
+			chainIter.Cancel()
+		})
+	}
+	return retCh, cancelFunc
+}
+
+// followChainIterator returns a chain iterator starting at the intersection of the specified points with the local
+// chain, along with the intersection point
+func (n *Node) followChainIterator(
+	from []ocommon.Point,
+) (*chain.ChainIterator, ocommon.Point, error) {
+	intersectPoint := ocommon.NewPointOrigin()
+	if len(from) > 0 {
+		tmpPoint, err := n.ledgerState.GetIntersectPoint(from)
+		if err != nil {
+			return nil, intersectPoint, err
+		}
+		if tmpPoint == nil {
+			return nil, intersectPoint, ochainsync.ErrIntersectNotFound
+		}
+		intersectPoint = *tmpPoint
+	}
+	chainIter, err := n.ledgerState.GetChainFromPoint(intersectPoint, false)
+	if err != nil {
+		return nil, intersectPoint, err
+	}
+	return chainIter, intersectPoint, nil
+}
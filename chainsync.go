@@ -15,27 +15,31 @@
 package dingo
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"time"
 
-	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/dingo/chainsync"
+	"github.com/blinklabs-io/dingo/connmanager"
 	"github.com/blinklabs-io/dingo/event"
 	"github.com/blinklabs-io/dingo/ledger"
 	ouroboros "github.com/blinklabs-io/gouroboros"
 	gledger "github.com/blinklabs-io/gouroboros/ledger"
 	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
 	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 const (
-	chainsyncIntersectPointCount = 100
+	defaultChainsyncIntersectPointCount = 100
+	maxChainsyncIntersectPointCount     = 1000
+	defaultDeepRollbackThreshold        = 100
 )
 
 func (n *Node) chainsyncServerConnOpts() []ochainsync.ChainSyncOptionFunc {
-	return []ochainsync.ChainSyncOptionFunc{
-		ochainsync.WithFindIntersectFunc(n.chainsyncServerFindIntersect),
-		ochainsync.WithRequestNextFunc(n.chainsyncServerRequestNext),
-	}
+	return n.chainsyncState.ServerConnOpts()
 }
 
 func (n *Node) chainsyncClientConnOpts() []ochainsync.ChainSyncOptionFunc {
@@ -54,12 +58,24 @@ func (n *Node) chainsyncClientStart(connId ouroboros.ConnectionId) error {
 	if conn == nil {
 		return fmt.Errorf("failed to lookup connection ID: %s", connId.String())
 	}
+	intersectPointCount := n.config.intersectPointCount
+	if intersectPointCount == 0 {
+		intersectPointCount = defaultChainsyncIntersectPointCount
+	}
 	intersectPoints, err := n.ledgerState.RecentChainPoints(
-		chainsyncIntersectPointCount,
+		intersectPointCount,
 	)
 	if err != nil {
 		return err
 	}
+	// Fall back to persisted sync cursor if we have no stored chain points
+	if len(intersectPoints) == 0 {
+		cursorPoints, err := n.ledgerState.SyncCursorPoints()
+		if err != nil {
+			return err
+		}
+		intersectPoints = append(intersectPoints, cursorPoints...)
+	}
 	// Determine start point if we have no stored chain points
 	if len(intersectPoints) == 0 {
 		if n.config.intersectTip {
@@ -84,112 +100,69 @@ func (n *Node) chainsyncClientStart(connId ouroboros.ConnectionId) error {
 	return conn.ChainSync().Client.Sync(intersectPoints)
 }
 
-func (n *Node) chainsyncServerFindIntersect(
-	ctx ochainsync.CallbackContext,
-	points []ocommon.Point,
-) (ocommon.Point, ochainsync.Tip, error) {
-	n.ledgerState.RLock()
-	defer n.ledgerState.RUnlock()
-	var retPoint ocommon.Point
-	var retTip ochainsync.Tip
-	// Find intersection
-	intersectPoint, err := n.ledgerState.GetIntersectPoint(points)
-	if err != nil {
-		return retPoint, retTip, err
+// PauseChainSync stops the chainsync client from processing further roll forwards and roll backwards from upstream
+// peers, such as while taking a consistent database backup. Connections are left open, and callbacks received while
+// paused block until ResumeChainSync is called, so no chain updates are lost. Blocks already requested via blockfetch
+// before the pause may still be applied. It has no effect if already paused
+func (n *Node) PauseChainSync() {
+	n.chainsyncPauseMutex.Lock()
+	if n.chainsyncPauseChan != nil {
+		n.chainsyncPauseMutex.Unlock()
+		return
 	}
+	n.chainsyncPauseChan = make(chan struct{})
+	n.chainsyncPauseMutex.Unlock()
+	n.config.logger.Info(
+		"chainsync paused",
+		"component", "network",
+	)
+	n.publishChainsyncPauseEvent(chainsync.ClientPausedEventType)
+}
 
-	// Populate return tip
-	retTip = n.ledgerState.Tip()
-
-	if intersectPoint == nil {
-		return retPoint, retTip, ochainsync.ErrIntersectNotFound
+// ResumeChainSync resumes chainsync client processing from where it was paused. It has no effect if not paused
+func (n *Node) ResumeChainSync() {
+	n.chainsyncPauseMutex.Lock()
+	if n.chainsyncPauseChan == nil {
+		n.chainsyncPauseMutex.Unlock()
+		return
 	}
-
-	// Add our client to the chainsync state
-	_, err = n.chainsyncState.AddClient(
-		ctx.ConnectionId,
-		*intersectPoint,
+	close(n.chainsyncPauseChan)
+	n.chainsyncPauseChan = nil
+	n.chainsyncPauseMutex.Unlock()
+	n.config.logger.Info(
+		"chainsync resumed",
+		"component", "network",
 	)
-	if err != nil {
-		return retPoint, retTip, err
-	}
-
-	// Populate return point
-	retPoint = *intersectPoint
-
-	return retPoint, retTip, nil
+	n.publishChainsyncPauseEvent(chainsync.ClientResumedEventType)
 }
 
-func (n *Node) chainsyncServerRequestNext(
-	ctx ochainsync.CallbackContext,
-) error {
-	// Create/retrieve chainsync state for connection
-	tip := n.ledgerState.Tip()
-	clientState, err := n.chainsyncState.AddClient(
-		ctx.ConnectionId,
-		tip.Point,
-	)
-	if err != nil {
-		return err
+func (n *Node) publishChainsyncPauseEvent(eventType event.EventType) {
+	var tip ochainsync.Tip
+	if n.ledgerState != nil {
+		n.ledgerState.RLock()
+		tip = n.ledgerState.Tip()
+		n.ledgerState.RUnlock()
 	}
-	if clientState.NeedsInitialRollback {
-		err := ctx.Server.RollBackward(
-			clientState.Cursor,
-			tip,
-		)
-		if err != nil {
-			return err
-		}
-		clientState.NeedsInitialRollback = false
-		return nil
-	}
-	// Check for available block
-	next, err := clientState.ChainIter.Next(false)
-	if err != nil {
-		if !errors.Is(err, chain.ErrIteratorChainTip) {
-			return err
-		}
-	}
-	if next != nil {
-		if next.Rollback {
-			err = ctx.Server.RollBackward(
-				next.Point,
-				tip,
-			)
-		} else {
-			err = ctx.Server.RollForward(
-				next.Block.Type,
-				next.Block.Cbor,
-				tip,
-			)
-		}
-		return err
-	}
-	// Send AwaitReply
-	if err := ctx.Server.AwaitReply(); err != nil {
-		return err
+	n.eventBus.Publish(
+		eventType,
+		event.NewEvent(
+			eventType,
+			chainsync.ClientPauseEvent{
+				Tip: tip,
+			},
+		),
+	)
+}
+
+// chainsyncClientWaitIfPaused blocks while chainsync is paused. Blocking in the client callbacks stops us from
+// requesting more blocks, which leaves any pipelined responses queued in the protocol until we resume
+func (n *Node) chainsyncClientWaitIfPaused() {
+	n.chainsyncPauseMutex.Lock()
+	pauseChan := n.chainsyncPauseChan
+	n.chainsyncPauseMutex.Unlock()
+	if pauseChan != nil {
+		<-pauseChan
 	}
-	// Wait for next block and send
-	go func() {
-		next, _ := clientState.ChainIter.Next(true)
-		if next == nil {
-			return
-		}
-		tip := n.ledgerState.Tip()
-		if next.Rollback {
-			_ = ctx.Server.RollBackward(
-				next.Point,
-				tip,
-			)
-		} else {
-			_ = ctx.Server.RollForward(
-				next.Block.Type,
-				next.Block.Cbor,
-				tip,
-			)
-		}
-	}()
-	return nil
 }
 
 func (n *Node) chainsyncClientRollBackward(
@@ -197,6 +170,33 @@ func (n *Node) chainsyncClientRollBackward(
 	point ocommon.Point,
 	tip ochainsync.Tip,
 ) error {
+	n.chainsyncClientWaitIfPaused()
+	_, span := otel.Tracer("").Start(
+		context.TODO(),
+		"chainsync client roll backward",
+	)
+	defer span.End()
+	span.SetAttributes(
+		attribute.Int64("block.slot", int64(point.Slot)), // #nosec G115
+		attribute.Bool("chainsync.rollback", true),
+	)
+	if n.config.connEventSink != nil {
+		n.config.connEventSink(
+			ConnEvent{
+				Type:         connmanager.ConnEventTypeRollBackward,
+				Timestamp:    time.Now(),
+				ConnectionId: ctx.ConnectionId,
+				Point:        point,
+			},
+		)
+	}
+	// Refuse rollbacks beyond the immutable chain tip and drop the peer
+	if err := n.checkRollbackBeyondImmutable(ctx.ConnectionId, point); err != nil {
+		return err
+	}
+	n.checkDeepRollback(ctx.ConnectionId, point)
+	// Our local chain will be at the rollback point once it's applied
+	n.updateChainsyncLag(tip.Point.Slot, point.Slot)
 	// Generate event
 	n.eventBus.Publish(
 		ledger.ChainsyncEventType,
@@ -212,16 +212,124 @@ func (n *Node) chainsyncClientRollBackward(
 	return nil
 }
 
+// checkRollbackBeyondImmutable returns a RollbackTooDeepError if the rollback point is before our immutable chain tip,
+// which is k blocks behind our chain tip. The connection is closed with that error, which is carried by the resulting
+// ConnectionClosedEvent
+func (n *Node) checkRollbackBeyondImmutable(
+	connId ouroboros.ConnectionId,
+	point ocommon.Point,
+) error {
+	securityParam := n.chainManager.SecurityParam()
+	if securityParam == 0 {
+		return nil
+	}
+	immutableTip, err := n.chainManager.PrimaryChain().ImmutableTip()
+	if err != nil {
+		return fmt.Errorf("failed to get immutable tip: %w", err)
+	}
+	if point.Slot >= immutableTip.Point.Slot {
+		return nil
+	}
+	rollbackErr := chainsync.RollbackTooDeepError{
+		Point:         point,
+		ImmutableTip:  immutableTip.Point,
+		SecurityParam: securityParam,
+	}
+	n.config.logger.Warn(
+		fmt.Sprintf("closing connection to misbehaving peer: %s", rollbackErr),
+		"component", "network",
+		"connection_id", connId.String(),
+	)
+	if err := n.connManager.CloseConnectionWithError(connId, rollbackErr); err != nil &&
+		!errors.Is(err, connmanager.ErrConnectionNotFound) {
+		n.config.logger.Error(
+			"failed to close connection",
+			"component", "network",
+			"connection_id", connId.String(),
+			"error", err,
+		)
+	}
+	return rollbackErr
+}
+
+// checkDeepRollback logs a warning and generates a DeepRollbackEvent if the rollback point is further behind our local
+// chain tip than the configured threshold
+func (n *Node) checkDeepRollback(
+	connId ouroboros.ConnectionId,
+	point ocommon.Point,
+) {
+	threshold := n.config.deepRollbackThreshold
+	if threshold == 0 {
+		threshold = defaultDeepRollbackThreshold
+	}
+	localTip := n.ledgerState.Chain().HeaderTip()
+	if localTip.Point.Slot <= point.Slot {
+		return
+	}
+	depth := localTip.Point.Slot - point.Slot
+	if depth <= threshold {
+		return
+	}
+	n.config.logger.Warn(
+		fmt.Sprintf(
+			"deep rollback of %d slots from slot %d to slot %d",
+			depth,
+			localTip.Point.Slot,
+			point.Slot,
+		),
+		"component", "network",
+		"connection_id", connId.String(),
+	)
+	n.eventBus.Publish(
+		chainsync.DeepRollbackEventType,
+		event.NewEvent(
+			chainsync.DeepRollbackEventType,
+			chainsync.DeepRollbackEvent{
+				ConnectionId: connId,
+				Point:        point,
+				Tip:          localTip,
+				Depth:        depth,
+			},
+		),
+	)
+}
+
 func (n *Node) chainsyncClientRollForward(
 	ctx ochainsync.CallbackContext,
 	blockType uint,
 	blockData any,
 	tip ochainsync.Tip,
 ) error {
+	n.chainsyncClientWaitIfPaused()
+	_, span := otel.Tracer("").Start(
+		context.TODO(),
+		"chainsync client roll forward",
+	)
+	defer span.End()
 	switch v := blockData.(type) {
 	case gledger.BlockHeader:
 		blockSlot := v.SlotNumber()
 		blockHash := v.Hash().Bytes()
+		span.SetAttributes(
+			attribute.Int64("block.slot", int64(blockSlot)),         // #nosec G115
+			attribute.Int64("block.number", int64(v.BlockNumber())), // #nosec G115
+			attribute.Bool("chainsync.rollback", false),
+		)
+		n.updateChainsyncLag(
+			tip.Point.Slot,
+			n.ledgerState.Tip().Point.Slot,
+		)
+		n.blockfetchPeers.updateTip(ctx.ConnectionId, tip.Point.Slot)
+		if n.config.connEventSink != nil {
+			n.config.connEventSink(
+				ConnEvent{
+					Type:         connmanager.ConnEventTypeRollForward,
+					Timestamp:    time.Now(),
+					ConnectionId: ctx.ConnectionId,
+					Point:        ocommon.NewPoint(blockSlot, blockHash),
+				},
+			)
+		}
 		n.eventBus.Publish(
 			ledger.ChainsyncEventType,
 			event.NewEvent(
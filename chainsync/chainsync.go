@@ -15,68 +15,186 @@
 package chainsync
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"sync"
 
 	"github.com/blinklabs-io/dingo/chain"
 	"github.com/blinklabs-io/dingo/event"
-	"github.com/blinklabs-io/dingo/ledger"
 	ouroboros "github.com/blinklabs-io/gouroboros"
 	"github.com/blinklabs-io/gouroboros/connection"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
 	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
+// ErrMaxClientsReached is returned when adding a client would exceed the configured max number of chainsync clients
+var ErrMaxClientsReached = errors.New("max chainsync clients reached")
+
+// ErrRollbackTooDeep matches any RollbackTooDeepError when used with errors.Is
+var ErrRollbackTooDeep = errors.New("rollback beyond immutable chain tip")
+
+// RollbackTooDeepError is returned when an upstream peer requests a rollback to a point before our immutable chain
+// tip, which is more than k (the security parameter) blocks deep. A correct peer never does this
+type RollbackTooDeepError struct {
+	Point         ocommon.Point
+	ImmutableTip  ocommon.Point
+	SecurityParam uint64
+}
+
+func (e RollbackTooDeepError) Error() string {
+	return fmt.Sprintf(
+		"%s: rollback to slot %d is before immutable tip at slot %d (k=%d)",
+		ErrRollbackTooDeep,
+		e.Point.Slot,
+		e.ImmutableTip.Slot,
+		e.SecurityParam,
+	)
+}
+
+func (e RollbackTooDeepError) Is(target error) bool {
+	return target == ErrRollbackTooDeep
+}
+
+// LedgerState is the subset of the ledger state used for serving chainsync clients. It's satisfied by
+// *ledger.LedgerState
+type LedgerState interface {
+	RLock()
+	RUnlock()
+	GetIntersectPoint([]ocommon.Point) (*ocommon.Point, error)
+	GetChainFromPoint(ocommon.Point, bool) (*chain.ChainIterator, error)
+	Tip() ochainsync.Tip
+}
+
+// RetentionStartSlotFunc describes a callback function that returns the first slot of the retained block window
+type RetentionStartSlotFunc func() uint64
+
 type ChainsyncClientState struct {
 	Cursor               ocommon.Point
 	ChainIter            *chain.ChainIterator
 	NeedsInitialRollback bool
+	RateLimiter          *RateLimiter
 }
 
 type State struct {
 	sync.Mutex
 	eventBus     *event.EventBus
-	ledgerState  *ledger.LedgerState
+	ledgerState  LedgerState
+	logger       *slog.Logger
 	clients      map[ouroboros.ConnectionId]*ChainsyncClientState
 	clientConnId *ouroboros.ConnectionId // TODO: replace with handling of multiple chainsync clients (#385)
+	blocksPerSec int
+	bytesPerSec  int
+	maxClients   int
+	metrics      *stateMetrics
+	// retentionStartSlotFunc is used to refuse intersect points before the retained block window
+	retentionStartSlotFunc RetentionStartSlotFunc
 }
 
 func NewState(
 	eventBus *event.EventBus,
-	ledgerState *ledger.LedgerState,
+	ledgerState LedgerState,
+	promRegistry prometheus.Registerer,
 ) *State {
 	s := &State{
 		eventBus:    eventBus,
 		ledgerState: ledgerState,
-		clients:     make(map[ouroboros.ConnectionId]*ChainsyncClientState),
+		// Create logger to throw away logs until one is set
+		logger:  slog.New(slog.NewJSONHandler(io.Discard, nil)),
+		clients: make(map[ouroboros.ConnectionId]*ChainsyncClientState),
+	}
+	if promRegistry != nil {
+		s.initMetrics(promRegistry)
 	}
 	return s
 }
 
+// SetLogger sets the logger used when serving chainsync clients
+func (s *State) SetLogger(logger *slog.Logger) {
+	s.Lock()
+	defer s.Unlock()
+	if logger != nil {
+		s.logger = logger
+	}
+}
+
+// SetRetentionStartSlotFunc sets the callback used to find the first slot of the retained block window. Clients
+// whose intersect point is before this slot are refused, since the blocks after it can't be served
+func (s *State) SetRetentionStartSlotFunc(retentionStartSlotFunc RetentionStartSlotFunc) {
+	s.Lock()
+	defer s.Unlock()
+	s.retentionStartSlotFunc = retentionStartSlotFunc
+}
+
+// SetClientRateLimit sets the per-client limits for blocks and bytes sent per second to newly added clients. A value
+// of 0 disables the corresponding limit
+func (s *State) SetClientRateLimit(blocksPerSec int, bytesPerSec int) {
+	s.Lock()
+	defer s.Unlock()
+	s.blocksPerSec = blocksPerSec
+	s.bytesPerSec = bytesPerSec
+}
+
+// SetMaxClients sets the max number of concurrent chainsync clients. A value of 0 means no limit
+func (s *State) SetMaxClients(maxClients int) {
+	s.Lock()
+	defer s.Unlock()
+	s.maxClients = maxClients
+	if s.metrics != nil {
+		s.metrics.serverClientsMax.Set(float64(maxClients))
+	}
+}
+
 func (s *State) AddClient(
 	connId connection.ConnectionId,
 	intersectPoint ocommon.Point,
 ) (*ChainsyncClientState, error) {
 	s.Lock()
 	defer s.Unlock()
+	if clientState, ok := s.clients[connId]; ok {
+		return clientState, nil
+	}
+	if s.maxClients > 0 && len(s.clients) >= s.maxClients {
+		return nil, ErrMaxClientsReached
+	}
 	// Create initial chainsync state for connection
 	chainIter, err := s.ledgerState.GetChainFromPoint(intersectPoint, false)
 	if err != nil {
 		return nil, err
 	}
-	if _, ok := s.clients[connId]; !ok {
-		s.clients[connId] = &ChainsyncClientState{
-			Cursor:               intersectPoint,
-			ChainIter:            chainIter,
-			NeedsInitialRollback: true,
-		}
+	s.clients[connId] = &ChainsyncClientState{
+		Cursor:               intersectPoint,
+		ChainIter:            chainIter,
+		NeedsInitialRollback: true,
+		RateLimiter:          NewRateLimiter(s.blocksPerSec, s.bytesPerSec),
 	}
+	s.updateClientMetrics()
 	return s.clients[connId], nil
 }
 
 func (s *State) RemoveClient(connId connection.ConnectionId) {
 	s.Lock()
 	defer s.Unlock()
+	// Stop the client's iterator to release any pending blocking reads
+	if clientState, ok := s.clients[connId]; ok {
+		clientState.ChainIter.Cancel()
+	}
 	// Remove client state entry
 	delete(s.clients, connId)
+	s.updateClientMetrics()
+}
+
+// Close removes all client state and stops their iterators. This is used on shutdown
+func (s *State) Close() {
+	s.Lock()
+	defer s.Unlock()
+	for connId, clientState := range s.clients {
+		clientState.ChainIter.Cancel()
+		delete(s.clients, connId)
+	}
+	s.updateClientMetrics()
 }
 
 // TODO: replace with handling of multiple chainsync clients (#385)
@@ -0,0 +1,42 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"github.com/blinklabs-io/dingo/event"
+	ouroboros "github.com/blinklabs-io/gouroboros"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+const (
+	ClientPausedEventType  event.EventType = "chainsync.client-paused"
+	ClientResumedEventType event.EventType = "chainsync.client-resumed"
+	DeepRollbackEventType  event.EventType = "chainsync.deep-rollback"
+)
+
+// ClientPauseEvent is generated when chainsync client processing is paused or resumed
+type ClientPauseEvent struct {
+	Tip ochainsync.Tip // Local ledger tip at the time of the pause or resume
+}
+
+// DeepRollbackEvent is generated when an upstream peer rolls back our chain further than the configured threshold,
+// which can indicate network instability or an adversarial peer
+type DeepRollbackEvent struct {
+	ConnectionId ouroboros.ConnectionId // Connection ID of the peer that sent the rollback
+	Point        ocommon.Point          // Rollback point
+	Tip          ochainsync.Tip         // Local chain tip before the rollback
+	Depth        uint64                 // Rollback depth in slots
+}
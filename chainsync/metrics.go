@@ -0,0 +1,50 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type stateMetrics struct {
+	serverClients    prometheus.Gauge
+	serverClientsMax prometheus.Gauge
+}
+
+func (s *State) initMetrics(promRegistry prometheus.Registerer) {
+	promautoFactory := promauto.With(promRegistry)
+	s.metrics = &stateMetrics{}
+	s.metrics.serverClients = promautoFactory.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "chainsync_server_clients",
+			Help: "number of active chainsync server clients",
+		},
+	)
+	s.metrics.serverClientsMax = promautoFactory.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "chainsync_server_clients_max",
+			Help: "max number of chainsync server clients, or 0 for no limit",
+		},
+	)
+}
+
+// updateClientMetrics records the current number of chainsync server clients. The caller must hold the state lock
+func (s *State) updateClientMetrics() {
+	if s.metrics == nil {
+		return
+	}
+	s.metrics.serverClients.Set(float64(len(s.clients)))
+}
@@ -0,0 +1,101 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"sync"
+	"time"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+)
+
+// RateLimiter limits the rate at which blocks are sent to a chainsync client. Callers exceeding the limit are delayed
+// rather than rejected
+type RateLimiter struct {
+	mutex        sync.Mutex
+	blocksPerSec int
+	bytesPerSec  int
+	next         time.Time
+}
+
+// NewRateLimiter returns a RateLimiter allowing the specified number of blocks and bytes per second. A value of 0
+// disables the corresponding limit. If both values are 0, nil is returned
+func NewRateLimiter(blocksPerSec int, bytesPerSec int) *RateLimiter {
+	if blocksPerSec <= 0 && bytesPerSec <= 0 {
+		return nil
+	}
+	return &RateLimiter{
+		blocksPerSec: blocksPerSec,
+		bytesPerSec:  bytesPerSec,
+	}
+}
+
+// Wait blocks until a block of the specified size can be sent without exceeding the configured limits. It is safe to
+// call on a nil RateLimiter
+func (r *RateLimiter) Wait(size int) {
+	if r == nil {
+		return
+	}
+	if delay := r.reserve(size); delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// WaitRollForward blocks until the specified block can be sent in a RollForward message without exceeding the
+// configured limits. Node-to-node clients only receive the block header, so only the header bytes are counted against
+// the byte limit for them. It is safe to call on a nil RateLimiter
+func (r *RateLimiter) WaitRollForward(nodeToNode bool, blockCbor []byte) {
+	if r == nil {
+		return
+	}
+	size := len(blockCbor)
+	if nodeToNode && r.bytesPerSec > 0 {
+		size = blockHeaderSize(blockCbor)
+	}
+	r.Wait(size)
+}
+
+// blockHeaderSize returns the size of the header in the specified block CBOR, which is the first item in the block.
+// The full block size is returned if the header can't be extracted
+func blockHeaderSize(blockCbor []byte) int {
+	var tmpBlock []cbor.RawMessage
+	if _, err := cbor.Decode(blockCbor, &tmpBlock); err != nil || len(tmpBlock) == 0 {
+		return len(blockCbor)
+	}
+	return len(tmpBlock[0])
+}
+
+// reserve accounts for sending a block of the specified size and returns how long the caller must wait before sending it
+func (r *RateLimiter) reserve(size int) time.Duration {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	now := time.Now()
+	if r.next.Before(now) {
+		r.next = now
+	}
+	var cost time.Duration
+	if r.blocksPerSec > 0 {
+		cost = time.Second / time.Duration(r.blocksPerSec)
+	}
+	if r.bytesPerSec > 0 {
+		cost = max(
+			cost,
+			time.Duration(size)*time.Second/time.Duration(r.bytesPerSec),
+		)
+	}
+	delay := r.next.Sub(now)
+	r.next = r.next.Add(cost)
+	return delay
+}
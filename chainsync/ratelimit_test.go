@@ -0,0 +1,91 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+)
+
+func TestRateLimiterDisabled(t *testing.T) {
+	if r := NewRateLimiter(0, 0); r != nil {
+		t.Fatalf("expected nil rate limiter when both limits are disabled")
+	}
+	// Calling on a nil RateLimiter is a no-op
+	var r *RateLimiter
+	r.Wait(1000000)
+	r.WaitRollForward(true, []byte{0x80})
+}
+
+func TestRateLimiterBlocksPerSec(t *testing.T) {
+	r := NewRateLimiter(10, 0)
+	if delay := r.reserve(1000000); delay != 0 {
+		t.Fatalf("first block should not be delayed, got %s", delay)
+	}
+	// The block limit ignores the block size
+	delay := r.reserve(1000000)
+	if delay < 90*time.Millisecond || delay > 100*time.Millisecond {
+		t.Fatalf("did not get expected delay for second block: got %s, expected ~100ms", delay)
+	}
+}
+
+func TestRateLimiterBytesPerSec(t *testing.T) {
+	r := NewRateLimiter(0, 1000)
+	if delay := r.reserve(500); delay != 0 {
+		t.Fatalf("first block should not be delayed, got %s", delay)
+	}
+	delay := r.reserve(500)
+	if delay < 490*time.Millisecond || delay > 500*time.Millisecond {
+		t.Fatalf("did not get expected delay for second block: got %s, expected ~500ms", delay)
+	}
+}
+
+func TestBlockHeaderSize(t *testing.T) {
+	header := []byte{0x01, 0x02, 0x03}
+	blockCbor, err := cbor.Encode([]any{header, make([]byte, 1000)})
+	if err != nil {
+		t.Fatalf("unexpected error encoding block: %s", err)
+	}
+	// The header is encoded as a 1 byte bytestring prefix followed by the contents
+	if size := blockHeaderSize(blockCbor); size != len(header)+1 {
+		t.Fatalf("did not get expected header size: got %d, expected %d", size, len(header)+1)
+	}
+	// Fall back to the full size for anything that doesn't look like a block
+	invalidCbor := []byte{0xff, 0x00}
+	if size := blockHeaderSize(invalidCbor); size != len(invalidCbor) {
+		t.Fatalf("did not get expected fallback size: got %d, expected %d", size, len(invalidCbor))
+	}
+}
+
+func TestRateLimiterWaitRollForwardNodeToNode(t *testing.T) {
+	blockCbor, err := cbor.Encode([]any{[]byte{0x01}, make([]byte, 1000)})
+	if err != nil {
+		t.Fatalf("unexpected error encoding block: %s", err)
+	}
+	// Node-to-node clients only receive the header, so sending a large block shouldn't use up the byte budget
+	r := NewRateLimiter(0, 100)
+	r.WaitRollForward(true, blockCbor)
+	if delay := r.reserve(0); delay > 50*time.Millisecond {
+		t.Fatalf("node-to-node block was counted as more than its header: got delay %s", delay)
+	}
+	// Node-to-client clients receive the full block
+	r = NewRateLimiter(0, 100)
+	r.WaitRollForward(false, blockCbor)
+	if delay := r.reserve(0); delay < 9*time.Second {
+		t.Fatalf("node-to-client block was not counted in full: got delay %s", delay)
+	}
+}
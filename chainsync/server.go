@@ -0,0 +1,206 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/gouroboros/protocol"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// ServerConnOpts returns the chainsync protocol options for serving chainsync clients from this state
+func (s *State) ServerConnOpts() []ochainsync.ChainSyncOptionFunc {
+	return []ochainsync.ChainSyncOptionFunc{
+		ochainsync.WithFindIntersectFunc(s.serverFindIntersect),
+		ochainsync.WithRequestNextFunc(s.serverRequestNext),
+	}
+}
+
+func (s *State) serverFindIntersect(
+	ctx ochainsync.CallbackContext,
+	points []ocommon.Point,
+) (ocommon.Point, ochainsync.Tip, error) {
+	s.ledgerState.RLock()
+	defer s.ledgerState.RUnlock()
+	var retPoint ocommon.Point
+	var retTip ochainsync.Tip
+	// Find intersection
+	intersectPoint, err := s.ledgerState.GetIntersectPoint(points)
+	if err != nil {
+		return retPoint, retTip, err
+	}
+
+	// Populate return tip
+	retTip = s.ledgerState.Tip()
+
+	if intersectPoint == nil {
+		return retPoint, retTip, ochainsync.ErrIntersectNotFound
+	}
+
+	// Refuse intersect points before our retained block window, since we can't serve the blocks after them
+	s.Lock()
+	retentionStartSlotFunc := s.retentionStartSlotFunc
+	s.Unlock()
+	if retentionStartSlotFunc != nil {
+		if retentionStartSlot := retentionStartSlotFunc(); intersectPoint.Slot < retentionStartSlot {
+			s.logger.Warn(
+				fmt.Sprintf(
+					"refusing chainsync client: intersect point at slot %d is before retained block window starting at slot %d",
+					intersectPoint.Slot,
+					retentionStartSlot,
+				),
+				"component", "node",
+				"connection_id", ctx.ConnectionId.String(),
+			)
+			return retPoint, retTip, ochainsync.ErrIntersectNotFound
+		}
+	}
+
+	// Add our client to the chainsync state
+	_, err = s.AddClient(
+		ctx.ConnectionId,
+		*intersectPoint,
+	)
+	if err != nil {
+		if errors.Is(err, ErrMaxClientsReached) {
+			// Refuse the client without tearing down the connection
+			s.logger.Warn(
+				"refusing chainsync client: max clients reached",
+				"component", "node",
+				"connection_id", ctx.ConnectionId.String(),
+			)
+			return retPoint, retTip, ochainsync.ErrIntersectNotFound
+		}
+		return retPoint, retTip, err
+	}
+
+	// Populate return point
+	retPoint = *intersectPoint
+
+	return retPoint, retTip, nil
+}
+
+func (s *State) serverRequestNext(
+	ctx ochainsync.CallbackContext,
+) error {
+	_, span := otel.Tracer("").Start(
+		context.TODO(),
+		"chainsync server request next",
+	)
+	defer span.End()
+	// Create/retrieve chainsync state for connection
+	tip := s.ledgerState.Tip()
+	clientState, err := s.AddClient(
+		ctx.ConnectionId,
+		tip.Point,
+	)
+	if err != nil {
+		return err
+	}
+	if clientState.NeedsInitialRollback {
+		err := ctx.Server.RollBackward(
+			clientState.Cursor,
+			tip,
+		)
+		if err != nil {
+			return err
+		}
+		clientState.NeedsInitialRollback = false
+		return nil
+	}
+	// Check for available block
+	next, err := clientState.ChainIter.Next(false)
+	if err != nil {
+		if !errors.Is(err, chain.ErrIteratorChainTip) {
+			return err
+		}
+	}
+	if next != nil {
+		span.SetAttributes(
+			attribute.Bool("chainsync.rollback", next.Rollback),
+		)
+		if next.Rollback {
+			span.SetAttributes(
+				attribute.Int64("block.slot", int64(next.Point.Slot)), // #nosec G115
+			)
+			err = ctx.Server.RollBackward(
+				next.Point,
+				tip,
+			)
+		} else {
+			span.SetAttributes(
+				attribute.Int64("block.slot", int64(next.Block.Slot)),     // #nosec G115
+				attribute.Int64("block.number", int64(next.Block.Number)), // #nosec G115
+			)
+			clientState.RateLimiter.WaitRollForward(
+				ctx.Server.Mode() == protocol.ProtocolModeNodeToNode,
+				next.Block.Cbor,
+			)
+			err = ctx.Server.RollForward(
+				next.Block.Type,
+				next.Block.Cbor,
+				tip,
+			)
+		}
+		return err
+	}
+	// Send AwaitReply
+	if err := ctx.Server.AwaitReply(); err != nil {
+		return err
+	}
+	// Wait for next block and send. The iterator is cancelled when the client disconnects, which unblocks this
+	go func() {
+		next, err := clientState.ChainIter.Next(true)
+		if err != nil {
+			if !errors.Is(err, chain.ErrIteratorCancelled) {
+				s.logger.Error(
+					"failed to get next block for chainsync client",
+					"component", "node",
+					"connection_id", ctx.ConnectionId.String(),
+					"error", err,
+				)
+			}
+			return
+		}
+		if next == nil {
+			return
+		}
+		tip := s.ledgerState.Tip()
+		if next.Rollback {
+			_ = ctx.Server.RollBackward(
+				next.Point,
+				tip,
+			)
+		} else {
+			clientState.RateLimiter.WaitRollForward(
+				ctx.Server.Mode() == protocol.ProtocolModeNodeToNode,
+				next.Block.Cbor,
+			)
+			_ = ctx.Server.RollForward(
+				next.Block.Type,
+				next.Block.Cbor,
+				tip,
+			)
+		}
+	}()
+	return nil
+}
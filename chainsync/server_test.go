@@ -0,0 +1,168 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsync_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/dingo/chainsync"
+	"github.com/blinklabs-io/dingo/internal/test/chainsynctest"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+const testEventTimeout = 5 * time.Second
+
+func newTestChain(t *testing.T, count int) (*chainsynctest.MockLedgerState, []*chainsynctest.MockBlock) {
+	t.Helper()
+	ls, err := chainsynctest.NewMockLedgerState()
+	if err != nil {
+		t.Fatalf("unexpected error creating mock ledger state: %s", err)
+	}
+	var blocks []*chainsynctest.MockBlock
+	var prevBlock *chainsynctest.MockBlock
+	for i := range count {
+		// #nosec G115
+		block := chainsynctest.NewMockBlock(prevBlock, uint64((i+1)*20))
+		blocks = append(blocks, block)
+		prevBlock = block
+	}
+	if err := ls.AddBlocks(blocks...); err != nil {
+		t.Fatalf("unexpected error adding blocks: %s", err)
+	}
+	return ls, blocks
+}
+
+func newTestHarness(t *testing.T, ls *chainsynctest.MockLedgerState, state *chainsync.State) *chainsynctest.Harness {
+	t.Helper()
+	h, err := chainsynctest.NewHarness(ls, state)
+	if err != nil {
+		t.Fatalf("unexpected error creating harness: %s", err)
+	}
+	t.Cleanup(func() {
+		_ = h.Close()
+	})
+	return h
+}
+
+func expectRollBackward(t *testing.T, h *chainsynctest.Harness, point ocommon.Point) {
+	t.Helper()
+	evt, err := h.NextEvent(testEventTimeout)
+	if err != nil {
+		t.Fatalf("unexpected error waiting for roll backward: %s", err)
+	}
+	if !evt.Rollback {
+		t.Fatalf("expected roll backward, got roll forward")
+	}
+	if evt.Point.Slot != point.Slot || string(evt.Point.Hash) != string(point.Hash) {
+		t.Fatalf("did not get expected roll backward point: got slot %d, wanted slot %d", evt.Point.Slot, point.Slot)
+	}
+}
+
+func expectRollForward(t *testing.T, h *chainsynctest.Harness, block *chainsynctest.MockBlock) {
+	t.Helper()
+	evt, err := h.NextEvent(testEventTimeout)
+	if err != nil {
+		t.Fatalf("unexpected error waiting for roll forward: %s", err)
+	}
+	if evt.Rollback {
+		t.Fatalf("expected roll forward, got roll backward to slot %d", evt.Point.Slot)
+	}
+	if string(evt.BlockCbor) != string(block.Cbor()) {
+		t.Fatalf("did not get expected block: got %x, wanted %x", evt.BlockCbor, block.Cbor())
+	}
+}
+
+func TestServerSyncFromOrigin(t *testing.T) {
+	ls, blocks := newTestChain(t, 3)
+	h := newTestHarness(t, ls, nil)
+	if err := h.Client().Sync([]ocommon.Point{ocommon.NewPointOrigin()}); err != nil {
+		t.Fatalf("unexpected error starting sync: %s", err)
+	}
+	expectRollBackward(t, h, ocommon.NewPointOrigin())
+	for _, block := range blocks {
+		expectRollForward(t, h, block)
+	}
+}
+
+func TestServerFollowTip(t *testing.T) {
+	ls, blocks := newTestChain(t, 2)
+	h := newTestHarness(t, ls, nil)
+	if err := h.Client().Sync([]ocommon.Point{blocks[1].Point()}); err != nil {
+		t.Fatalf("unexpected error starting sync: %s", err)
+	}
+	expectRollBackward(t, h, blocks[1].Point())
+	// The client is at our tip, so the server sends AwaitReply and nothing is delivered yet
+	if evt, err := h.NextEvent(100 * time.Millisecond); !errors.Is(err, chainsynctest.ErrEventTimeout) {
+		t.Fatalf("expected no event while at tip, got: %+v", evt)
+	}
+	// Extend the chain and check that the waiting client receives the new block
+	newBlock := chainsynctest.NewMockBlock(blocks[1], 60)
+	if err := ls.AddBlocks(newBlock); err != nil {
+		t.Fatalf("unexpected error adding block: %s", err)
+	}
+	expectRollForward(t, h, newBlock)
+	// And the following block, which is requested after the await reply is resolved
+	nextBlock := chainsynctest.NewMockBlock(newBlock, 80)
+	if err := ls.AddBlocks(nextBlock); err != nil {
+		t.Fatalf("unexpected error adding block: %s", err)
+	}
+	expectRollForward(t, h, nextBlock)
+}
+
+func TestServerRollback(t *testing.T) {
+	ls, blocks := newTestChain(t, 3)
+	h := newTestHarness(t, ls, nil)
+	if err := h.Client().Sync([]ocommon.Point{blocks[2].Point()}); err != nil {
+		t.Fatalf("unexpected error starting sync: %s", err)
+	}
+	expectRollBackward(t, h, blocks[2].Point())
+	// Roll back the chain while the client is waiting at the tip
+	if err := ls.Rollback(blocks[0].Point()); err != nil {
+		t.Fatalf("unexpected error rolling back chain: %s", err)
+	}
+	expectRollBackward(t, h, blocks[0].Point())
+	// Extend the chain on a new fork
+	forkBlock := chainsynctest.NewMockBlock(blocks[0], 50)
+	if err := ls.AddBlocks(forkBlock); err != nil {
+		t.Fatalf("unexpected error adding block: %s", err)
+	}
+	expectRollForward(t, h, forkBlock)
+}
+
+func TestServerIntersectNotFound(t *testing.T) {
+	ls, _ := newTestChain(t, 2)
+	h := newTestHarness(t, ls, nil)
+	unknownBlock := chainsynctest.NewMockBlock(nil, 999)
+	err := h.Client().Sync([]ocommon.Point{unknownBlock.Point()})
+	if !errors.Is(err, ochainsync.ErrIntersectNotFound) {
+		t.Fatalf("did not get expected error: got %v, wanted %s", err, ochainsync.ErrIntersectNotFound)
+	}
+}
+
+func TestServerIntersectBeforeRetention(t *testing.T) {
+	ls, blocks := newTestChain(t, 3)
+	state := chainsync.NewState(nil, ls, nil)
+	state.SetRetentionStartSlotFunc(func() uint64 {
+		return blocks[1].MockSlot
+	})
+	h := newTestHarness(t, ls, state)
+	err := h.Client().Sync([]ocommon.Point{blocks[0].Point()})
+	if !errors.Is(err, ochainsync.ErrIntersectNotFound) {
+		t.Fatalf("did not get expected error: got %v, wanted %s", err, ochainsync.ErrIntersectNotFound)
+	}
+}
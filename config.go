@@ -19,9 +19,16 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net"
+	"strconv"
+	"time"
 
+	"github.com/blinklabs-io/dingo/chain"
 	"github.com/blinklabs-io/dingo/config/cardano"
 	"github.com/blinklabs-io/dingo/connmanager"
+	"github.com/blinklabs-io/dingo/ledger"
+	"github.com/blinklabs-io/dingo/mempool"
+	"github.com/blinklabs-io/dingo/peergov"
 	"github.com/blinklabs-io/dingo/topology"
 	ouroboros "github.com/blinklabs-io/gouroboros"
 	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
@@ -30,25 +37,93 @@ import (
 
 type ListenerConfig = connmanager.ListenerConfig
 
+type ConnEvent = connmanager.ConnEvent
+
+type PeerInfo = peergov.PeerInfo
+
+type MempoolTransaction = mempool.MempoolTransaction
+
+type RewardCalculator = ledger.RewardCalculator
+
+type RewardCalculationInput = ledger.RewardCalculationInput
+
+type ClosedByOperatorError = connmanager.ClosedByOperatorError
+
+type NodeToNodeVersionData = connmanager.NodeToNodeVersionData
+
+type BlockStore = chain.BlockStore
+
 type Config struct {
-	badgerCacheSize    int64
-	cardanoNodeConfig  *cardano.CardanoNodeConfig
-	dataDir            string
-	intersectPoints    []ocommon.Point
-	intersectTip       bool
-	logger             *slog.Logger
-	listeners          []ListenerConfig
-	network            string
-	networkMagic       uint32
-	outboundSourcePort uint
-	utxorpcPort        uint
-	tlsCertFilePath    string
-	tlsKeyFilePath     string
-	peerSharing        bool
-	promRegistry       prometheus.Registerer
-	topologyConfig     *topology.TopologyConfig
-	tracing            bool
-	tracingStdout      bool
+	badgerCacheSize       int64
+	blockfetchBatchSize   int
+	blockfetchMaxBytes    int
+	blockfetchStrategy    BlockFetchStrategy
+	blockStore            BlockStore
+	blockRetention        uint64
+	bulkSync              bool
+	bulkSyncThreshold     uint64
+	cardanoNodeConfig     *cardano.CardanoNodeConfig
+	byronGenesisFile      string
+	shelleyGenesisFile    string
+	alonzoGenesisFile     string
+	conwayGenesisFile     string
+	chainsyncBlockRate    int
+	chainsyncByteRate     int
+	chainsyncMaxClients   int
+	connEventSink         connmanager.ConnEventSinkFunc
+	dataDir               string
+	deepRollbackThreshold uint64
+	immutableDbPath       string
+	intersectPointCount   int
+	intersectPoints       []ocommon.Point
+	intersectTip          bool
+	ledgerBatchSize       int
+	ledgerFlushInterval   time.Duration
+	inboundAllowList      []string
+	inboundDenyList       []string
+	adminApiAddress       string
+	adminApiToken         string
+	logger                *slog.Logger
+	componentLogLevels    map[string]slog.Level
+	maxInboundConns       int
+	maxInboundConnsPerIP  int
+	maxReconnectAttempts  int
+	targetOutboundPeers   int
+	mempoolFeePriority    bool
+	metadataAutoRecover   bool
+	metadataReadReplica   bool
+	metricsListenAddress  string
+	metricsNamespace      string
+	listeners             []ListenerConfig
+	network               string
+	networkMagic          uint32
+	ntcListenAddress      string
+	ntcListenPort         uint
+	ntnListenAddress      string
+	ntnListenPort         uint
+	outboundAddressFamily string
+	outboundSourcePort    uint
+	outboundPortStart     int
+	outboundPortEnd       int
+	utxorpcPort           uint
+	tlsCertFilePath       string
+	tlsKeyFilePath        string
+	peerIdleTimeout       time.Duration
+	bandwidthLimit        uint64
+	keepAliveInterval     time.Duration
+	keepAliveTimeout      time.Duration
+	peerSharing           bool
+	peerSharingInterval   time.Duration
+	peerSharingAmount     uint8
+	promRegistry          prometheus.Registerer
+	promGatherer          prometheus.Gatherer
+	proxyProtocol         bool
+	scriptEvaluator       ScriptEvaluatorFunc
+	rewardCalculator      RewardCalculator
+	topologyConfig        *topology.TopologyConfig
+	tracing               bool
+	tracingStdout         bool
+	verifyCborRoundtrip   bool
 }
 
 // configPopulateNetworkMagic uses the named network (if specified) to determine the network magic value (if not specified)
@@ -65,6 +140,99 @@ func (n *Node) configPopulateNetworkMagic() error {
 	return nil
 }
 
+// configLoadGenesisFiles loads any genesis files specified in the config, which override those from the cardano-node
+// config. The cardano-node config is copied rather than modified
+func (n *Node) configLoadGenesisFiles() error {
+	if n.config.byronGenesisFile == "" &&
+		n.config.shelleyGenesisFile == "" &&
+		n.config.alonzoGenesisFile == "" &&
+		n.config.conwayGenesisFile == "" {
+		return nil
+	}
+	var tmpNodeCfg cardano.CardanoNodeConfig
+	if n.config.cardanoNodeConfig != nil {
+		tmpNodeCfg = *n.config.cardanoNodeConfig
+	}
+	var errs []error
+	if n.config.byronGenesisFile != "" {
+		if err := tmpNodeCfg.LoadByronGenesisFromFile(n.config.byronGenesisFile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if n.config.shelleyGenesisFile != "" {
+		if err := tmpNodeCfg.LoadShelleyGenesisFromFile(n.config.shelleyGenesisFile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if n.config.alonzoGenesisFile != "" {
+		if err := tmpNodeCfg.LoadAlonzoGenesisFromFile(n.config.alonzoGenesisFile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if n.config.conwayGenesisFile != "" {
+		if err := tmpNodeCfg.LoadConwayGenesisFromFile(n.config.conwayGenesisFile); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid genesis file: %w", err)
+	}
+	n.config.cardanoNodeConfig = &tmpNodeCfg
+	return nil
+}
+
+// configPopulateListeners validates the node-to-node and node-to-client listen addresses (if specified) and adds
+// the corresponding listeners
+func (n *Node) configPopulateListeners() error {
+	if n.config.ntnListenPort > 0 {
+		listenAddress, err := validateListenAddress(
+			n.config.ntnListenAddress,
+			n.config.ntnListenPort,
+		)
+		if err != nil {
+			return fmt.Errorf("invalid node-to-node listen address: %w", err)
+		}
+		n.config.listeners = append(
+			n.config.listeners,
+			ListenerConfig{
+				ListenNetwork: "tcp",
+				ListenAddress: listenAddress,
+				ReuseAddress:  true,
+			},
+		)
+	}
+	if n.config.ntcListenPort > 0 {
+		listenAddress, err := validateListenAddress(
+			n.config.ntcListenAddress,
+			n.config.ntcListenPort,
+		)
+		if err != nil {
+			return fmt.Errorf("invalid node-to-client listen address: %w", err)
+		}
+		n.config.listeners = append(
+			n.config.listeners,
+			ListenerConfig{
+				ListenNetwork: "tcp",
+				ListenAddress: listenAddress,
+				UseNtC:        true,
+			},
+		)
+	}
+	return nil
+}
+
+// validateListenAddress checks that the bind address is an IP address (or empty for all interfaces) and that the port
+// is valid, and returns the combined listen address
+func validateListenAddress(address string, port uint) (string, error) {
+	if address != "" && net.ParseIP(address) == nil {
+		return "", fmt.Errorf("not an IP address: %s", address)
+	}
+	if port > 65535 {
+		return "", fmt.Errorf("invalid port: %d", port)
+	}
+	return net.JoinHostPort(address, strconv.FormatUint(uint64(port), 10)), nil
+}
+
 func (n *Node) configValidate() error {
 	if n.config.networkMagic == 0 {
 		return fmt.Errorf(
@@ -72,6 +240,168 @@ func (n *Node) configValidate() error {
 			n.config.networkMagic,
 		)
 	}
+	switch n.config.outboundAddressFamily {
+	case "",
+		connmanager.AddressFamilyIPv4,
+		connmanager.AddressFamilyIPv6,
+		connmanager.AddressFamilyDual:
+	default:
+		return fmt.Errorf(
+			"invalid outbound address family: %s",
+			n.config.outboundAddressFamily,
+		)
+	}
+	if n.config.outboundPortStart != 0 || n.config.outboundPortEnd != 0 {
+		if n.config.outboundPortStart <= 0 ||
+			n.config.outboundPortEnd > 65535 ||
+			n.config.outboundPortStart > n.config.outboundPortEnd {
+			return fmt.Errorf(
+				"invalid outbound source port range: %d-%d",
+				n.config.outboundPortStart,
+				n.config.outboundPortEnd,
+			)
+		}
+	}
+	if err := adminApiValidateAddress(n.config.adminApiAddress, n.config.adminApiToken); err != nil {
+		return fmt.Errorf("invalid admin API config: %w", err)
+	}
+	if n.config.maxInboundConns < 0 {
+		return fmt.Errorf(
+			"invalid max inbound connections: %d",
+			n.config.maxInboundConns,
+		)
+	}
+	if n.config.maxInboundConnsPerIP < 0 {
+		return fmt.Errorf(
+			"invalid max inbound connections per IP: %d",
+			n.config.maxInboundConnsPerIP,
+		)
+	}
+	if _, err := connmanager.ParseCIDRList(n.config.inboundAllowList); err != nil {
+		return fmt.Errorf("invalid inbound allow list: %w", err)
+	}
+	if _, err := connmanager.ParseCIDRList(n.config.inboundDenyList); err != nil {
+		return fmt.Errorf("invalid inbound deny list: %w", err)
+	}
+	if n.config.intersectPointCount < 0 ||
+		n.config.intersectPointCount > maxChainsyncIntersectPointCount {
+		return fmt.Errorf(
+			"invalid intersect point count: %d, must be between 1 and %d",
+			n.config.intersectPointCount,
+			maxChainsyncIntersectPointCount,
+		)
+	}
+	if n.config.blockfetchBatchSize < 0 ||
+		n.config.blockfetchBatchSize > maxBlockfetchBatchSize {
+		return fmt.Errorf(
+			"invalid blockfetch batch size: %d, must be between 1 and %d",
+			n.config.blockfetchBatchSize,
+			maxBlockfetchBatchSize,
+		)
+	}
+	if n.config.metricsListenAddress != "" {
+		if _, ok := n.config.promRegistry.(prometheus.Gatherer); !ok {
+			return errors.New(
+				"invalid metrics listen address: prometheus registry must be set and implement prometheus.Gatherer",
+			)
+		}
+	}
+	if n.config.metricsNamespace != "" &&
+		!metricsNamespaceRegexp.MatchString(n.config.metricsNamespace) {
+		return fmt.Errorf(
+			"invalid metrics namespace: %s",
+			n.config.metricsNamespace,
+		)
+	}
+	if n.config.ledgerBatchSize < 0 {
+		return fmt.Errorf(
+			"invalid ledger batch size: %d",
+			n.config.ledgerBatchSize,
+		)
+	}
+	if n.config.ledgerFlushInterval < 0 {
+		return fmt.Errorf(
+			"invalid ledger flush interval: %s",
+			n.config.ledgerFlushInterval,
+		)
+	}
+	if n.config.blockfetchStrategy != "" &&
+		!n.config.blockfetchStrategy.valid() {
+		return fmt.Errorf(
+			"invalid blockfetch strategy: %s",
+			n.config.blockfetchStrategy,
+		)
+	}
+	if n.config.blockfetchMaxBytes < 0 {
+		return fmt.Errorf(
+			"invalid blockfetch max in-flight bytes: %d",
+			n.config.blockfetchMaxBytes,
+		)
+	}
+	if n.config.chainsyncBlockRate < 0 || n.config.chainsyncByteRate < 0 {
+		return fmt.Errorf(
+			"invalid chainsync server rate limit: %d blocks/sec, %d bytes/sec",
+			n.config.chainsyncBlockRate,
+			n.config.chainsyncByteRate,
+		)
+	}
+	if n.config.chainsyncMaxClients < 0 {
+		return fmt.Errorf(
+			"invalid chainsync server max clients: %d",
+			n.config.chainsyncMaxClients,
+		)
+	}
+	if n.config.keepAliveInterval < 0 || n.config.keepAliveTimeout < 0 {
+		return fmt.Errorf(
+			"invalid keep-alive interval/timeout: %s/%s",
+			n.config.keepAliveInterval,
+			n.config.keepAliveTimeout,
+		)
+	}
+	// The next keep-alive is sent after the interval regardless of whether a response was received, so the response
+	// timeout must expire first
+	keepAliveConfig := n.keepAliveConfig()
+	if keepAliveConfig.Timeout >= keepAliveConfig.Period {
+		return fmt.Errorf(
+			"invalid keep-alive timeout: %s, must be less than the keep-alive interval (%s)",
+			keepAliveConfig.Timeout,
+			keepAliveConfig.Period,
+		)
+	}
+	// Keep-alive traffic is what keeps a healthy but otherwise quiet connection from looking idle, so the idle timeout
+	// must allow for at least one keep-alive round trip
+	if n.config.peerIdleTimeout < 0 {
+		return fmt.Errorf(
+			"invalid peer idle timeout: %s",
+			n.config.peerIdleTimeout,
+		)
+	}
+	if n.config.peerIdleTimeout > 0 &&
+		n.config.peerIdleTimeout <= keepAliveConfig.Period {
+		return fmt.Errorf(
+			"invalid peer idle timeout: %s, must be greater than the keep-alive interval (%s)",
+			n.config.peerIdleTimeout,
+			keepAliveConfig.Period,
+		)
+	}
+	if n.config.peerSharingInterval < 0 {
+		return fmt.Errorf(
+			"invalid peer sharing request interval: %s",
+			n.config.peerSharingInterval,
+		)
+	}
+	if n.config.maxReconnectAttempts < 0 {
+		return fmt.Errorf(
+			"invalid max reconnect attempts: %d",
+			n.config.maxReconnectAttempts,
+		)
+	}
+	if n.config.targetOutboundPeers < 0 {
+		return fmt.Errorf(
+			"invalid target outbound peers: %d",
+			n.config.targetOutboundPeers,
+		)
+	}
 	if len(n.config.listeners) == 0 {
 		return errors.New("no listeners defined")
 	}
@@ -87,6 +417,10 @@ func (n *Node) configValidate() error {
 		)
 	}
 	if n.config.cardanoNodeConfig != nil {
+		// Make sure all genesis configs are available up front, rather than failing during block processing
+		if err := n.config.cardanoNodeConfig.ValidateGenesis(); err != nil {
+			return fmt.Errorf("invalid genesis config: %w", err)
+		}
 		shelleyGenesis := n.config.cardanoNodeConfig.ShelleyGenesis()
 		if shelleyGenesis == nil {
 			return errors.New("unable to get Shelley genesis information")
@@ -119,6 +453,78 @@ func NewConfig(opts ...ConfigOptionFunc) Config {
 	return c
 }
 
+// WithAdminApi enables the embedded HTTP admin API on the specified address (host:port). If token is not empty, it must
+// be provided as a bearer token in the Authorization header of all requests. A token is required unless the address is
+// a loopback address. The admin API is disabled by default
+func WithAdminApi(address string, token string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.adminApiAddress = address
+		c.adminApiToken = token
+	}
+}
+
+// WithBlockfetchBatchSize specifies the max number of blocks to request from a peer in a single blockfetch range. Larger
+// batches can speed up initial sync, but too-large batches can stall on slow peers and increase memory usage. This must be
+// between 1 and 5000, and defaults to 500
+func WithBlockfetchBatchSize(batchSize int) ConfigOptionFunc {
+	return func(c *Config) {
+		c.blockfetchBatchSize = batchSize
+	}
+}
+
+// WithBulkSync enables bulk sync mode, which is intended for syncing from genesis or from far behind the tip. This
+// uses larger DB write batches and collects more block headers ahead of blockfetch. Bulk sync mode is switched off
+// automatically once the node gets close to the upstream tip. It is disabled by default
+func WithBulkSync(bulkSync bool) ConfigOptionFunc {
+	return func(c *Config) {
+		c.bulkSync = bulkSync
+	}
+}
+
+// WithBulkSyncThreshold specifies the distance in slots from the upstream tip at which bulk sync mode is switched off.
+// This defaults to the security parameter (k) from the Shelley genesis
+func WithBulkSyncThreshold(slots uint64) ConfigOptionFunc {
+	return func(c *Config) {
+		c.bulkSyncThreshold = slots
+	}
+}
+
+// WithBlockStore specifies a custom storage backend for the blocks on the local chain, such as object storage or an
+// external database. The chainsync and blockfetch servers read blocks through it. By default, blocks are stored in the
+// node database
+func WithBlockStore(blockStore BlockStore) ConfigOptionFunc {
+	return func(c *Config) {
+		c.blockStore = blockStore
+	}
+}
+
+// WithBlockRetention enables pruning of block bodies older than the specified number of slots behind the tip. This is
+// useful for relays that only need to serve recent blocks. The block index and metadata are kept so that the node can
+// still find intersections with its upstream peers, but downstream peers can't sync from a point before the retained
+// window. Blocks from the current epoch and volatile blocks are always kept. A value of 0 (the default) keeps all blocks
+func WithBlockRetention(slots uint64) ConfigOptionFunc {
+	return func(c *Config) {
+		c.blockRetention = slots
+	}
+}
+
+// WithBlockFetchStrategy specifies how to choose which peer to fetch a block range from when multiple peers are available.
+// Latency is measured from the time a range is requested until its first block arrives. Peers that fail repeatedly are
+// temporarily deprioritized regardless of strategy. This defaults to BlockFetchStrategyMostAhead
+func WithBlockFetchStrategy(strategy BlockFetchStrategy) ConfigOptionFunc {
+	return func(c *Config) {
+		c.blockfetchStrategy = strategy
+	}
+}
+
+// WithBlockfetchMaxInflightBytes specifies the max bytes of fetched blocks to buffer before they are processed. This bounds
+// memory usage with large blockfetch batches. The default is no limit
+func WithBlockfetchMaxInflightBytes(maxBytes int) ConfigOptionFunc {
+	return func(c *Config) {
+		c.blockfetchMaxBytes = maxBytes
+	}
+}
+
 // WithCardanoNodeConfig specifies the CardanoNodeConfig object to use. This is mostly used for loading genesis config files
 // referenced by the dingo config
 func WithCardanoNodeConfig(
@@ -129,6 +535,90 @@ func WithCardanoNodeConfig(
 	}
 }
 
+// NewCustomNetwork returns a config option for running on a custom network from a directory of genesis files and the
+// network magic. The cardano-node config is built from the directory as described for
+// cardano.NewCustomNetworkConfig, which checks that the network magic and genesis hashes are consistent with the
+// genesis files. The option sets both the cardano-node config and the network magic
+func NewCustomNetwork(
+	genesisDir string,
+	networkMagic uint32,
+) (ConfigOptionFunc, error) {
+	nodeCfg, err := cardano.NewCustomNetworkConfig(genesisDir, networkMagic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid custom network: %w", err)
+	}
+	return func(c *Config) {
+		c.cardanoNodeConfig = nodeCfg
+		c.networkMagic = networkMagic
+	}, nil
+}
+
+// WithByronGenesisFile specifies a Byron genesis file to use instead of the one from the cardano-node config. The file
+// must match the Byron genesis hash from the cardano-node config, if any
+func WithByronGenesisFile(genesisFile string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.byronGenesisFile = genesisFile
+	}
+}
+
+// WithShelleyGenesisFile specifies a Shelley genesis file to use instead of the one from the cardano-node config. The
+// file must match the Shelley genesis hash from the cardano-node config, if any
+func WithShelleyGenesisFile(genesisFile string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.shelleyGenesisFile = genesisFile
+	}
+}
+
+// WithAlonzoGenesisFile specifies an Alonzo genesis file to use instead of the one from the cardano-node config. The
+// file must match the Alonzo genesis hash from the cardano-node config, if any
+func WithAlonzoGenesisFile(genesisFile string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.alonzoGenesisFile = genesisFile
+	}
+}
+
+// WithConwayGenesisFile specifies a Conway genesis file to use instead of the one from the cardano-node config. The
+// file must match the Conway genesis hash from the cardano-node config, if any
+func WithConwayGenesisFile(genesisFile string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.conwayGenesisFile = genesisFile
+	}
+}
+
+// WithChainsyncServerRateLimit specifies per-client limits on the number of blocks and bytes per second sent to
+// chainsync clients. Clients exceeding the limit are delayed rather than disconnected. A value of 0 disables the
+// corresponding limit, and the default is no limit
+func WithChainsyncServerRateLimit(blocksPerSec int, bytesPerSec int) ConfigOptionFunc {
+	return func(c *Config) {
+		c.chainsyncBlockRate = blocksPerSec
+		c.chainsyncByteRate = bytesPerSec
+	}
+}
+
+// WithChainsyncServerMaxClients specifies the max number of peers to serve chainsync to at once. New clients beyond this
+// limit are refused when finding an intersection. The default of 0 means no limit
+func WithChainsyncServerMaxClients(maxClients int) ConfigOptionFunc {
+	return func(c *Config) {
+		c.chainsyncMaxClients = maxClients
+	}
+}
+
+// WithConnectionEventSink specifies a function to receive structured connection events (connect, disconnect, reconnect,
+// chainsync roll forward/backward). This is useful for shipping these events to an external system
+func WithConnectionEventSink(sink func(ConnEvent)) ConfigOptionFunc {
+	return func(c *Config) {
+		c.connEventSink = sink
+	}
+}
+
+// WithDeepRollbackThreshold specifies the rollback depth in slots from the local chain tip beyond which a rollback from
+// an upstream peer is logged as a warning and generates a DeepRollbackEvent. The default is 100 slots
+func WithDeepRollbackThreshold(slots uint64) ConfigOptionFunc {
+	return func(c *Config) {
+		c.deepRollbackThreshold = slots
+	}
+}
+
 // WithDatabasePath specifies the persistent data directory to use. The default is to store everything in memory
 func WithDatabasePath(dataDir string) ConfigOptionFunc {
 	return func(c *Config) {
@@ -136,6 +626,40 @@ func WithDatabasePath(dataDir string) ConfigOptionFunc {
 	}
 }
 
+// WithImmutableDbPath specifies the path to a cardano-node compatible ImmutableDB directory to serve immutable blocks from
+// via blockfetch. Block ranges not fully contained in the ImmutableDB are served from our own store. This avoids
+// duplicating block storage when running alongside a cardano-node
+func WithImmutableDbPath(path string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.immutableDbPath = path
+	}
+}
+
+// WithInboundAllowList specifies a list of CIDR ranges to accept inbound node-to-node connections from. By default, all
+// addresses are allowed
+func WithInboundAllowList(cidrs []string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.inboundAllowList = cidrs
+	}
+}
+
+// WithInboundDenyList specifies a list of CIDR ranges to reject inbound node-to-node connections from. The deny list
+// takes precedence over the allow list
+func WithInboundDenyList(cidrs []string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.inboundDenyList = cidrs
+	}
+}
+
+// WithIntersectPointCount specifies the number of recent chain points to send when finding an intersection with an
+// upstream peer. Higher values improve the chances of finding a good intersection after a deep rollback or long downtime,
+// at the cost of a larger FindIntersect message. This must be between 1 and 1000, and defaults to 100
+func WithIntersectPointCount(count int) ConfigOptionFunc {
+	return func(c *Config) {
+		c.intersectPointCount = count
+	}
+}
+
 // WithIntersectPoints specifies intersect point(s) for the initial chainsync. The default is to start at chain genesis
 func WithIntersectPoints(points []ocommon.Point) ConfigOptionFunc {
 	return func(c *Config) {
@@ -150,6 +674,18 @@ func WithIntersectTip(intersectTip bool) ConfigOptionFunc {
 	}
 }
 
+// WithComponentLogLevel specifies the minimum log level for records with the specified "component" attribute (such as
+// "network" or "database"). This overrides the level of the configured logger for that component, which allows
+// enabling debug logs for one subsystem or silencing a noisy one
+func WithComponentLogLevel(component string, level slog.Level) ConfigOptionFunc {
+	return func(c *Config) {
+		if c.componentLogLevels == nil {
+			c.componentLogLevels = make(map[string]slog.Level)
+		}
+		c.componentLogLevels[component] = level
+	}
+}
+
 // WithLogger specifies the logger to use. This defaults to discarding log output
 func WithLogger(logger *slog.Logger) ConfigOptionFunc {
 	return func(c *Config) {
@@ -164,6 +700,64 @@ func WithListeners(listeners ...ListenerConfig) ConfigOptionFunc {
 	}
 }
 
+// WithMaxInboundConnections specifies the maximum number of concurrent inbound node-to-node connections. Inbound
+// connections beyond this limit are closed immediately. Node-to-client connections aren't limited. The default is no
+// limit
+func WithMaxInboundConnections(maxConns int) ConfigOptionFunc {
+	return func(c *Config) {
+		c.maxInboundConns = maxConns
+	}
+}
+
+// WithMaxInboundConnectionsPerIP specifies the maximum number of concurrent inbound node-to-node connections from a
+// single remote IP. Loopback addresses and topology local roots are exempt. The default is no limit
+func WithMaxInboundConnectionsPerIP(maxConns int) ConfigOptionFunc {
+	return func(c *Config) {
+		c.maxInboundConnsPerIP = maxConns
+	}
+}
+
+// WithMaxReconnectAttempts specifies the number of failed reconnect attempts after which an outbound peer is
+// abandoned until the topology is reloaded. The default of 0 means unlimited
+func WithMaxReconnectAttempts(attempts int) ConfigOptionFunc {
+	return func(c *Config) {
+		c.maxReconnectAttempts = attempts
+	}
+}
+
+// WithTargetOutboundPeers specifies the number of outbound peers to maintain. Peers discovered via peer sharing are
+// dialed when there are fewer configured peers than this. The default is 20
+func WithTargetOutboundPeers(peers int) ConfigOptionFunc {
+	return func(c *Config) {
+		c.targetOutboundPeers = peers
+	}
+}
+
+// WithMempoolFeePriority specifies whether to announce mempool transactions to peers in order of fee per byte,
+// highest first. The default is to announce transactions in the order they were added
+func WithMempoolFeePriority(prioritize bool) ConfigOptionFunc {
+	return func(c *Config) {
+		c.mempoolFeePriority = prioritize
+	}
+}
+
+// WithMetadataAutoRecover specifies whether to rebuild the metadata database from the chain when it fails to initialize
+// and can't be repaired in place. The existing database is moved aside and the ledger state is replayed from the stored
+// blocks. The default is disabled, which causes startup to fail instead
+func WithMetadataAutoRecover(autoRecover bool) ConfigOptionFunc {
+	return func(c *Config) {
+		c.metadataAutoRecover = autoRecover
+	}
+}
+
+// WithMetadataReadReplica specifies whether to open a separate read-only connection to the metadata database for read
+// queries, which avoids contention with the write path for read-heavy workloads. The default is disabled
+func WithMetadataReadReplica(readReplica bool) ConfigOptionFunc {
+	return func(c *Config) {
+		c.metadataReadReplica = readReplica
+	}
+}
+
 // WithNetwork specifies the named network to operate on. This will automatically set the appropriate network magic value
 func WithNetwork(network string) ConfigOptionFunc {
 	return func(c *Config) {
@@ -178,6 +772,50 @@ func WithNetworkMagic(networkMagic uint32) ConfigOptionFunc {
 	}
 }
 
+// WithNodeToClientSocketPath specifies the path of a UNIX socket to listen on for node-to-client connections. This is the
+// conventional way for local tooling to connect to a node. A stale socket file at the path will be removed
+func WithNodeToClientSocketPath(path string) ConfigOptionFunc {
+	return func(c *Config) {
+		if path == "" {
+			return
+		}
+		c.listeners = append(
+			c.listeners,
+			ListenerConfig{
+				ListenNetwork: "unix",
+				ListenAddress: path,
+				UseNtC:        true,
+			},
+		)
+	}
+}
+
+// WithNodeToClientListenAddress specifies the IP address and port to listen on for node-to-client TCP connections. An
+// empty address listens on all interfaces. A port of 0 disables the listener
+func WithNodeToClientListenAddress(address string, port uint) ConfigOptionFunc {
+	return func(c *Config) {
+		c.ntcListenAddress = address
+		c.ntcListenPort = port
+	}
+}
+
+// WithNodeToNodeListenAddress specifies the IP address and port to listen on for node-to-node TCP connections. An
+// empty address listens on all interfaces. A port of 0 disables the listener
+func WithNodeToNodeListenAddress(address string, port uint) ConfigOptionFunc {
+	return func(c *Config) {
+		c.ntnListenAddress = address
+		c.ntnListenPort = port
+	}
+}
+
+// WithOutboundAddressFamily specifies which address families to use for outbound connections. Valid values are "ipv4",
+// "ipv6", and "dual". When set to "dual", both address families are dialed concurrently and the first to connect is used
+func WithOutboundAddressFamily(family string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.outboundAddressFamily = family
+	}
+}
+
 // WithOutboundSourcePort specifies the source port to use for outbound connections. This defaults to dynamic source ports
 func WithOutboundSourcePort(port uint) ConfigOptionFunc {
 	return func(c *Config) {
@@ -185,6 +823,15 @@ func WithOutboundSourcePort(port uint) ConfigOptionFunc {
 	}
 }
 
+// WithOutboundSourcePortRange specifies an inclusive range of source ports to rotate through for outbound connections.
+// Each new outbound connection uses the next port in the range. This takes precedence over WithOutboundSourcePort
+func WithOutboundSourcePortRange(start, end int) ConfigOptionFunc {
+	return func(c *Config) {
+		c.outboundPortStart = start
+		c.outboundPortEnd = end
+	}
+}
+
 // WithUtxorpcTlsCertFilePath specifies the path to the TLS certificate for the gRPC API listener. This defaults to empty
 func WithUtxorpcTlsCertFilePath(path string) ConfigOptionFunc {
 	return func(c *Config) {
@@ -206,6 +853,38 @@ func WithUtxorpcPort(port uint) ConfigOptionFunc {
 	}
 }
 
+// WithKeepAliveInterval specifies how often to send keep-alive messages to node-to-node peers. This defaults to 60s
+func WithKeepAliveInterval(interval time.Duration) ConfigOptionFunc {
+	return func(c *Config) {
+		c.keepAliveInterval = interval
+	}
+}
+
+// WithKeepAliveTimeout specifies how long to wait for a response to a keep-alive message before the connection is
+// considered dead. A longer timeout avoids dropping healthy connections on high-latency links, but it must be shorter
+// than the keep-alive interval. This defaults to 10s
+func WithKeepAliveTimeout(timeout time.Duration) ConfigOptionFunc {
+	return func(c *Config) {
+		c.keepAliveTimeout = timeout
+	}
+}
+
+// WithPeerIdleTimeout specifies the maximum amount of time to wait for data from an outbound peer before the connection
+// is considered dead and closed. It must be longer than the keep-alive interval. This is disabled by default
+func WithPeerIdleTimeout(timeout time.Duration) ConfigOptionFunc {
+	return func(c *Config) {
+		c.peerIdleTimeout = timeout
+	}
+}
+
+// WithBandwidthLimit specifies the maximum number of bytes per second to receive and to send, shared across all
+// node-to-node connections. This is disabled by default
+func WithBandwidthLimit(bytesPerSec uint64) ConfigOptionFunc {
+	return func(c *Config) {
+		c.bandwidthLimit = bytesPerSec
+	}
+}
+
 // WithPeerSharing specifies whether to enable peer sharing. This is disabled by default
 func WithPeerSharing(peerSharing bool) ConfigOptionFunc {
 	return func(c *Config) {
@@ -213,14 +892,72 @@ func WithPeerSharing(peerSharing bool) ConfigOptionFunc {
 	}
 }
 
+// WithPeerSharingRequestInterval specifies how often to request peers from connected peers that are willing to share
+// them when peer sharing is enabled. This defaults to 5m
+func WithPeerSharingRequestInterval(interval time.Duration) ConfigOptionFunc {
+	return func(c *Config) {
+		c.peerSharingInterval = interval
+	}
+}
+
+// WithPeerSharingRequestAmount specifies the number of peers to request from each peer when peer sharing is enabled.
+// This defaults to 10
+func WithPeerSharingRequestAmount(amount uint8) ConfigOptionFunc {
+	return func(c *Config) {
+		c.peerSharingAmount = amount
+	}
+}
+
 // WithPrometheusRegistry specifies a prometheus.Registerer instance to add metrics to. In most cases, prometheus.DefaultRegistry would be
-// a good choice to get metrics working
+// a good choice to get metrics working. This registry is used by the node and all of its subsystems, so a sub-registry
+// created with prometheus.WrapRegistererWith can be used to route dingo metrics separately from those of a host application
 func WithPrometheusRegistry(registry prometheus.Registerer) ConfigOptionFunc {
 	return func(c *Config) {
 		c.promRegistry = registry
 	}
 }
 
+// WithMetricsListenAddress specifies the address (host:port) to serve the prometheus registry on at /metrics. This
+// requires a registry that also implements prometheus.Gatherer, such as one created with prometheus.NewRegistry. The
+// metrics listener is disabled by default, for use cases where the registry is already served elsewhere
+func WithMetricsListenAddress(address string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.metricsListenAddress = address
+	}
+}
+
+// WithMetricsNamespace specifies a namespace to prefix all metric names with, separated by an underscore. This can be used
+// to avoid collisions with the metrics of a host application sharing the same registry. There is no prefix by default
+func WithMetricsNamespace(namespace string) ConfigOptionFunc {
+	return func(c *Config) {
+		c.metricsNamespace = namespace
+	}
+}
+
+// WithProxyProtocol specifies whether node-to-node listeners expect a PROXY protocol v1/v2 header on each connection,
+// which allows recovering the real client address when running behind a TCP load balancer. This must only be enabled
+// when all connections come through a proxy, since direct connections will be rejected. The default is disabled
+func WithProxyProtocol(proxyProtocol bool) ConfigOptionFunc {
+	return func(c *Config) {
+		c.proxyProtocol = proxyProtocol
+	}
+}
+
+// WithRewardCalculator specifies a RewardCalculator to call at each epoch boundary
+func WithRewardCalculator(calculator RewardCalculator) ConfigOptionFunc {
+	return func(c *Config) {
+		c.rewardCalculator = calculator
+	}
+}
+
+// WithScriptEvaluator specifies a function to use for evaluating Plutus scripts in EvaluateTransaction. There is no
+// built-in evaluator, so EvaluateTransaction returns ErrNoScriptEvaluator unless this is set
+func WithScriptEvaluator(evaluator ScriptEvaluatorFunc) ConfigOptionFunc {
+	return func(c *Config) {
+		c.scriptEvaluator = evaluator
+	}
+}
+
 // WithTopologyConfig specifies a topology.TopologyConfig to use for outbound peers
 func WithTopologyConfig(
 	topologyConfig *topology.TopologyConfig,
@@ -245,6 +982,30 @@ func WithTracingStdout(stdout bool) ConfigOptionFunc {
 	}
 }
 
+// WithVerifyCborRoundtrip specifies whether to re-encode each block before applying it and compare the result to the
+// original CBOR, logging a warning on mismatch. This is expensive and disabled by default
+func WithVerifyCborRoundtrip(verify bool) ConfigOptionFunc {
+	return func(c *Config) {
+		c.verifyCborRoundtrip = verify
+	}
+}
+
+// WithLedgerBatchSize specifies the max number of blocks to apply in a single DB transaction during bulk sync. Larger
+// batches reduce commit overhead when far from tip. Blocks near the tip are always committed individually. This defaults to 50
+func WithLedgerBatchSize(batchSize int) ConfigOptionFunc {
+	return func(c *Config) {
+		c.ledgerBatchSize = batchSize
+	}
+}
+
+// WithLedgerFlushInterval specifies the max time to spend applying blocks in a single DB transaction before committing
+// them, regardless of the batch size. This defaults to 5s
+func WithLedgerFlushInterval(interval time.Duration) ConfigOptionFunc {
+	return func(c *Config) {
+		c.ledgerFlushInterval = interval
+	}
+}
+
 // WithBadgerCacheSize sets the maximum cache size (in bytes).This controls memory usage by limiting the size of block and index caches.
 // If not set, the default size defined in internal config will be used.
 func WithBadgerCacheSize(cacheSize int64) ConfigOptionFunc {
@@ -0,0 +1,134 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardano
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// Standard file names used for a custom network config directory
+const (
+	CustomNetworkConfigFile         = "config.json"
+	CustomNetworkByronGenesisFile   = "byron-genesis.json"
+	CustomNetworkShelleyGenesisFile = "shelley-genesis.json"
+	CustomNetworkAlonzoGenesisFile  = "alonzo-genesis.json"
+	CustomNetworkConwayGenesisFile  = "conway-genesis.json"
+)
+
+// NewCustomNetworkConfig builds a cardano-node config for a custom network from a directory of genesis files. If the
+// directory contains a config.json, it's used for the genesis file names and hashes, which must match the file
+// contents. Otherwise the genesis files are expected under their standard names, and the genesis hashes are calculated
+// from them. The genesis configs for all eras must be present, and the network magic must match the Byron and Shelley
+// genesis configs
+func NewCustomNetworkConfig(
+	genesisDir string,
+	networkMagic uint32,
+) (*CardanoNodeConfig, error) {
+	var cfg *CardanoNodeConfig
+	configPath := path.Join(genesisDir, CustomNetworkConfigFile)
+	if _, err := os.Stat(configPath); err == nil {
+		cfg, err = NewCardanoNodeConfigFromFile(configPath)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, err
+		}
+		cfg = &CardanoNodeConfig{
+			path:               genesisDir,
+			ByronGenesisFile:   CustomNetworkByronGenesisFile,
+			ShelleyGenesisFile: CustomNetworkShelleyGenesisFile,
+			AlonzoGenesisFile:  CustomNetworkAlonzoGenesisFile,
+			ConwayGenesisFile:  CustomNetworkConwayGenesisFile,
+		}
+		if err := cfg.populateGenesisHashes(); err != nil {
+			return nil, err
+		}
+		if err := cfg.loadGenesisConfigs(); err != nil {
+			return nil, err
+		}
+	}
+	if err := cfg.ValidateGenesis(); err != nil {
+		return nil, err
+	}
+	// Check that the network magic lines up with the genesis configs
+	var errs []error
+	// #nosec G115
+	if byronMagic := cfg.byronGenesis.ProtocolConsts.ProtocolMagic; uint32(byronMagic) != networkMagic {
+		errs = append(
+			errs,
+			fmt.Errorf(
+				"network magic (%d) doesn't match protocol magic from Byron genesis (%d)",
+				networkMagic,
+				byronMagic,
+			),
+		)
+	}
+	if shelleyMagic := cfg.shelleyGenesis.NetworkMagic; shelleyMagic != networkMagic {
+		errs = append(
+			errs,
+			fmt.Errorf(
+				"network magic (%d) doesn't match value from Shelley genesis (%d)",
+				networkMagic,
+				shelleyMagic,
+			),
+		)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// populateGenesisHashes calculates the genesis hashes from the configured genesis files
+func (c *CardanoNodeConfig) populateGenesisHashes() error {
+	genesisFiles := []struct {
+		era  string
+		file string
+		hash *string
+	}{
+		{"Byron", c.ByronGenesisFile, &c.ByronGenesisHash},
+		{"Shelley", c.ShelleyGenesisFile, &c.ShelleyGenesisHash},
+		{"Alonzo", c.AlonzoGenesisFile, &c.AlonzoGenesisHash},
+		{"Conway", c.ConwayGenesisFile, &c.ConwayGenesisHash},
+	}
+	var errs []error
+	for _, genesisFile := range genesisFiles {
+		genesisPath := c.genesisPath(genesisFile.file)
+		data, err := os.ReadFile(genesisPath)
+		if err != nil {
+			errs = append(
+				errs,
+				GenesisError{Era: genesisFile.era, Path: genesisPath, Err: err},
+			)
+			continue
+		}
+		hash, err := genesisHash(genesisFile.era, data)
+		if err != nil {
+			errs = append(
+				errs,
+				GenesisError{Era: genesisFile.era, Path: genesisPath, Err: err},
+			)
+			continue
+		}
+		*genesisFile.hash = hash
+	}
+	return errors.Join(errs...)
+}
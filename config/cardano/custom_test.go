@@ -0,0 +1,93 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardano
+
+import (
+	"os"
+	"path"
+	"strings"
+	"testing"
+)
+
+// The test data is from the preview network
+const testNetworkMagic = 2
+
+func copyTestGenesisFiles(t *testing.T, dir string) {
+	t.Helper()
+	for _, genesisFile := range []string{
+		CustomNetworkByronGenesisFile,
+		CustomNetworkShelleyGenesisFile,
+		CustomNetworkAlonzoGenesisFile,
+		CustomNetworkConwayGenesisFile,
+	} {
+		genesisData, err := os.ReadFile(path.Join(testDataDir, genesisFile))
+		if err != nil {
+			t.Fatalf("unexpected error reading test genesis: %s", err)
+		}
+		if err := os.WriteFile(path.Join(dir, genesisFile), genesisData, 0o600); err != nil {
+			t.Fatalf("unexpected error writing test genesis: %s", err)
+		}
+	}
+}
+
+func TestNewCustomNetworkConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	copyTestGenesisFiles(t, tmpDir)
+	cfg, err := NewCustomNetworkConfig(tmpDir, testNetworkMagic)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// The calculated genesis hashes should match those from the test config
+	if cfg.ByronGenesisHash != expectedCardanoNodeConfig.ByronGenesisHash ||
+		cfg.ShelleyGenesisHash != expectedCardanoNodeConfig.ShelleyGenesisHash ||
+		cfg.AlonzoGenesisHash != expectedCardanoNodeConfig.AlonzoGenesisHash ||
+		cfg.ConwayGenesisHash != expectedCardanoNodeConfig.ConwayGenesisHash {
+		t.Fatalf("did not get expected genesis hashes: %#v", cfg)
+	}
+	// The existing config is used when present
+	cfg, err = NewCustomNetworkConfig(testDataDir, testNetworkMagic)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cfg.ShelleyGenesis() == nil {
+		t.Fatalf("got nil instead of ShelleyGenesis")
+	}
+}
+
+func TestNewCustomNetworkConfigMagicMismatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	copyTestGenesisFiles(t, tmpDir)
+	_, err := NewCustomNetworkConfig(tmpDir, 42)
+	if err == nil {
+		t.Fatalf("did not get expected error")
+	}
+	for _, expected := range []string{"Byron genesis (2)", "Shelley genesis (2)"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("error does not contain %q: %s", expected, err)
+		}
+	}
+}
+
+func TestNewCustomNetworkConfigMissingGenesis(t *testing.T) {
+	tmpDir := t.TempDir()
+	copyTestGenesisFiles(t, tmpDir)
+	if err := os.Remove(path.Join(tmpDir, CustomNetworkConwayGenesisFile)); err != nil {
+		t.Fatalf("unexpected error removing genesis file: %s", err)
+	}
+	_, err := NewCustomNetworkConfig(tmpDir, testNetworkMagic)
+	if err == nil || !strings.Contains(err.Error(), "Conway genesis") {
+		t.Fatalf("did not get expected error: %v", err)
+	}
+}
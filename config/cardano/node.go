@@ -15,18 +15,49 @@
 package cardano
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path"
 	"path/filepath"
+	"strings"
 
 	"github.com/blinklabs-io/gouroboros/ledger/alonzo"
 	"github.com/blinklabs-io/gouroboros/ledger/byron"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
 	"github.com/blinklabs-io/gouroboros/ledger/conway"
 	"github.com/blinklabs-io/gouroboros/ledger/shelley"
 	"gopkg.in/yaml.v3"
 )
 
+// ErrGenesisHashMismatch is used in a GenesisError when a genesis file doesn't match the configured genesis hash
+var ErrGenesisHashMismatch = errors.New("genesis hash mismatch")
+
+// ErrGenesisMissing is used in a GenesisError when the genesis config for an era has not been loaded
+var ErrGenesisMissing = errors.New("genesis config not loaded")
+
+// GenesisError describes a problem loading the genesis config for an era. The path is empty if no genesis file was
+// specified for the era
+type GenesisError struct {
+	Era  string
+	Path string
+	Err  error
+}
+
+func (e GenesisError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("%s genesis: %s", e.Era, e.Err)
+	}
+	return fmt.Sprintf("%s genesis (%s): %s", e.Era, e.Path, e.Err)
+}
+
+func (e GenesisError) Unwrap() error {
+	return e.Err
+}
+
 // CardanoNodeConfig represents the config.json/yaml file used by cardano-node
 type CardanoNodeConfig struct {
 	path               string
@@ -70,61 +101,141 @@ func NewCardanoNodeConfigFromFile(file string) (*CardanoNodeConfig, error) {
 }
 
 func (c *CardanoNodeConfig) loadGenesisConfigs() error {
-	// Load Byron genesis
+	// We try to load all genesis configs and report every problem at once
+	var errs []error
 	if c.ByronGenesisFile != "" {
-		byronGenesisPath := c.ByronGenesisFile
-		if !filepath.IsAbs(byronGenesisPath) {
-			byronGenesisPath = path.Join(c.path, byronGenesisPath)
-		}
-		// TODO: check genesis file hash (#399)
-		byronGenesis, err := byron.NewByronGenesisFromFile(byronGenesisPath)
-		if err != nil {
-			return err
+		if err := c.loadByronGenesisFile(c.genesisPath(c.ByronGenesisFile)); err != nil {
+			errs = append(errs, err)
 		}
-		c.byronGenesis = &byronGenesis
 	}
-	// Load Shelley genesis
 	if c.ShelleyGenesisFile != "" {
-		shelleyGenesisPath := c.ShelleyGenesisFile
-		if !filepath.IsAbs(shelleyGenesisPath) {
-			shelleyGenesisPath = path.Join(c.path, shelleyGenesisPath)
-		}
-		// TODO: check genesis file hash (#399)
-		shelleyGenesis, err := shelley.NewShelleyGenesisFromFile(
-			shelleyGenesisPath,
-		)
-		if err != nil {
-			return err
+		if err := c.loadShelleyGenesisFile(c.genesisPath(c.ShelleyGenesisFile)); err != nil {
+			errs = append(errs, err)
 		}
-		c.shelleyGenesis = &shelleyGenesis
 	}
-	// Load Alonzo genesis
 	if c.AlonzoGenesisFile != "" {
-		alonzoGenesisPath := c.AlonzoGenesisFile
-		if !filepath.IsAbs(alonzoGenesisPath) {
-			alonzoGenesisPath = path.Join(c.path, alonzoGenesisPath)
-		}
-		// TODO: check genesis file hash (#399)
-		alonzoGenesis, err := alonzo.NewAlonzoGenesisFromFile(alonzoGenesisPath)
-		if err != nil {
-			return err
+		if err := c.loadAlonzoGenesisFile(c.genesisPath(c.AlonzoGenesisFile)); err != nil {
+			errs = append(errs, err)
 		}
-		c.alonzoGenesis = &alonzoGenesis
 	}
-	// Load Conway genesis
 	if c.ConwayGenesisFile != "" {
-		conwayGenesisPath := c.ConwayGenesisFile
-		if !filepath.IsAbs(conwayGenesisPath) {
-			conwayGenesisPath = path.Join(c.path, conwayGenesisPath)
+		if err := c.loadConwayGenesisFile(c.genesisPath(c.ConwayGenesisFile)); err != nil {
+			errs = append(errs, err)
 		}
-		// TODO: check genesis file hash (#399)
-		conwayGenesis, err := conway.NewConwayGenesisFromFile(conwayGenesisPath)
+	}
+	return errors.Join(errs...)
+}
+
+// readGenesisFile reads a genesis file and checks its hash against the expected hash, if any
+func readGenesisFile(
+	era string,
+	genesisFile string,
+	expectedHash string,
+) ([]byte, error) {
+	data, err := os.ReadFile(genesisFile)
+	if err != nil {
+		return nil, GenesisError{Era: era, Path: genesisFile, Err: err}
+	}
+	if expectedHash == "" {
+		return data, nil
+	}
+	hash, err := genesisHash(era, data)
+	if err != nil {
+		return nil, GenesisError{Era: era, Path: genesisFile, Err: err}
+	}
+	if !strings.EqualFold(hash, expectedHash) {
+		return nil, GenesisError{
+			Era:  era,
+			Path: genesisFile,
+			Err: fmt.Errorf(
+				"%w: expected %s, got %s",
+				ErrGenesisHashMismatch,
+				expectedHash,
+				hash,
+			),
+		}
+	}
+	return data, nil
+}
+
+// genesisHash returns the hex-encoded hash of a genesis file for the specified era. The Byron genesis hash is calculated
+// over the canonical JSON encoding of the file, and the others over the raw file
+func genesisHash(era string, data []byte) (string, error) {
+	if era == "Byron" {
+		canonicalData, err := canonicalJson(data)
 		if err != nil {
-			return err
+			return "", err
 		}
-		c.conwayGenesis = &conwayGenesis
+		data = canonicalData
 	}
-	return nil
+	return lcommon.Blake2b256Hash(data).String(), nil
+}
+
+// canonicalJson re-encodes JSON data with sorted object keys and no whitespace
+func canonicalJson(data []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Keep numbers as they appear in the source
+	dec.UseNumber()
+	var tmpData any
+	if err := dec.Decode(&tmpData); err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	// The encoder sorts map keys for us
+	if err := enc.Encode(tmpData); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// absGenesisFile returns the absolute path for a genesis file specified relative to the current directory, so that it
+// isn't later resolved relative to the cardano-node config file
+func absGenesisFile(genesisFile string) string {
+	if absFile, err := filepath.Abs(genesisFile); err == nil {
+		return absFile
+	}
+	return genesisFile
+}
+
+// genesisPath returns the path for a genesis file, which is relative to the cardano-node config file unless absolute
+func (c *CardanoNodeConfig) genesisPath(genesisFile string) string {
+	if genesisFile == "" || filepath.IsAbs(genesisFile) {
+		return genesisFile
+	}
+	return path.Join(c.path, genesisFile)
+}
+
+// ValidateGenesis checks that the genesis configs for all eras have been loaded, since they are all needed to apply
+// blocks. A GenesisError is generated for each missing era, and they are combined into a single error
+func (c *CardanoNodeConfig) ValidateGenesis() error {
+	var errs []error
+	if c.byronGenesis == nil {
+		errs = append(
+			errs,
+			GenesisError{Era: "Byron", Path: c.genesisPath(c.ByronGenesisFile), Err: ErrGenesisMissing},
+		)
+	}
+	if c.shelleyGenesis == nil {
+		errs = append(
+			errs,
+			GenesisError{Era: "Shelley", Path: c.genesisPath(c.ShelleyGenesisFile), Err: ErrGenesisMissing},
+		)
+	}
+	if c.alonzoGenesis == nil {
+		errs = append(
+			errs,
+			GenesisError{Era: "Alonzo", Path: c.genesisPath(c.AlonzoGenesisFile), Err: ErrGenesisMissing},
+		)
+	}
+	if c.conwayGenesis == nil {
+		errs = append(
+			errs,
+			GenesisError{Era: "Conway", Path: c.genesisPath(c.ConwayGenesisFile), Err: ErrGenesisMissing},
+		)
+	}
+	return errors.Join(errs...)
 }
 
 // ByronGenesis returns the Byron genesis config specified in the cardano-node config
@@ -143,6 +254,28 @@ func (c *CardanoNodeConfig) LoadByronGenesisFromReader(r io.Reader) error {
 	return nil
 }
 
+// LoadByronGenesisFromFile loads a Byron genesis config from the specified file, which replaces any Byron genesis
+// file from the cardano-node config. The file hash is checked against the configured Byron genesis hash, if any. A
+// GenesisError is returned on failure
+func (c *CardanoNodeConfig) LoadByronGenesisFromFile(genesisFile string) error {
+	if err := c.loadByronGenesisFile(genesisFile); err != nil {
+		return err
+	}
+	c.ByronGenesisFile = absGenesisFile(genesisFile)
+	return nil
+}
+
+func (c *CardanoNodeConfig) loadByronGenesisFile(genesisFile string) error {
+	data, err := readGenesisFile("Byron", genesisFile, c.ByronGenesisHash)
+	if err != nil {
+		return err
+	}
+	if err := c.LoadByronGenesisFromReader(bytes.NewReader(data)); err != nil {
+		return GenesisError{Era: "Byron", Path: genesisFile, Err: err}
+	}
+	return nil
+}
+
 // ShelleyGenesis returns the Shelley genesis config specified in the cardano-node config
 func (c *CardanoNodeConfig) ShelleyGenesis() *shelley.ShelleyGenesis {
 	return c.shelleyGenesis
@@ -159,6 +292,28 @@ func (c *CardanoNodeConfig) LoadShelleyGenesisFromReader(r io.Reader) error {
 	return nil
 }
 
+// LoadShelleyGenesisFromFile loads a Shelley genesis config from the specified file, which replaces any Shelley genesis
+// file from the cardano-node config. The file hash is checked against the configured Shelley genesis hash, if any. A
+// GenesisError is returned on failure
+func (c *CardanoNodeConfig) LoadShelleyGenesisFromFile(genesisFile string) error {
+	if err := c.loadShelleyGenesisFile(genesisFile); err != nil {
+		return err
+	}
+	c.ShelleyGenesisFile = absGenesisFile(genesisFile)
+	return nil
+}
+
+func (c *CardanoNodeConfig) loadShelleyGenesisFile(genesisFile string) error {
+	data, err := readGenesisFile("Shelley", genesisFile, c.ShelleyGenesisHash)
+	if err != nil {
+		return err
+	}
+	if err := c.LoadShelleyGenesisFromReader(bytes.NewReader(data)); err != nil {
+		return GenesisError{Era: "Shelley", Path: genesisFile, Err: err}
+	}
+	return nil
+}
+
 // AlonzoGenesis returns the Alonzo genesis config specified in the cardano-node config
 func (c *CardanoNodeConfig) AlonzoGenesis() *alonzo.AlonzoGenesis {
 	return c.alonzoGenesis
@@ -175,6 +330,28 @@ func (c *CardanoNodeConfig) LoadAlonzoGenesisFromReader(r io.Reader) error {
 	return nil
 }
 
+// LoadAlonzoGenesisFromFile loads a Alonzo genesis config from the specified file, which replaces any Alonzo genesis
+// file from the cardano-node config. The file hash is checked against the configured Alonzo genesis hash, if any. A
+// GenesisError is returned on failure
+func (c *CardanoNodeConfig) LoadAlonzoGenesisFromFile(genesisFile string) error {
+	if err := c.loadAlonzoGenesisFile(genesisFile); err != nil {
+		return err
+	}
+	c.AlonzoGenesisFile = absGenesisFile(genesisFile)
+	return nil
+}
+
+func (c *CardanoNodeConfig) loadAlonzoGenesisFile(genesisFile string) error {
+	data, err := readGenesisFile("Alonzo", genesisFile, c.AlonzoGenesisHash)
+	if err != nil {
+		return err
+	}
+	if err := c.LoadAlonzoGenesisFromReader(bytes.NewReader(data)); err != nil {
+		return GenesisError{Era: "Alonzo", Path: genesisFile, Err: err}
+	}
+	return nil
+}
+
 // ConwayGenesis returns the Conway genesis config specified in the cardano-node config
 func (c *CardanoNodeConfig) ConwayGenesis() *conway.ConwayGenesis {
 	return c.conwayGenesis
@@ -190,3 +367,37 @@ func (c *CardanoNodeConfig) LoadConwayGenesisFromReader(r io.Reader) error {
 	c.conwayGenesis = &conwayGenesis
 	return nil
 }
+
+// LoadConwayGenesisFromFile loads a Conway genesis config from the specified file, which replaces any Conway genesis
+// file from the cardano-node config. The file hash is checked against the configured Conway genesis hash, if any. A
+// GenesisError is returned on failure
+func (c *CardanoNodeConfig) LoadConwayGenesisFromFile(genesisFile string) error {
+	if err := c.loadConwayGenesisFile(genesisFile); err != nil {
+		return err
+	}
+	c.ConwayGenesisFile = absGenesisFile(genesisFile)
+	return nil
+}
+
+func (c *CardanoNodeConfig) loadConwayGenesisFile(genesisFile string) error {
+	data, err := readGenesisFile("Conway", genesisFile, c.ConwayGenesisHash)
+	if err != nil {
+		return err
+	}
+	if err := c.LoadConwayGenesisFromReader(bytes.NewReader(data)); err != nil {
+		return GenesisError{Era: "Conway", Path: genesisFile, Err: err}
+	}
+	return nil
+}
+
+// SecurityParam returns the security parameter (k) from the Byron genesis config, falling back to the Shelley genesis
+// config. This is the max number of blocks that can be rolled back. It returns 0 if neither genesis config is loaded
+func (c *CardanoNodeConfig) SecurityParam() uint {
+	if c.byronGenesis != nil && c.byronGenesis.ProtocolConsts.K > 0 {
+		return uint(c.byronGenesis.ProtocolConsts.K) // #nosec G115
+	}
+	if c.shelleyGenesis != nil && c.shelleyGenesis.SecurityParam > 0 {
+		return uint(c.shelleyGenesis.SecurityParam) // #nosec G115
+	}
+	return 0
+}
@@ -15,8 +15,12 @@
 package cardano
 
 import (
+	"errors"
+	"os"
 	"path"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -83,3 +87,169 @@ func TestCardanoNodeConfig(t *testing.T) {
 		}
 	})
 }
+
+func TestCardanoNodeConfigSecurityParam(t *testing.T) {
+	testDefs := []struct {
+		name           string
+		byronGenesis   string
+		shelleyGenesis string
+		expected       uint
+	}{
+		{
+			name:           "mainnet",
+			byronGenesis:   `{"protocolConsts": {"k": 2160, "protocolMagic": 764824073}}`,
+			shelleyGenesis: `{"networkMagic": 764824073, "securityParam": 2160}`,
+			expected:       2160,
+		},
+		{
+			name:           "preprod",
+			byronGenesis:   `{"protocolConsts": {"k": 2160, "protocolMagic": 1}}`,
+			shelleyGenesis: `{"networkMagic": 1, "securityParam": 2160}`,
+			expected:       2160,
+		},
+		{
+			name:           "Shelley only",
+			shelleyGenesis: `{"networkMagic": 1, "securityParam": 2160}`,
+			expected:       2160,
+		},
+		{
+			name:     "no genesis",
+			expected: 0,
+		},
+	}
+	for _, testDef := range testDefs {
+		t.Run(testDef.name, func(t *testing.T) {
+			cfg := &CardanoNodeConfig{}
+			if testDef.byronGenesis != "" {
+				if err := cfg.LoadByronGenesisFromReader(strings.NewReader(testDef.byronGenesis)); err != nil {
+					t.Fatalf("unexpected error loading Byron genesis: %s", err)
+				}
+			}
+			if testDef.shelleyGenesis != "" {
+				if err := cfg.LoadShelleyGenesisFromReader(strings.NewReader(testDef.shelleyGenesis)); err != nil {
+					t.Fatalf("unexpected error loading Shelley genesis: %s", err)
+				}
+			}
+			if securityParam := cfg.SecurityParam(); securityParam != testDef.expected {
+				t.Fatalf("did not get expected security param: got %d, wanted %d", securityParam, testDef.expected)
+			}
+		})
+	}
+	// The test data is from the preview network
+	cfg, err := NewCardanoNodeConfigFromFile(path.Join(testDataDir, "config.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if securityParam := cfg.SecurityParam(); securityParam != 432 {
+		t.Fatalf("did not get expected security param for preview: got %d, wanted %d", securityParam, 432)
+	}
+}
+
+func TestCardanoNodeConfigGenesisErrors(t *testing.T) {
+	tmpDir := t.TempDir()
+	// Use valid Byron and Shelley genesis files, a malformed Alonzo genesis file, and a missing Conway genesis file
+	for _, genesisFile := range []string{"byron-genesis.json", "shelley-genesis.json"} {
+		genesisData, err := os.ReadFile(path.Join(testDataDir, genesisFile))
+		if err != nil {
+			t.Fatalf("unexpected error reading test genesis: %s", err)
+		}
+		if err := os.WriteFile(path.Join(tmpDir, genesisFile), genesisData, 0o600); err != nil {
+			t.Fatalf("unexpected error writing test genesis: %s", err)
+		}
+	}
+	if err := os.WriteFile(path.Join(tmpDir, "alonzo-genesis.json"), []byte("{"), 0o600); err != nil {
+		t.Fatalf("unexpected error writing test genesis: %s", err)
+	}
+	configData := `
+ByronGenesisFile: byron-genesis.json
+ShelleyGenesisFile: shelley-genesis.json
+AlonzoGenesisFile: alonzo-genesis.json
+ConwayGenesisFile: conway-genesis.json
+`
+	configPath := path.Join(tmpDir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte(configData), 0o600); err != nil {
+		t.Fatalf("unexpected error writing test config: %s", err)
+	}
+	_, err := NewCardanoNodeConfigFromFile(configPath)
+	if err == nil {
+		t.Fatalf("did not get expected error")
+	}
+	// Both problems should be reported
+	for _, expected := range []string{
+		"Alonzo genesis (" + path.Join(tmpDir, "alonzo-genesis.json") + ")",
+		"Conway genesis (" + path.Join(tmpDir, "conway-genesis.json") + ")",
+	} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Fatalf("error does not contain %q: %s", expected, err)
+		}
+	}
+	for _, unexpected := range []string{"Byron genesis", "Shelley genesis"} {
+		if strings.Contains(err.Error(), unexpected) {
+			t.Fatalf("error unexpectedly contains %q: %s", unexpected, err)
+		}
+	}
+	var genesisErr GenesisError
+	if !errors.As(err, &genesisErr) {
+		t.Fatalf("error is not a GenesisError: %s", err)
+	}
+}
+
+func TestCardanoNodeConfigValidateGenesis(t *testing.T) {
+	cfg := &CardanoNodeConfig{
+		ShelleyGenesisFile: "shelley-genesis.json",
+	}
+	err := cfg.ValidateGenesis()
+	if !errors.Is(err, ErrGenesisMissing) {
+		t.Fatalf("did not get expected error: got %v, wanted %s", err, ErrGenesisMissing)
+	}
+	for _, era := range []string{"Byron", "Shelley", "Alonzo", "Conway"} {
+		if !strings.Contains(err.Error(), era+" genesis") {
+			t.Fatalf("error does not mention %s genesis: %s", era, err)
+		}
+	}
+	// A fully loaded config passes validation
+	cfg, err = NewCardanoNodeConfigFromFile(path.Join(testDataDir, "config.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := cfg.ValidateGenesis(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestCardanoNodeConfigLoadGenesisFromFile(t *testing.T) {
+	cfg, err := NewCardanoNodeConfigFromFile(path.Join(testDataDir, "config.json"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Override with the same files, which match the configured hashes
+	if err := cfg.LoadByronGenesisFromFile(path.Join(testDataDir, "byron-genesis.json")); err != nil {
+		t.Fatalf("unexpected error loading Byron genesis: %s", err)
+	}
+	if err := cfg.LoadShelleyGenesisFromFile(path.Join(testDataDir, "shelley-genesis.json")); err != nil {
+		t.Fatalf("unexpected error loading Shelley genesis: %s", err)
+	}
+	if !filepath.IsAbs(cfg.ShelleyGenesisFile) {
+		t.Fatalf("expected absolute genesis file path, got: %s", cfg.ShelleyGenesisFile)
+	}
+	// Loading a file that doesn't match the configured hash fails
+	err = cfg.LoadAlonzoGenesisFromFile(path.Join(testDataDir, "conway-genesis.json"))
+	if !errors.Is(err, ErrGenesisHashMismatch) {
+		t.Fatalf("did not get expected error: got %v, wanted %s", err, ErrGenesisHashMismatch)
+	}
+	// The hash isn't checked if none is configured
+	cfg.AlonzoGenesisHash = ""
+	if err := cfg.LoadAlonzoGenesisFromFile(path.Join(testDataDir, "alonzo-genesis.json")); err != nil {
+		t.Fatalf("unexpected error loading Alonzo genesis: %s", err)
+	}
+	// Missing files are reported by era and path
+	missingFile := path.Join(testDataDir, "missing-genesis.json")
+	err = cfg.LoadConwayGenesisFromFile(missingFile)
+	var genesisErr GenesisError
+	if !errors.As(err, &genesisErr) {
+		t.Fatalf("did not get expected GenesisError: %v", err)
+	}
+	if genesisErr.Era != "Conway" || genesisErr.Path != missingFile {
+		t.Fatalf("did not get expected era/path: got %s/%s", genesisErr.Era, genesisErr.Path)
+	}
+}
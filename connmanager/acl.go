@@ -0,0 +1,101 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// inboundACL holds parsed CIDR allow and deny lists for inbound connections
+type inboundACL struct {
+	allow []*net.IPNet
+	deny  []*net.IPNet
+}
+
+// check returns an error if the specified remote address is not permitted by the access lists
+func (a *inboundACL) check(remoteAddr net.Addr) error {
+	if a == nil {
+		return nil
+	}
+	tcpAddr, ok := remoteAddr.(*net.TCPAddr)
+	if !ok {
+		return nil
+	}
+	for _, ipNet := range a.deny {
+		if ipNet.Contains(tcpAddr.IP) {
+			return ErrBlockedByDenylist
+		}
+	}
+	if len(a.allow) == 0 {
+		return nil
+	}
+	for _, ipNet := range a.allow {
+		if ipNet.Contains(tcpAddr.IP) {
+			return nil
+		}
+	}
+	return ErrBlockedByAllowlist
+}
+
+// ParseCIDRList parses a list of CIDR ranges. Bare IP addresses are treated as a single-host range
+func ParseCIDRList(cidrs []string) ([]*net.IPNet, error) {
+	ret := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip := net.ParseIP(cidr)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid IP address: %s", cidr)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				bits = 32
+			}
+			ret = append(
+				ret,
+				&net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)},
+			)
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR range: %s", cidr)
+		}
+		ret = append(ret, ipNet)
+	}
+	return ret, nil
+}
+
+// SetInboundAccessLists replaces the CIDR allow and deny lists used for inbound node-to-node connections.
+// When the allow list is non-empty, only connections from matching addresses are accepted. The deny list
+// takes precedence over the allow list
+func (c *ConnectionManager) SetInboundAccessLists(allow, deny []string) error {
+	allowNets, err := ParseCIDRList(allow)
+	if err != nil {
+		return fmt.Errorf("invalid allow list: %w", err)
+	}
+	denyNets, err := ParseCIDRList(deny)
+	if err != nil {
+		return fmt.Errorf("invalid deny list: %w", err)
+	}
+	c.inboundACL.Store(
+		&inboundACL{
+			allow: allowNets,
+			deny:  denyNets,
+		},
+	)
+	return nil
+}
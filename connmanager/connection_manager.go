@@ -15,30 +15,82 @@
 package connmanager
 
 import (
+	"errors"
 	"io"
 	"log/slog"
+	"net"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/blinklabs-io/dingo/event"
 	ouroboros "github.com/blinklabs-io/gouroboros"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // ConnectionManagerConnClosedFunc is a function that takes a connection ID and an optional error
 type ConnectionManagerConnClosedFunc func(ouroboros.ConnectionId, error)
 
 type ConnectionManager struct {
-	config           ConnectionManagerConfig
-	connections      map[ouroboros.ConnectionId]*ouroboros.Connection
-	connectionsMutex sync.Mutex
+	config             ConnectionManagerConfig
+	connections        map[ouroboros.ConnectionId]*ouroboros.Connection
+	inboundConnections map[ouroboros.ConnectionId]struct{}
+	// Inbound node-to-client connections, which aren't subject to the inbound connection limits
+	inboundNtcConnections map[ouroboros.ConnectionId]struct{}
+	inboundConnsByIP      map[string]int
+	connsByRemoteAddr     map[string][]ouroboros.ConnectionId
+	closeReasons          map[ouroboros.ConnectionId]error
+	connectionsMutex      sync.Mutex
+	metrics               *connectionManagerMetrics
+	sourcePortIdx         atomic.Uint64
+	maxInboundConns       atomic.Int64
+	inboundACL            atomic.Pointer[inboundACL]
+	listeners             []net.Listener
+	listenersMutex        sync.Mutex
+	readLimiter           *bandwidthLimiter
+	writeLimiter          *bandwidthLimiter
 }
 
 type ConnectionManagerConfig struct {
 	Logger             *slog.Logger
 	EventBus           *event.EventBus
 	ConnClosedFunc     ConnectionManagerConnClosedFunc
+	ConnEventSink      ConnEventSinkFunc
 	Listeners          []ListenerConfig
 	OutboundConnOpts   []ouroboros.ConnectionOptionFunc
 	OutboundSourcePort uint
+	// OutboundSourcePortRangeStart and OutboundSourcePortRangeEnd specify an inclusive range of source ports
+	// to rotate through for outbound connections. When set, these take precedence over OutboundSourcePort
+	OutboundSourcePortRangeStart uint
+	OutboundSourcePortRangeEnd   uint
+	// OutboundAddressFamily controls which address families are used for outbound connections.
+	// Valid values are "ipv4", "ipv6", and "dual". The default is to use the Go default behavior
+	OutboundAddressFamily string
+	// NetworkMagic is the expected network magic for all connections. Connections that negotiate
+	// a different network magic are rejected. A value of 0 disables the check
+	NetworkMagic uint32
+	// PeerIdleTimeout is the maximum amount of time to wait for data from an outbound peer before considering
+	// the connection dead and closing it. A value of 0 disables the idle timeout
+	PeerIdleTimeout time.Duration
+	// MaxInboundConns is the maximum number of concurrent inbound node-to-node connections. Additional inbound
+	// connections are closed immediately. A value of 0 means no limit
+	MaxInboundConns int
+	// MaxInboundConnsPerIP is the maximum number of concurrent inbound node-to-node connections from a single
+	// remote IP. Loopback addresses and addresses in InboundIPLimitExempt are not subject to this limit. A value of 0
+	// means no limit
+	MaxInboundConnsPerIP int
+	// InboundIPLimitExempt is a list of IP addresses that are exempt from the per-IP inbound connection limit
+	InboundIPLimitExempt []string
+	// InboundAllowList is a list of CIDR ranges to accept inbound node-to-node connections from. An empty
+	// list allows all addresses not in InboundDenyList
+	InboundAllowList []string
+	// InboundDenyList is a list of CIDR ranges to reject inbound node-to-node connections from
+	InboundDenyList []string
+	// BandwidthLimit is the maximum number of bytes per second to receive and to send, shared across all
+	// node-to-node connections. A value of 0 means no limit
+	BandwidthLimit uint64
+	PromRegistry   prometheus.Registerer
 }
 
 func NewConnectionManager(cfg ConnectionManagerConfig) *ConnectionManager {
@@ -46,12 +98,41 @@ func NewConnectionManager(cfg ConnectionManagerConfig) *ConnectionManager {
 		cfg.Logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
 	}
 	cfg.Logger = cfg.Logger.With("component", "connmanager")
-	return &ConnectionManager{
+	c := &ConnectionManager{
 		config: cfg,
 		connections: make(
 			map[ouroboros.ConnectionId]*ouroboros.Connection,
 		),
+		inboundConnections: make(
+			map[ouroboros.ConnectionId]struct{},
+		),
+		inboundNtcConnections: make(
+			map[ouroboros.ConnectionId]struct{},
+		),
+		inboundConnsByIP:  make(map[string]int),
+		connsByRemoteAddr: make(map[string][]ouroboros.ConnectionId),
+		closeReasons:      make(map[ouroboros.ConnectionId]error),
+	}
+	c.maxInboundConns.Store(int64(cfg.MaxInboundConns))
+	if cfg.BandwidthLimit > 0 {
+		c.readLimiter = newBandwidthLimiter(cfg.BandwidthLimit)
+		c.writeLimiter = newBandwidthLimiter(cfg.BandwidthLimit)
+	}
+	if err := c.SetInboundAccessLists(cfg.InboundAllowList, cfg.InboundDenyList); err != nil {
+		cfg.Logger.Error(
+			"failed to load inbound access lists: " + err.Error(),
+		)
+	}
+	if cfg.PromRegistry != nil {
+		c.initMetrics(cfg.PromRegistry)
 	}
+	if !socketReusePortSupported &&
+		(cfg.OutboundSourcePort > 0 || cfg.OutboundSourcePortRangeStart > 0) {
+		cfg.Logger.Warn(
+			"SO_REUSEPORT is not supported on this platform, outbound source port binding may not work",
+		)
+	}
+	return c
 }
 
 func (c *ConnectionManager) Start() error {
@@ -61,13 +142,59 @@ func (c *ConnectionManager) Start() error {
 	return nil
 }
 
+// Stop closes all listeners. Listening UNIX sockets are removed when closed
+func (c *ConnectionManager) Stop() error {
+	c.listenersMutex.Lock()
+	defer c.listenersMutex.Unlock()
+	var err error
+	for _, listener := range c.listeners {
+		err = errors.Join(err, listener.Close())
+	}
+	c.listeners = nil
+	return err
+}
+
 func (c *ConnectionManager) AddConnection(conn *ouroboros.Connection) {
+	c.addConnection(conn, false, false)
+}
+
+func (c *ConnectionManager) addConnection(
+	conn *ouroboros.Connection,
+	inbound bool,
+	ntc bool,
+) {
 	connId := conn.Id()
 	c.connectionsMutex.Lock()
 	c.connections[connId] = conn
+	if connId.RemoteAddr != nil {
+		remoteAddr := connId.RemoteAddr.String()
+		c.connsByRemoteAddr[remoteAddr] = append(
+			c.connsByRemoteAddr[remoteAddr],
+			connId,
+		)
+	}
+	if inbound {
+		c.inboundConnections[connId] = struct{}{}
+		if ntc {
+			c.inboundNtcConnections[connId] = struct{}{}
+		} else if ip := remoteIP(connId.RemoteAddr); ip != "" {
+			c.inboundConnsByIP[ip]++
+		}
+		if c.metrics != nil {
+			c.metrics.inboundConnsAccepted.Inc()
+		}
+	}
+	c.updateConnectionMetrics()
 	c.connectionsMutex.Unlock()
 	go func() {
 		err := <-conn.ErrorChan()
+		// Use the provided reason for deliberately closed connections
+		c.connectionsMutex.Lock()
+		if reason, ok := c.closeReasons[connId]; ok {
+			err = reason
+			delete(c.closeReasons, connId)
+		}
+		c.connectionsMutex.Unlock()
 		// Remove connection
 		c.RemoveConnection(connId)
 		// Generate event
@@ -83,6 +210,17 @@ func (c *ConnectionManager) AddConnection(conn *ouroboros.Connection) {
 				),
 			)
 		}
+		// Deliver to connection event sink
+		if c.config.ConnEventSink != nil {
+			c.config.ConnEventSink(
+				ConnEvent{
+					Type:         ConnEventTypeDisconnect,
+					Timestamp:    time.Now(),
+					ConnectionId: connId,
+					Error:        err,
+				},
+			)
+		}
 		// Call configured connection closed callback func
 		if c.config.ConnClosedFunc != nil {
 			c.config.ConnClosedFunc(connId, err)
@@ -93,7 +231,91 @@ func (c *ConnectionManager) AddConnection(conn *ouroboros.Connection) {
 func (c *ConnectionManager) RemoveConnection(connId ouroboros.ConnectionId) {
 	c.connectionsMutex.Lock()
 	delete(c.connections, connId)
+	delete(c.closeReasons, connId)
+	if connId.RemoteAddr != nil {
+		remoteAddr := connId.RemoteAddr.String()
+		c.connsByRemoteAddr[remoteAddr] = slices.DeleteFunc(
+			c.connsByRemoteAddr[remoteAddr],
+			func(tmpConnId ouroboros.ConnectionId) bool {
+				return tmpConnId == connId
+			},
+		)
+		if len(c.connsByRemoteAddr[remoteAddr]) == 0 {
+			delete(c.connsByRemoteAddr, remoteAddr)
+		}
+	}
+	if _, ok := c.inboundNtcConnections[connId]; ok {
+		delete(c.inboundConnections, connId)
+		delete(c.inboundNtcConnections, connId)
+	} else if _, ok := c.inboundConnections[connId]; ok {
+		delete(c.inboundConnections, connId)
+		if ip := remoteIP(connId.RemoteAddr); ip != "" {
+			c.inboundConnsByIP[ip]--
+			if c.inboundConnsByIP[ip] <= 0 {
+				delete(c.inboundConnsByIP, ip)
+			}
+		}
+	}
+	c.updateConnectionMetrics()
+	c.connectionsMutex.Unlock()
+}
+
+// CloseConnection shuts down the specified connection. The resulting ConnectionClosedEvent will carry a
+// ClosedByOperatorError with the provided reason
+func (c *ConnectionManager) CloseConnection(
+	connId ouroboros.ConnectionId,
+	reason string,
+) error {
+	return c.CloseConnectionWithError(
+		connId,
+		ClosedByOperatorError{Reason: reason},
+	)
+}
+
+// CloseConnectionWithError shuts down the specified connection. The resulting ConnectionClosedEvent will carry the
+// provided error, which is useful for identifying peer misbehavior
+func (c *ConnectionManager) CloseConnectionWithError(
+	connId ouroboros.ConnectionId,
+	closeErr error,
+) error {
+	c.connectionsMutex.Lock()
+	conn, ok := c.connections[connId]
+	if !ok {
+		c.connectionsMutex.Unlock()
+		return ErrConnectionNotFound
+	}
+	c.closeReasons[connId] = closeErr
 	c.connectionsMutex.Unlock()
+	return conn.Close()
+}
+
+// InboundConnectionCount returns the number of currently active inbound node-to-node connections
+func (c *ConnectionManager) InboundConnectionCount() int {
+	c.connectionsMutex.Lock()
+	defer c.connectionsMutex.Unlock()
+	return len(c.inboundConnections) - len(c.inboundNtcConnections)
+}
+
+// MaxInboundConnections returns the current maximum number of inbound node-to-node connections. A value of 0 means
+// no limit
+func (c *ConnectionManager) MaxInboundConnections() int {
+	return int(c.maxInboundConns.Load())
+}
+
+// SetMaxInboundConnections adjusts the maximum number of inbound node-to-node connections at runtime. Existing
+// connections are not closed if they exceed the new limit, but new inbound connections will be rejected until the
+// count drops below the limit. A value of 0 means no limit
+func (c *ConnectionManager) SetMaxInboundConnections(maxConns int) {
+	c.maxInboundConns.Store(int64(maxConns))
+}
+
+// inboundLimitReached returns whether accepting another inbound connection would exceed the configured limit
+func (c *ConnectionManager) inboundLimitReached() bool {
+	maxConns := c.MaxInboundConnections()
+	if maxConns <= 0 {
+		return false
+	}
+	return c.InboundConnectionCount() >= maxConns
 }
 
 func (c *ConnectionManager) GetConnectionById(
@@ -103,3 +325,120 @@ func (c *ConnectionManager) GetConnectionById(
 	defer c.connectionsMutex.Unlock()
 	return c.connections[connId]
 }
+
+// GetConnectionByRemoteAddr returns the connection with the specified remote address (host:port). If there are
+// multiple connections with the same remote address, such as an inbound and outbound connection to the same peer, the
+// oldest connection is returned
+func (c *ConnectionManager) GetConnectionByRemoteAddr(
+	addr string,
+) (*ouroboros.Connection, bool) {
+	c.connectionsMutex.Lock()
+	defer c.connectionsMutex.Unlock()
+	connIds := c.connsByRemoteAddr[addr]
+	if len(connIds) == 0 {
+		return nil, false
+	}
+	return c.connections[connIds[0]], true
+}
+
+// GetConnectionsByRemoteAddr returns all connections with the specified remote address (host:port), oldest first
+func (c *ConnectionManager) GetConnectionsByRemoteAddr(
+	addr string,
+) []*ouroboros.Connection {
+	c.connectionsMutex.Lock()
+	defer c.connectionsMutex.Unlock()
+	connIds := c.connsByRemoteAddr[addr]
+	ret := make([]*ouroboros.Connection, 0, len(connIds))
+	for _, connId := range connIds {
+		ret = append(ret, c.connections[connId])
+	}
+	return ret
+}
+
+// handshakeCompleted records a completed Ouroboros handshake and generates a HandshakeCompletedEvent
+func (c *ConnectionManager) handshakeCompleted(
+	conn *ouroboros.Connection,
+	inbound bool,
+) {
+	if c.metrics != nil {
+		c.metrics.handshakeCompleted.Inc()
+	}
+	if c.config.EventBus == nil {
+		return
+	}
+	evt := HandshakeCompletedEvent{
+		ConnectionId: conn.Id(),
+		Inbound:      inbound,
+	}
+	protoVersion, versionData := conn.ProtocolVersion()
+	evt.ProtocolVersion = uint(protoVersion)
+	if versionData != nil {
+		evt.PeerSharing = versionData.PeerSharing()
+	}
+	c.config.EventBus.Publish(
+		HandshakeCompletedEventType,
+		event.NewEvent(
+			HandshakeCompletedEventType,
+			evt,
+		),
+	)
+}
+
+// handshakeFailed records a failed Ouroboros handshake
+func (c *ConnectionManager) handshakeFailed() {
+	if c.metrics != nil {
+		c.metrics.handshakeFailed.Inc()
+	}
+}
+
+// inboundIPLimitReached returns whether accepting another inbound connection from the specified remote
+// address would exceed the configured per-IP limit
+func (c *ConnectionManager) inboundIPLimitReached(remoteAddr net.Addr) bool {
+	if c.config.MaxInboundConnsPerIP <= 0 {
+		return false
+	}
+	ip := remoteIP(remoteAddr)
+	if ip == "" {
+		return false
+	}
+	if net.ParseIP(ip).IsLoopback() ||
+		slices.Contains(c.config.InboundIPLimitExempt, ip) {
+		return false
+	}
+	c.connectionsMutex.Lock()
+	defer c.connectionsMutex.Unlock()
+	return c.inboundConnsByIP[ip] >= c.config.MaxInboundConnsPerIP
+}
+
+// remoteIP returns the IP address string for a TCP address, or an empty string for other address types
+func remoteIP(addr net.Addr) string {
+	tcpAddr, ok := addr.(*net.TCPAddr)
+	if !ok {
+		return ""
+	}
+	return tcpAddr.IP.String()
+}
+
+// validateNetworkMagic checks the network magic negotiated during the handshake against our
+// configured network magic
+func (c *ConnectionManager) validateNetworkMagic(
+	conn *ouroboros.Connection,
+) error {
+	if c.config.NetworkMagic == 0 {
+		return nil
+	}
+	_, versionData := conn.ProtocolVersion()
+	if versionData == nil {
+		return nil
+	}
+	if versionData.NetworkMagic() != c.config.NetworkMagic {
+		if c.metrics != nil {
+			c.metrics.networkMagicRejected.Inc()
+		}
+		return NetworkMagicMismatchError{
+			Expected: c.config.NetworkMagic,
+			Actual:   versionData.NetworkMagic(),
+		}
+	}
+	return nil
+}
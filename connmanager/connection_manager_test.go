@@ -183,3 +183,54 @@ func TestConnectionManagerConnClosed(t *testing.T) {
 		t.Fatalf("did not receive error within timeout")
 	}
 }
+
+func TestConnectionManagerGetConnectionByRemoteAddr(t *testing.T) {
+	defer goleak.VerifyNone(t)
+	doneChan := make(chan any)
+	connManager := connmanager.NewConnectionManager(
+		connmanager.ConnectionManagerConfig{
+			ConnClosedFunc: func(connId ouroboros.ConnectionId, err error) {
+				close(doneChan)
+			},
+		},
+	)
+	mockConn := ouroboros_mock.NewConnection(
+		ouroboros_mock.ProtocolRoleClient,
+		[]ouroboros_mock.ConversationEntry{
+			ouroboros_mock.ConversationEntryHandshakeRequestGeneric,
+			ouroboros_mock.ConversationEntryHandshakeNtNResponse,
+		},
+	)
+	oConn, err := ouroboros.New(
+		ouroboros.WithConnection(mockConn),
+		ouroboros.WithNetworkMagic(ouroboros_mock.MockNetworkMagic),
+		ouroboros.WithNodeToNode(true),
+		ouroboros.WithKeepAlive(false),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error when creating Ouroboros object: %s", err)
+	}
+	connManager.AddConnection(oConn)
+	remoteAddr := oConn.Id().RemoteAddr.String()
+	conn, ok := connManager.GetConnectionByRemoteAddr(remoteAddr)
+	if !ok || conn != oConn {
+		t.Fatalf("did not get expected connection for remote address %s", remoteAddr)
+	}
+	if conns := connManager.GetConnectionsByRemoteAddr(remoteAddr); len(conns) != 1 {
+		t.Fatalf("did not get expected number of connections: got %d, expected 1", len(conns))
+	}
+	if _, ok := connManager.GetConnectionByRemoteAddr("192.0.2.1:3001"); ok {
+		t.Fatalf("got unexpected connection for unknown remote address")
+	}
+	oConn.Close()
+	select {
+	case <-doneChan:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("did not receive connection close within timeout")
+	}
+	if _, ok := connManager.GetConnectionByRemoteAddr(remoteAddr); ok {
+		t.Fatalf("got unexpected connection after close")
+	}
+	// TODO: actually wait for shutdown
+	time.Sleep(5 * time.Second)
+}
@@ -0,0 +1,121 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"bytes"
+	"errors"
+	"net"
+
+	"github.com/blinklabs-io/dingo/event"
+	ouroboros "github.com/blinklabs-io/gouroboros"
+)
+
+// dedupConnection checks whether the specified connection duplicates an existing connection to the same peer in the
+// opposite direction. This happens when two nodes that share their listen port as the outbound source port dial each
+// other simultaneously, so both connections have the same remote address. One of the pair is closed, and the ID of the
+// connection that was kept is returned along with whether the specified connection was the one closed
+func (c *ConnectionManager) dedupConnection(
+	connId ouroboros.ConnectionId,
+) (ouroboros.ConnectionId, bool) {
+	if connId.RemoteAddr == nil {
+		return connId, false
+	}
+	c.connectionsMutex.Lock()
+	_, inbound := c.inboundConnections[connId]
+	var otherConnId ouroboros.ConnectionId
+	var found bool
+	for _, tmpConnId := range c.connsByRemoteAddr[connId.RemoteAddr.String()] {
+		if tmpConnId == connId {
+			continue
+		}
+		if _, tmpInbound := c.inboundConnections[tmpConnId]; tmpInbound != inbound {
+			otherConnId = tmpConnId
+			found = true
+			break
+		}
+	}
+	c.connectionsMutex.Unlock()
+	if !found {
+		return connId, false
+	}
+	inboundConnId, outboundConnId := connId, otherConnId
+	if !inbound {
+		inboundConnId, outboundConnId = otherConnId, connId
+	}
+	closeInbound, ok := closeInboundDuplicate(outboundConnId)
+	if !ok {
+		return connId, false
+	}
+	dropConnId, keepConnId := outboundConnId, inboundConnId
+	if closeInbound {
+		dropConnId, keepConnId = inboundConnId, outboundConnId
+	}
+	c.config.Logger.Info(
+		"closing duplicate connection",
+		"connection_id", dropConnId.String(),
+		"kept_connection_id", keepConnId.String(),
+	)
+	if c.config.EventBus != nil {
+		c.config.EventBus.Publish(
+			DuplicateConnectionEventType,
+			event.NewEvent(
+				DuplicateConnectionEventType,
+				DuplicateConnectionEvent{
+					ConnectionId:     dropConnId,
+					KeptConnectionId: keepConnId,
+					Inbound:          closeInbound,
+				},
+			),
+		)
+	}
+	// This deliberately isn't closed as a ClosedByOperatorError, since the peer is still connected via the kept
+	// connection and shouldn't be forgotten
+	if err := c.CloseConnectionWithError(
+		dropConnId,
+		DuplicateConnectionError{KeptConnectionId: keepConnId},
+	); err != nil &&
+		!errors.Is(err, ErrConnectionNotFound) {
+		c.config.Logger.Warn(
+			"failed to close duplicate connection: "+err.Error(),
+			"connection_id", dropConnId.String(),
+		)
+	}
+	return keepConnId, dropConnId == connId
+}
+
+// closeInboundDuplicate determines which of a pair of duplicate connections to close, given the outbound connection
+// of the pair. Like cardano-node, we close the connection initiated by the node with the lower address, which means
+// both nodes make the same choice. The second return value is false if the addresses can't be compared
+func closeInboundDuplicate(outboundConnId ouroboros.ConnectionId) (bool, bool) {
+	localAddr, ok := outboundConnId.LocalAddr.(*net.TCPAddr)
+	if !ok {
+		return false, false
+	}
+	remoteAddr, ok := outboundConnId.RemoteAddr.(*net.TCPAddr)
+	if !ok {
+		return false, false
+	}
+	// We initiated the outbound connection, so we close it if we have the lower address
+	return !tcpAddrLess(localAddr, remoteAddr), true
+}
+
+// tcpAddrLess returns whether TCP address a sorts before b by IP and then port
+func tcpAddrLess(a, b *net.TCPAddr) bool {
+	if cmp := bytes.Compare(a.IP.To16(), b.IP.To16()); cmp != 0 {
+		return cmp < 0
+	}
+	return a.Port < b.Port
+}
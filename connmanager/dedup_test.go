@@ -0,0 +1,50 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"net"
+	"testing"
+
+	ouroboros "github.com/blinklabs-io/gouroboros"
+)
+
+func TestCloseInboundDuplicate(t *testing.T) {
+	lowAddr := &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 3001}
+	highAddr := &net.TCPAddr{IP: net.ParseIP("192.0.2.2"), Port: 3001}
+	// We have the lower address, so our outbound connection is closed
+	closeInbound, ok := closeInboundDuplicate(
+		ouroboros.ConnectionId{LocalAddr: lowAddr, RemoteAddr: highAddr},
+	)
+	if !ok || closeInbound {
+		t.Fatalf("expected outbound connection to be closed when we have the lower address")
+	}
+	// The peer has the lower address, so our inbound connection (its outbound) is closed
+	closeInbound, ok = closeInboundDuplicate(
+		ouroboros.ConnectionId{LocalAddr: highAddr, RemoteAddr: lowAddr},
+	)
+	if !ok || !closeInbound {
+		t.Fatalf("expected inbound connection to be closed when the peer has the lower address")
+	}
+	// Non-TCP addresses can't be compared
+	if _, ok := closeInboundDuplicate(
+		ouroboros.ConnectionId{
+			LocalAddr:  &net.UnixAddr{Name: "a", Net: "unix"},
+			RemoteAddr: &net.UnixAddr{Name: "b", Net: "unix"},
+		},
+	); ok {
+		t.Fatalf("expected non-TCP addresses to not be compared")
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"errors"
+	"fmt"
+
+	ouroboros "github.com/blinklabs-io/gouroboros"
+)
+
+// ErrSocketControl is returned when setting socket options on an outbound or listener socket fails
+var ErrSocketControl = errors.New("failed to set socket options")
+
+// ErrTooManyInboundConnections is returned when an inbound connection is rejected due to the configured limit
+var ErrTooManyInboundConnections = errors.New("too many inbound connections")
+
+// ErrTooManyInboundConnectionsFromIP is returned when an inbound connection is rejected due to the per-IP limit
+var ErrTooManyInboundConnectionsFromIP = errors.New(
+	"too many inbound connections from IP",
+)
+
+// ErrBlockedByDenylist is returned when an inbound connection is rejected by the deny list
+var ErrBlockedByDenylist = errors.New("blocked by denylist")
+
+// ErrBlockedByAllowlist is returned when an inbound connection is rejected for not matching the allow list
+var ErrBlockedByAllowlist = errors.New("blocked by allowlist")
+
+// ErrInvalidProxyHeader is returned when an inbound connection on a PROXY protocol listener doesn't start with a
+// valid PROXY protocol header
+var ErrInvalidProxyHeader = errors.New("invalid PROXY protocol header")
+
+// ErrConnectionNotFound is returned when the specified connection is not known to the connection manager
+var ErrConnectionNotFound = errors.New("connection not found")
+
+// ErrDNSLookup is used to classify outbound connection failures caused by resolving the peer address
+var ErrDNSLookup = errors.New("DNS lookup failed")
+
+// ErrDial is used to classify outbound connection failures when establishing the TCP connection
+var ErrDial = errors.New("dial failed")
+
+// ErrHandshake is used to classify outbound connection failures during the Ouroboros handshake
+var ErrHandshake = errors.New("handshake failed")
+
+// ErrSelfConnection is returned by CreateOutboundConn when the address resolves to one of our own listeners
+var ErrSelfConnection = errors.New("self-connection detected")
+
+// ErrNetworkMagicMismatch matches any NetworkMagicMismatchError when used with errors.Is
+var ErrNetworkMagicMismatch = errors.New("network magic mismatch")
+
+// OutboundConnError is returned by CreateOutboundConn. The Kind field is one of ErrDNSLookup, ErrDial, or
+// ErrHandshake, and both it and the underlying error can be matched with errors.Is/errors.As
+type OutboundConnError struct {
+	Address string
+	Kind    error
+	Err     error
+}
+
+func (e OutboundConnError) Error() string {
+	return fmt.Sprintf("%s: %s: %s", e.Kind, e.Address, e.Err)
+}
+
+func (e OutboundConnError) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// IsHandshakeError returns whether the error was caused by a failure during the Ouroboros handshake, such
+// as a network magic mismatch or unsupported protocol version, which is unlikely to resolve on retry
+func IsHandshakeError(err error) bool {
+	return errors.Is(err, ErrHandshake) || errors.Is(err, ErrNetworkMagicMismatch)
+}
+
+// NetworkMagicMismatchError is returned when the network magic negotiated with a peer does
+// not match the configured network magic
+type NetworkMagicMismatchError struct {
+	Expected uint32
+	Actual   uint32
+}
+
+func (e NetworkMagicMismatchError) Error() string {
+	return fmt.Sprintf(
+		"network magic mismatch: expected %d, peer negotiated %d",
+		e.Expected,
+		e.Actual,
+	)
+}
+
+func (e NetworkMagicMismatchError) Is(target error) bool {
+	return target == ErrNetworkMagicMismatch
+}
+
+// ClosedByOperatorError is used as the error for a ConnectionClosedEvent when a connection is closed deliberately
+// via CloseConnection
+type ClosedByOperatorError struct {
+	Reason string
+}
+
+func (e ClosedByOperatorError) Error() string {
+	return "connection closed by operator: " + e.Reason
+}
+
+// ErrDuplicateConnection matches any DuplicateConnectionError when used with errors.Is
+var ErrDuplicateConnection = errors.New("duplicate connection")
+
+// DuplicateConnectionError is returned by CreateOutboundConn when the new connection duplicates an existing inbound
+// connection from the same peer and was closed in favor of it. It's also used as the error for the ConnectionClosedEvent
+// of any connection closed as a duplicate
+type DuplicateConnectionError struct {
+	KeptConnectionId ouroboros.ConnectionId
+}
+
+func (e DuplicateConnectionError) Error() string {
+	return "duplicate connection: keeping existing connection " + e.KeptConnectionId.String()
+}
+
+func (e DuplicateConnectionError) Is(target error) bool {
+	return target == ErrDuplicateConnection
+}
@@ -16,13 +16,17 @@ package connmanager
 
 import (
 	"net"
+	"time"
 
 	ouroboros "github.com/blinklabs-io/gouroboros"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
 )
 
 const (
-	InboundConnectionEventType = "connmanager.inbound-conn"
-	ConnectionClosedEventType  = "connmanager.conn-closed"
+	InboundConnectionEventType   = "connmanager.inbound-conn"
+	ConnectionClosedEventType    = "connmanager.conn-closed"
+	HandshakeCompletedEventType  = "connmanager.handshake-completed"
+	DuplicateConnectionEventType = "connmanager.duplicate-conn"
 )
 
 type InboundConnectionEvent struct {
@@ -31,7 +35,49 @@ type InboundConnectionEvent struct {
 	RemoteAddr   net.Addr
 }
 
+// HandshakeCompletedEvent is generated when the Ouroboros handshake completes for an inbound or outbound connection
+type HandshakeCompletedEvent struct {
+	ConnectionId    ouroboros.ConnectionId
+	Inbound         bool
+	ProtocolVersion uint
+	PeerSharing     bool
+}
+
+// DuplicateConnectionEvent is generated when an inbound and outbound connection to the same peer are detected and
+// one of them is closed
+type DuplicateConnectionEvent struct {
+	ConnectionId     ouroboros.ConnectionId // Connection that was closed
+	KeptConnectionId ouroboros.ConnectionId
+	Inbound          bool // Whether the closed connection was inbound
+}
+
 type ConnectionClosedEvent struct {
 	ConnectionId ouroboros.ConnectionId
 	Error        error
 }
+
+// ConnEventSinkFunc is a function that receives structured connection events
+type ConnEventSinkFunc func(ConnEvent)
+
+type ConnEventType string
+
+const (
+	ConnEventTypeConnect      ConnEventType = "connect"
+	ConnEventTypeDisconnect   ConnEventType = "disconnect"
+	ConnEventTypeReconnect    ConnEventType = "reconnect"
+	ConnEventTypeRollForward  ConnEventType = "rollforward"
+	ConnEventTypeRollBackward ConnEventType = "rollbackward"
+)
+
+// ConnEvent is a structured record of a connection lifecycle or chainsync event. These are delivered
+// to a configured ConnEventSinkFunc for shipping to external systems
+type ConnEvent struct {
+	Type           ConnEventType
+	Timestamp      time.Time
+	ConnectionId   ouroboros.ConnectionId
+	Address        string
+	Error          error
+	ReconnectCount int
+	ReconnectDelay time.Duration
+	Point          ocommon.Point
+}
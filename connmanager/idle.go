@@ -0,0 +1,42 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"net"
+	"time"
+)
+
+// idleTimeoutConn wraps a net.Conn and extends the read deadline before each read. If no data is received
+// from the peer within the idle timeout, the read fails and the connection is torn down. This catches
+// half-open TCP connections that keep-alive alone may not detect
+type idleTimeoutConn struct {
+	net.Conn
+	idleTimeout time.Duration
+}
+
+func newIdleTimeoutConn(conn net.Conn, idleTimeout time.Duration) *idleTimeoutConn {
+	return &idleTimeoutConn{
+		Conn:        conn,
+		idleTimeout: idleTimeout,
+	}
+}
+
+func (c *idleTimeoutConn) Read(b []byte) (int, error) {
+	if err := c.Conn.SetReadDeadline(time.Now().Add(c.idleTimeout)); err != nil {
+		return 0, err
+	}
+	return c.Conn.Read(b)
+}
@@ -16,8 +16,13 @@ package connmanager
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net"
+	"os"
+	"slices"
 
 	"github.com/blinklabs-io/dingo/event"
 	ouroboros "github.com/blinklabs-io/gouroboros"
@@ -30,6 +35,42 @@ type ListenerConfig struct {
 	ListenAddress  string
 	ReuseAddress   bool
 	ConnectionOpts []ouroboros.ConnectionOptionFunc
+	// TLS options for node-to-client listeners. TLS is enabled when a cert and key are provided,
+	// and client certificates are required when a client CA is provided
+	TlsCertFilePath     string
+	TlsKeyFilePath      string
+	TlsClientCaFilePath string
+	// UseProxyProtocol expects each connection to start with a PROXY protocol v1/v2 header, which is used to
+	// determine the real client address when behind a load balancer. Connections without a valid header are rejected
+	UseProxyProtocol bool
+}
+
+// tlsConfig builds a TLS config from the listener TLS options. It returns nil if TLS is not enabled
+func (l ListenerConfig) tlsConfig() (*tls.Config, error) {
+	if !l.UseNtC || l.TlsCertFilePath == "" || l.TlsKeyFilePath == "" {
+		return nil, nil
+	}
+	cert, err := tls.LoadX509KeyPair(l.TlsCertFilePath, l.TlsKeyFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load TLS cert/key: %w", err)
+	}
+	ret := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if l.TlsClientCaFilePath != "" {
+		caData, err := os.ReadFile(l.TlsClientCaFilePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read TLS client CA: %w", err)
+		}
+		caPool := x509.NewCertPool()
+		if !caPool.AppendCertsFromPEM(caData) {
+			return nil, errors.New("failed to parse TLS client CA")
+		}
+		ret.ClientCAs = caPool
+		ret.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return ret, nil
 }
 
 func (c *ConnectionManager) startListeners() error {
@@ -42,8 +83,17 @@ func (c *ConnectionManager) startListeners() error {
 }
 
 func (c *ConnectionManager) startListener(l ListenerConfig) error {
+	tlsConfig, err := l.tlsConfig()
+	if err != nil {
+		return err
+	}
 	// Create listener if none is provided
 	if l.Listener == nil {
+		if l.ListenNetwork == "unix" {
+			if err := removeStaleUnixSocket(l.ListenAddress); err != nil {
+				return err
+			}
+		}
 		listenConfig := net.ListenConfig{}
 		if l.ReuseAddress {
 			listenConfig.Control = socketControl
@@ -77,11 +127,18 @@ func (c *ConnectionManager) startListener(l ListenerConfig) error {
 		defaultConnOpts,
 		l.ConnectionOpts...,
 	)
+	c.listenersMutex.Lock()
+	c.listeners = append(c.listeners, l.Listener)
+	c.listenersMutex.Unlock()
 	go func() {
 		for {
 			// Accept connection
 			conn, err := l.Listener.Accept()
 			if err != nil {
+				// Stop accepting connections when the listener is closed
+				if errors.Is(err, net.ErrClosed) {
+					return
+				}
 				c.config.Logger.Error(
 					fmt.Sprintf("listener: accept failed: %s", err),
 				)
@@ -99,42 +156,154 @@ func (c *ConnectionManager) startListener(l ListenerConfig) error {
 				}
 				conn = tmpConn
 			}
-			c.config.Logger.Info(
-				fmt.Sprintf(
-					"listener: accepted connection from %s",
-					conn.RemoteAddr(),
-				),
-			)
-			// Setup Ouroboros connection
-			connOpts := append(
-				defaultConnOpts,
-				ouroboros.WithConnection(conn),
-			)
-			oConn, err := ouroboros.NewConnection(connOpts...)
-			if err != nil {
-				c.config.Logger.Error(
-					fmt.Sprintf(
-						"listener: failed to setup connection: %s",
-						err,
-					),
-				)
+			// Recover the client address from the PROXY protocol header. The header is read in its own goroutine, so
+			// that a slow or idle client can't hold up accepting other connections
+			if l.UseProxyProtocol {
+				go func(conn net.Conn) {
+					tmpConn, err := newProxyProtoConn(conn)
+					if err != nil {
+						c.rejectInboundConn(conn, err)
+						return
+					}
+					c.setupInboundConn(l, tmpConn, tlsConfig, defaultConnOpts)
+				}(conn)
 				continue
 			}
-			// Add to connection manager
-			c.AddConnection(oConn)
-			// Generate event
-			c.config.EventBus.Publish(
-				InboundConnectionEventType,
-				event.NewEvent(
-					InboundConnectionEventType,
-					InboundConnectionEvent{
-						ConnectionId: oConn.Id(),
-						LocalAddr:    conn.LocalAddr(),
-						RemoteAddr:   conn.RemoteAddr(),
-					},
-				),
-			)
+			c.setupInboundConn(l, conn, tlsConfig, defaultConnOpts)
 		}
 	}()
 	return nil
 }
+
+// setupInboundConn checks an accepted inbound connection against our access lists and limits, and sets up the
+// Ouroboros connection
+func (c *ConnectionManager) setupInboundConn(
+	l ListenerConfig,
+	conn net.Conn,
+	tlsConfig *tls.Config,
+	defaultConnOpts []ouroboros.ConnectionOptionFunc,
+) {
+	c.config.Logger.Info(
+		fmt.Sprintf(
+			"listener: accepted connection from %s",
+			conn.RemoteAddr(),
+		),
+	)
+	// Check node-to-node connections against access lists and inbound connection limits. Local clients
+	// aren't limited, so that they can't be locked out by peers
+	if !l.UseNtC {
+		if err := c.inboundACL.Load().check(conn.RemoteAddr()); err != nil {
+			c.rejectInboundConn(conn, err)
+			return
+		}
+		if c.inboundLimitReached() {
+			c.rejectInboundConn(conn, ErrTooManyInboundConnections)
+			return
+		}
+		if c.inboundIPLimitReached(conn.RemoteAddr()) {
+			c.rejectInboundConn(conn, ErrTooManyInboundConnectionsFromIP)
+			return
+		}
+	}
+	// Apply the bandwidth limit to node-to-node connections
+	if !l.UseNtC {
+		conn = c.throttleConn(conn)
+	}
+	// Wrap TLS connections
+	if tlsConfig != nil {
+		conn = tls.Server(conn, tlsConfig)
+	}
+	// Setup Ouroboros connection
+	connOpts := slices.Concat(
+		defaultConnOpts,
+		[]ouroboros.ConnectionOptionFunc{ouroboros.WithConnection(conn)},
+	)
+	oConn, err := ouroboros.NewConnection(connOpts...)
+	if err != nil {
+		c.handshakeFailed()
+		c.config.Logger.Error(
+			fmt.Sprintf(
+				"listener: failed to setup connection: %s",
+				err,
+			),
+		)
+		return
+	}
+	// Make sure the peer is on the same network
+	if err := c.validateNetworkMagic(oConn); err != nil {
+		c.config.Logger.Error(
+			fmt.Sprintf(
+				"listener: rejecting connection from %s: %s",
+				conn.RemoteAddr(),
+				err,
+			),
+		)
+		_ = oConn.Close()
+		return
+	}
+	c.handshakeCompleted(oConn, true)
+	// Add to connection manager
+	c.addConnection(oConn, true, l.UseNtC)
+	// Generate event
+	c.config.EventBus.Publish(
+		InboundConnectionEventType,
+		event.NewEvent(
+			InboundConnectionEventType,
+			InboundConnectionEvent{
+				ConnectionId: oConn.Id(),
+				LocalAddr:    conn.LocalAddr(),
+				RemoteAddr:   conn.RemoteAddr(),
+			},
+		),
+	)
+	// Close any duplicate of an existing outbound connection to the same peer. This is done after
+	// generating the event above so that the close is handled after the new connection is known
+	c.dedupConnection(oConn.Id())
+}
+
+// rejectInboundConn closes an inbound connection before the Ouroboros handshake and generates a
+// ConnectionClosedEvent with the reason
+func (c *ConnectionManager) rejectInboundConn(conn net.Conn, reason error) {
+	c.config.Logger.Warn(
+		fmt.Sprintf(
+			"listener: rejecting connection from %s: %s",
+			conn.RemoteAddr(),
+			reason,
+		),
+	)
+	_ = conn.Close()
+	if c.config.EventBus != nil {
+		c.config.EventBus.Publish(
+			ConnectionClosedEventType,
+			event.NewEvent(
+				ConnectionClosedEventType,
+				ConnectionClosedEvent{
+					ConnectionId: ouroboros.ConnectionId{
+						LocalAddr:  conn.LocalAddr(),
+						RemoteAddr: conn.RemoteAddr(),
+					},
+					Error: reason,
+				},
+			),
+		)
+	}
+}
+
+// removeStaleUnixSocket removes an existing UNIX socket file at the specified path if nothing is listening on it
+func removeStaleUnixSocket(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	conn, err := net.Dial("unix", path)
+	if err == nil {
+		_ = conn.Close()
+		return fmt.Errorf("socket is already in use: %s", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove stale socket: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/dingo/event"
+	ouroboros "github.com/blinklabs-io/gouroboros"
+)
+
+// startTestListener starts a TCP listener on a random local port and returns its address
+func startTestListener(t *testing.T, c *ConnectionManager, l ListenerConfig) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error creating listener: %s", err)
+	}
+	l.Listener = listener
+	if err := c.startListener(l); err != nil {
+		t.Fatalf("unexpected error starting listener: %s", err)
+	}
+	t.Cleanup(func() { _ = c.Stop() })
+	return listener.Addr().String()
+}
+
+func TestInboundLimitNodeToNodeOnly(t *testing.T) {
+	eventBus := event.NewEventBus(nil)
+	closedSubId, closedCh := eventBus.Subscribe(ConnectionClosedEventType)
+	defer eventBus.Unsubscribe(ConnectionClosedEventType, closedSubId)
+	c := NewConnectionManager(
+		ConnectionManagerConfig{
+			EventBus:        eventBus,
+			MaxInboundConns: 1,
+		},
+	)
+	// Fill the inbound limit with an existing node-to-node connection
+	c.connectionsMutex.Lock()
+	c.inboundConnections[ouroboros.ConnectionId{
+		LocalAddr:  &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 3001},
+		RemoteAddr: &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 40000},
+	}] = struct{}{}
+	c.connectionsMutex.Unlock()
+	ntcAddr := startTestListener(t, c, ListenerConfig{UseNtC: true})
+	ntnAddr := startTestListener(t, c, ListenerConfig{})
+	// Node-to-node connections are rejected
+	ntnConn, err := net.Dial("tcp", ntnAddr)
+	if err != nil {
+		t.Fatalf("unexpected error connecting: %s", err)
+	}
+	defer ntnConn.Close()
+	select {
+	case evt := <-closedCh:
+		closedEvt := evt.Data.(ConnectionClosedEvent)
+		if !errors.Is(closedEvt.Error, ErrTooManyInboundConnections) {
+			t.Fatalf("did not get expected close reason: %v", closedEvt.Error)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("node-to-node connection was not rejected")
+	}
+	// Node-to-client connections aren't limited
+	ntcConn, err := net.Dial("tcp", ntcAddr)
+	if err != nil {
+		t.Fatalf("unexpected error connecting: %s", err)
+	}
+	defer ntcConn.Close()
+	select {
+	case evt := <-closedCh:
+		closedEvt := evt.Data.(ConnectionClosedEvent)
+		t.Fatalf("node-to-client connection was unexpectedly closed: %v", closedEvt.Error)
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+func TestProxyProtoHeaderDoesNotBlockAccept(t *testing.T) {
+	eventBus := event.NewEventBus(nil)
+	closedSubId, closedCh := eventBus.Subscribe(ConnectionClosedEventType)
+	defer eventBus.Unsubscribe(ConnectionClosedEventType, closedSubId)
+	c := NewConnectionManager(
+		ConnectionManagerConfig{
+			EventBus: eventBus,
+		},
+	)
+	addr := startTestListener(t, c, ListenerConfig{UseProxyProtocol: true})
+	// A client that never sends the PROXY header
+	idleConn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("unexpected error connecting: %s", err)
+	}
+	defer idleConn.Close()
+	// Another client sending an invalid header should be handled without waiting for the idle client to time out
+	badConn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatalf("unexpected error connecting: %s", err)
+	}
+	defer badConn.Close()
+	if _, err := badConn.Write([]byte("not a proxy header\r\n")); err != nil {
+		t.Fatalf("unexpected error writing: %s", err)
+	}
+	select {
+	case evt := <-closedCh:
+		closedEvt := evt.Data.(ConnectionClosedEvent)
+		if !errors.Is(closedEvt.Error, ErrInvalidProxyHeader) {
+			t.Fatalf("did not get expected close reason: %v", closedEvt.Error)
+		}
+	case <-time.After(proxyHeaderTimeout / 2):
+		t.Fatal("connection with invalid PROXY header was not rejected while another client was pending")
+	}
+}
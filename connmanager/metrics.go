@@ -0,0 +1,88 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+type connectionManagerMetrics struct {
+	networkMagicRejected prometheus.Counter
+	handshakeCompleted   prometheus.Counter
+	handshakeFailed      prometheus.Counter
+	outboundConnFailed   *prometheus.CounterVec
+	inboundConns         prometheus.Gauge
+	outboundConns        prometheus.Gauge
+	inboundConnsAccepted prometheus.Counter
+}
+
+func (c *ConnectionManager) initMetrics(promRegistry prometheus.Registerer) {
+	promautoFactory := promauto.With(promRegistry)
+	c.metrics = &connectionManagerMetrics{}
+	c.metrics.networkMagicRejected = promautoFactory.NewCounter(
+		prometheus.CounterOpts{
+			Name: "connmanager_network_magic_rejected_total",
+			Help: "total connections rejected due to network magic mismatch",
+		},
+	)
+	c.metrics.handshakeCompleted = promautoFactory.NewCounter(
+		prometheus.CounterOpts{
+			Name: "connmanager_handshake_completed_total",
+			Help: "total connections that completed the Ouroboros handshake",
+		},
+	)
+	c.metrics.handshakeFailed = promautoFactory.NewCounter(
+		prometheus.CounterOpts{
+			Name: "connmanager_handshake_failed_total",
+			Help: "total TCP connections that failed to complete the Ouroboros handshake",
+		},
+	)
+	c.metrics.outboundConnFailed = promautoFactory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "connmanager_outbound_connection_failed_total",
+			Help: "total failed outbound connection attempts by reason",
+		},
+		[]string{"reason"},
+	)
+	c.metrics.inboundConns = promautoFactory.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "connmanager_inbound_connections",
+			Help: "current number of inbound connections",
+		},
+	)
+	c.metrics.outboundConns = promautoFactory.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "connmanager_outbound_connections",
+			Help: "current number of outbound connections",
+		},
+	)
+	c.metrics.inboundConnsAccepted = promautoFactory.NewCounter(
+		prometheus.CounterOpts{
+			Name: "connmanager_inbound_connections_accepted_total",
+			Help: "total inbound connections accepted",
+		},
+	)
+}
+
+// updateConnectionMetrics sets the connection count gauges. The connections mutex must be held when calling this
+func (c *ConnectionManager) updateConnectionMetrics() {
+	if c.metrics == nil {
+		return
+	}
+	inboundCount := len(c.inboundConnections)
+	c.metrics.inboundConns.Set(float64(inboundCount))
+	c.metrics.outboundConns.Set(float64(len(c.connections) - inboundCount))
+}
@@ -16,8 +16,10 @@ package connmanager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
+	"syscall"
 	"time"
 
 	ouroboros "github.com/blinklabs-io/gouroboros"
@@ -25,6 +27,12 @@ import (
 	"go.opentelemetry.io/otel/attribute"
 )
 
+const (
+	AddressFamilyIPv4 = "ipv4"
+	AddressFamilyIPv6 = "ipv6"
+	AddressFamilyDual = "dual"
+)
+
 func (c *ConnectionManager) CreateOutboundConn(
 	address string,
 ) (*ouroboros.Connection, error) {
@@ -41,12 +49,12 @@ func (c *ConnectionManager) CreateOutboundConn(
 	dialer := net.Dialer{
 		Timeout: 10 * time.Second,
 	}
-	if c.config.OutboundSourcePort > 0 {
+	if sourcePort := c.nextOutboundSourcePort(); sourcePort > 0 {
 		// Setup connection to use our listening port as the source port
 		// This is required for peer sharing to be useful
 		clientAddr, _ = net.ResolveTCPAddr(
 			"tcp",
-			fmt.Sprintf(":%d", c.config.OutboundSourcePort),
+			fmt.Sprintf(":%d", sourcePort),
 		)
 		dialer.LocalAddr = clientAddr
 		dialer.Control = socketControl
@@ -55,10 +63,41 @@ func (c *ConnectionManager) CreateOutboundConn(
 		"establishing TCP connection to: "+address,
 		"role", "client",
 	)
-	tmpConn, err := dialer.Dial("tcp", address)
+	tmpConn, err := c.dialOutbound(dialer, address)
 	if err != nil {
-		return nil, err
+		if dialer.LocalAddr == nil || !isSourceBindError(err) {
+			return nil, c.outboundConnError(address, err)
+		}
+		// Binding to our source port is only needed for peer sharing, so we retry without it
+		// rather than losing connectivity
+		c.config.Logger.Warn(
+			fmt.Sprintf(
+				"failed to bind outbound connection to %s to source port, retrying without: %s",
+				address,
+				err,
+			),
+			"role", "client",
+		)
+		dialer.LocalAddr = nil
+		dialer.Control = nil
+		tmpConn, err = c.dialOutbound(dialer, address)
+		if err != nil {
+			return nil, c.outboundConnError(address, err)
+		}
 	}
+	// Make sure we didn't dial ourselves
+	if c.isSelfConnection(tmpConn) {
+		_ = tmpConn.Close()
+		if c.metrics != nil {
+			c.metrics.outboundConnFailed.WithLabelValues("self").Inc()
+		}
+		return nil, fmt.Errorf("%w: %s", ErrSelfConnection, address)
+	}
+	// Detect dead peers that stop sending data
+	if c.config.PeerIdleTimeout > 0 {
+		tmpConn = newIdleTimeoutConn(tmpConn, c.config.PeerIdleTimeout)
+	}
+	tmpConn = c.throttleConn(tmpConn)
 	// Build connection options
 	connOpts := []ouroboros.ConnectionOptionFunc{
 		ouroboros.WithConnection(tmpConn),
@@ -77,8 +116,23 @@ func (c *ConnectionManager) CreateOutboundConn(
 		connOpts...,
 	)
 	if err != nil {
-		return nil, err
+		c.handshakeFailed()
+		return nil, c.outboundHandshakeError(address, err)
+	}
+	// Make sure the peer is on the same network
+	if err := c.validateNetworkMagic(oConn); err != nil {
+		c.config.Logger.Error(
+			fmt.Sprintf(
+				"rejecting connection to %s: %s",
+				address,
+				err,
+			),
+			"role", "client",
+		)
+		_ = oConn.Close()
+		return nil, c.outboundHandshakeError(address, err)
 	}
+	c.handshakeCompleted(oConn, false)
 	c.config.Logger.Info(
 		"connected ouroboros to "+address,
 		"role", "client",
@@ -89,5 +143,186 @@ func (c *ConnectionManager) CreateOutboundConn(
 		"connection_id", oConn.Id().String(),
 	)
 	c.AddConnection(oConn)
+	if keptConnId, dropped := c.dedupConnection(oConn.Id()); dropped {
+		return nil, DuplicateConnectionError{KeptConnectionId: keptConnId}
+	}
+	if c.config.ConnEventSink != nil {
+		c.config.ConnEventSink(
+			ConnEvent{
+				Type:         ConnEventTypeConnect,
+				Timestamp:    time.Now(),
+				ConnectionId: oConn.Id(),
+				Address:      address,
+			},
+		)
+	}
 	return oConn, nil
 }
+
+// outboundConnError classifies an error from establishing the TCP connection to a peer
+func (c *ConnectionManager) outboundConnError(address string, err error) error {
+	kind := ErrDial
+	reason := "dial"
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		kind = ErrDNSLookup
+		reason = "dns"
+	}
+	if c.metrics != nil {
+		c.metrics.outboundConnFailed.WithLabelValues(reason).Inc()
+	}
+	return OutboundConnError{
+		Address: address,
+		Kind:    kind,
+		Err:     err,
+	}
+}
+
+// outboundHandshakeError classifies an error from the Ouroboros handshake with a peer
+func (c *ConnectionManager) outboundHandshakeError(
+	address string,
+	err error,
+) error {
+	reason := "handshake"
+	if errors.Is(err, ErrNetworkMagicMismatch) {
+		reason = "network_magic"
+	}
+	if c.metrics != nil {
+		c.metrics.outboundConnFailed.WithLabelValues(reason).Inc()
+	}
+	return OutboundConnError{
+		Address: address,
+		Kind:    ErrHandshake,
+		Err:     err,
+	}
+}
+
+// isSourceBindError returns whether a dial error was caused by setting socket options or binding to the local address
+func isSourceBindError(err error) bool {
+	return errors.Is(err, ErrSocketControl) ||
+		errors.Is(err, syscall.EADDRINUSE) ||
+		errors.Is(err, syscall.EADDRNOTAVAIL) ||
+		errors.Is(err, syscall.EACCES)
+}
+
+// nextOutboundSourcePort returns the source port to use for the next outbound connection. Ports are chosen
+// round-robin from the configured range, if any, falling back to the single configured source port
+func (c *ConnectionManager) nextOutboundSourcePort() uint {
+	start := c.config.OutboundSourcePortRangeStart
+	end := c.config.OutboundSourcePortRangeEnd
+	if start == 0 || end < start {
+		return c.config.OutboundSourcePort
+	}
+	rangeSize := uint64(end - start + 1)
+	idx := (c.sourcePortIdx.Add(1) - 1) % rangeSize
+	return start + uint(idx)
+}
+
+// dialOutbound establishes a TCP connection using the configured address family preference
+func (c *ConnectionManager) dialOutbound(
+	dialer net.Dialer,
+	address string,
+) (net.Conn, error) {
+	switch c.config.OutboundAddressFamily {
+	case AddressFamilyIPv4:
+		return dialer.Dial("tcp4", address)
+	case AddressFamilyIPv6:
+		return dialer.Dial("tcp6", address)
+	case AddressFamilyDual:
+		return dialDualStack(dialer, address)
+	default:
+		return dialer.Dial("tcp", address)
+	}
+}
+
+// dialDualStack concurrently dials the IPv4 and IPv6 addresses for a peer and returns the
+// first connection to succeed. This avoids waiting on a timeout for a broken address family
+func dialDualStack(dialer net.Dialer, address string) (net.Conn, error) {
+	type dialResult struct {
+		conn net.Conn
+		err  error
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), dialer.Timeout)
+	defer cancel()
+	networks := []string{"tcp6", "tcp4"}
+	resultChan := make(chan dialResult, len(networks))
+	for _, network := range networks {
+		go func(network string) {
+			conn, err := dialer.DialContext(ctx, network, address)
+			resultChan <- dialResult{conn: conn, err: err}
+		}(network)
+	}
+	var errs []error
+	for i := range networks {
+		result := <-resultChan
+		if result.err != nil {
+			errs = append(errs, result.err)
+			continue
+		}
+		// Close any other connections that succeed after the first
+		if remaining := len(networks) - i - 1; remaining > 0 {
+			go func() {
+				for range remaining {
+					if tmpResult := <-resultChan; tmpResult.conn != nil {
+						_ = tmpResult.conn.Close()
+					}
+				}
+			}()
+		}
+		return result.conn, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+// isSelfConnection returns whether an outbound connection is connected to one of our own listeners
+func (c *ConnectionManager) isSelfConnection(conn net.Conn) bool {
+	remoteAddr, ok := conn.RemoteAddr().(*net.TCPAddr)
+	if !ok {
+		return false
+	}
+	// A TCP simultaneous open can connect a socket to itself
+	if localAddr, ok := conn.LocalAddr().(*net.TCPAddr); ok &&
+		localAddr.IP.Equal(remoteAddr.IP) &&
+		localAddr.Port == remoteAddr.Port {
+		return true
+	}
+	c.listenersMutex.Lock()
+	listenAddrs := make([]net.Addr, 0, len(c.listeners))
+	for _, listener := range c.listeners {
+		listenAddrs = append(listenAddrs, listener.Addr())
+	}
+	c.listenersMutex.Unlock()
+	for _, tmpAddr := range listenAddrs {
+		listenAddr, ok := tmpAddr.(*net.TCPAddr)
+		if !ok || listenAddr.Port != remoteAddr.Port {
+			continue
+		}
+		if listenAddr.IP.IsUnspecified() {
+			if isLocalIP(remoteAddr.IP) {
+				return true
+			}
+			continue
+		}
+		if listenAddr.IP.Equal(remoteAddr.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLocalIP returns whether the IP address belongs to one of the local network interfaces
+func isLocalIP(ip net.IP) bool {
+	if ip.IsLoopback() {
+		return true
+	}
+	ifaceAddrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, ifaceAddr := range ifaceAddrs {
+		if ipNet, ok := ifaceAddr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"net"
+	"testing"
+)
+
+func TestIsSelfConnection(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error creating listener: %s", err)
+	}
+	defer listener.Close()
+	otherListener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error creating listener: %s", err)
+	}
+	defer otherListener.Close()
+	c := &ConnectionManager{
+		listeners: []net.Listener{listener},
+	}
+	selfConn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error dialing listener: %s", err)
+	}
+	defer selfConn.Close()
+	if !c.isSelfConnection(selfConn) {
+		t.Fatalf("expected connection to own listener to be detected")
+	}
+	otherConn, err := net.Dial("tcp", otherListener.Addr().String())
+	if err != nil {
+		t.Fatalf("unexpected error dialing listener: %s", err)
+	}
+	defer otherConn.Close()
+	if c.isSelfConnection(otherConn) {
+		t.Fatalf("did not expect connection to other listener to be detected")
+	}
+}
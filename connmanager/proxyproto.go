@@ -0,0 +1,178 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Maximum amount of time to wait for a client to send the PROXY protocol header
+	proxyHeaderTimeout = 5 * time.Second
+	// Maximum length of a PROXY protocol v1 header line, including the CRLF
+	proxyV1MaxLength = 107
+)
+
+var proxyV2Signature = []byte("\r\n\r\n\x00\r\nQUIT\n")
+
+// proxyProtoConn wraps a connection from a load balancer or reverse proxy, reporting the original client address
+// from the PROXY protocol header as the remote address
+type proxyProtoConn struct {
+	net.Conn
+	reader     *bufio.Reader
+	remoteAddr net.Addr
+}
+
+func (c *proxyProtoConn) Read(b []byte) (int, error) {
+	return c.reader.Read(b)
+}
+
+func (c *proxyProtoConn) RemoteAddr() net.Addr {
+	if c.remoteAddr != nil {
+		return c.remoteAddr
+	}
+	return c.Conn.RemoteAddr()
+}
+
+// newProxyProtoConn reads a PROXY protocol v1 or v2 header from the connection and returns a wrapped connection
+// that reports the original client address. An error is returned if a valid header is not received
+func newProxyProtoConn(conn net.Conn) (net.Conn, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout)); err != nil {
+		return nil, err
+	}
+	ret := &proxyProtoConn{
+		Conn:   conn,
+		reader: bufio.NewReader(conn),
+	}
+	sig, err := ret.reader.Peek(len(proxyV2Signature))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProxyHeader, err)
+	}
+	if bytes.Equal(sig, proxyV2Signature) {
+		ret.remoteAddr, err = readProxyV2Header(ret.reader)
+	} else {
+		ret.remoteAddr, err = readProxyV1Header(ret.reader)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProxyHeader, err)
+	}
+	if err := conn.SetReadDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// readProxyV1Header parses a human-readable PROXY protocol v1 header. A nil address is returned for the UNKNOWN protocol
+func readProxyV1Header(r *bufio.Reader) (net.Addr, error) {
+	var line []byte
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, b)
+		if b == '\n' {
+			break
+		}
+		if len(line) >= proxyV1MaxLength {
+			return nil, errors.New("v1 header too long")
+		}
+	}
+	if !bytes.HasSuffix(line, []byte("\r\n")) {
+		return nil, errors.New("v1 header not terminated by CRLF")
+	}
+	fields := strings.Split(string(line[:len(line)-2]), " ")
+	if fields[0] != "PROXY" || len(fields) < 2 {
+		return nil, errors.New("missing header")
+	}
+	switch fields[1] {
+	case "UNKNOWN":
+		return nil, nil
+	case "TCP4", "TCP6":
+	default:
+		return nil, fmt.Errorf("unsupported v1 protocol: %s", fields[1])
+	}
+	if len(fields) != 6 {
+		return nil, errors.New("malformed v1 header")
+	}
+	srcIP := net.ParseIP(fields[2])
+	if srcIP == nil {
+		return nil, fmt.Errorf("invalid v1 source address: %s", fields[2])
+	}
+	srcPort, err := strconv.ParseUint(fields[4], 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("invalid v1 source port: %s", fields[4])
+	}
+	return &net.TCPAddr{IP: srcIP, Port: int(srcPort)}, nil
+}
+
+// readProxyV2Header parses a binary PROXY protocol v2 header. A nil address is returned for LOCAL commands and
+// non-TCP address families
+func readProxyV2Header(r *bufio.Reader) (net.Addr, error) {
+	header := make([]byte, len(proxyV2Signature)+4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	verCmd := header[12]
+	famProto := header[13]
+	addrLen := binary.BigEndian.Uint16(header[14:16])
+	if verCmd>>4 != 2 {
+		return nil, fmt.Errorf("unsupported v2 version: %d", verCmd>>4)
+	}
+	addrData := make([]byte, addrLen)
+	if _, err := io.ReadFull(r, addrData); err != nil {
+		return nil, err
+	}
+	switch verCmd & 0x0f {
+	case 0x0:
+		// LOCAL command, such as a health check from the proxy itself
+		return nil, nil
+	case 0x1:
+		// PROXY command
+	default:
+		return nil, fmt.Errorf("unsupported v2 command: %d", verCmd&0x0f)
+	}
+	switch famProto {
+	case 0x11:
+		// TCP over IPv4
+		if len(addrData) < 12 {
+			return nil, errors.New("v2 address data too short")
+		}
+		return &net.TCPAddr{
+			IP:   net.IP(addrData[0:4]),
+			Port: int(binary.BigEndian.Uint16(addrData[8:10])),
+		}, nil
+	case 0x21:
+		// TCP over IPv6
+		if len(addrData) < 36 {
+			return nil, errors.New("v2 address data too short")
+		}
+		return &net.TCPAddr{
+			IP:   net.IP(addrData[0:16]),
+			Port: int(binary.BigEndian.Uint16(addrData[32:34])),
+		}, nil
+	default:
+		return nil, nil
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"testing"
+)
+
+func TestProxyProtoConn(t *testing.T) {
+	v2Header := func(cmd byte, famProto byte, addrData []byte) []byte {
+		ret := append([]byte{}, proxyV2Signature...)
+		ret = append(ret, 0x20|cmd, famProto)
+		ret = binary.BigEndian.AppendUint16(ret, uint16(len(addrData))) // #nosec G115
+		return append(ret, addrData...)
+	}
+	testDefs := []struct {
+		name       string
+		header     []byte
+		remoteAddr string
+		wantErr    bool
+	}{
+		{
+			name:       "v1 TCP4",
+			header:     []byte("PROXY TCP4 192.0.2.10 198.51.100.1 40000 3001\r\n"),
+			remoteAddr: "192.0.2.10:40000",
+		},
+		{
+			name:       "v1 TCP6",
+			header:     []byte("PROXY TCP6 2001:db8::10 2001:db8::1 40000 3001\r\n"),
+			remoteAddr: "[2001:db8::10]:40000",
+		},
+		{
+			name:   "v1 UNKNOWN",
+			header: []byte("PROXY UNKNOWN\r\n"),
+		},
+		{
+			name: "v2 TCP4",
+			header: v2Header(
+				0x1,
+				0x11,
+				[]byte{192, 0, 2, 10, 198, 51, 100, 1, 0x9c, 0x40, 0x0b, 0xb9},
+			),
+			remoteAddr: "192.0.2.10:40000",
+		},
+		{
+			name:   "v2 LOCAL",
+			header: v2Header(0x0, 0x00, nil),
+		},
+		{
+			name:    "missing header",
+			header:  []byte("not a proxy header\r\n"),
+			wantErr: true,
+		},
+	}
+	for _, testDef := range testDefs {
+		t.Run(testDef.name, func(t *testing.T) {
+			client, server := net.Pipe()
+			defer client.Close()
+			defer server.Close()
+			payload := []byte("payload")
+			go func() {
+				_, _ = client.Write(append(testDef.header, payload...))
+			}()
+			conn, err := newProxyProtoConn(server)
+			if testDef.wantErr {
+				if !errors.Is(err, ErrInvalidProxyHeader) {
+					t.Fatalf("expected invalid header error, got: %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			expectedAddr := testDef.remoteAddr
+			if expectedAddr == "" {
+				expectedAddr = server.RemoteAddr().String()
+			}
+			if conn.RemoteAddr().String() != expectedAddr {
+				t.Fatalf(
+					"did not get expected remote address: got %s, wanted %s",
+					conn.RemoteAddr().String(),
+					expectedAddr,
+				)
+			}
+			// Make sure data after the header is still readable
+			buf := make([]byte, len(payload))
+			if _, err := io.ReadFull(conn, buf); err != nil {
+				t.Fatalf("unexpected error reading payload: %s", err)
+			}
+			if !bytes.Equal(buf, payload) {
+				t.Fatalf("did not get expected payload: got %q", buf)
+			}
+		})
+	}
+}
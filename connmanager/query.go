@@ -0,0 +1,197 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"time"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/muxer"
+	"github.com/blinklabs-io/gouroboros/protocol"
+	"github.com/blinklabs-io/gouroboros/protocol/handshake"
+)
+
+const (
+	// handshakeMessageTypeQueryReply is the handshake message type used to answer a version query
+	handshakeMessageTypeQueryReply = 3
+
+	peerVersionQueryTimeout = 10 * time.Second
+	// Limit how much data we accept from a peer while waiting for the query reply
+	peerVersionQueryMaxReplySize = 4 * muxer.SegmentMaxPayloadLength
+)
+
+// NodeToNodeVersionData contains the version data reported by a peer for a node-to-node protocol version
+type NodeToNodeVersionData struct {
+	NetworkMagic  uint32
+	DiffusionMode bool
+	PeerSharing   bool
+}
+
+// QueryPeerVersions performs a handshake in query mode against the specified address and returns the NtN protocol
+// versions supported by the peer along with the version data for the highest version. No mini-protocols are started,
+// and the connection is closed once the peer replies. Peers that don't support query mode will accept a version
+// instead, in which case only that version is returned
+func (c *ConnectionManager) QueryPeerVersions(
+	address string,
+) ([]uint, NodeToNodeVersionData, error) {
+	dialer := net.Dialer{
+		Timeout: peerVersionQueryTimeout,
+	}
+	conn, err := c.dialOutbound(dialer, address)
+	if err != nil {
+		return nil, NodeToNodeVersionData{}, fmt.Errorf("%w: %w", ErrDial, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(peerVersionQueryTimeout)); err != nil {
+		return nil, NodeToNodeVersionData{}, err
+	}
+	// Propose all of our versions with query mode enabled
+	msg := handshake.NewMsgProposeVersions(
+		protocol.GetProtocolVersionMap(
+			protocol.ProtocolModeNodeToNode,
+			c.config.NetworkMagic,
+			false,
+			false,
+			protocol.QueryModeEnabled,
+		),
+	)
+	msgCbor, err := cbor.Encode(msg)
+	if err != nil {
+		return nil, NodeToNodeVersionData{}, err
+	}
+	segment := muxer.NewSegment(handshake.ProtocolId, msgCbor, false)
+	if segment == nil {
+		return nil, NodeToNodeVersionData{}, errors.New("handshake propose message too large")
+	}
+	if err := writeSegment(conn, segment); err != nil {
+		return nil, NodeToNodeVersionData{}, fmt.Errorf("%w: %w", ErrHandshake, err)
+	}
+	reply, err := readHandshakeReply(conn)
+	if err != nil {
+		return nil, NodeToNodeVersionData{}, fmt.Errorf("%w: %w", ErrHandshake, err)
+	}
+	return parseHandshakeReply(reply)
+}
+
+func writeSegment(w io.Writer, segment *muxer.Segment) error {
+	buf := bytes.NewBuffer(nil)
+	if err := binary.Write(buf, binary.BigEndian, segment.SegmentHeader); err != nil {
+		return err
+	}
+	buf.Write(segment.Payload)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// readHandshakeReply reads handshake segments until they contain a complete message, and returns the message
+// decoded as a list
+func readHandshakeReply(r io.Reader) ([]cbor.RawMessage, error) {
+	var payload []byte
+	for {
+		var header muxer.SegmentHeader
+		if err := binary.Read(r, binary.BigEndian, &header); err != nil {
+			return nil, err
+		}
+		segmentPayload := make([]byte, header.PayloadLength)
+		if _, err := io.ReadFull(r, segmentPayload); err != nil {
+			return nil, err
+		}
+		if header.GetProtocolId() != handshake.ProtocolId {
+			continue
+		}
+		payload = append(payload, segmentPayload...)
+		var reply []cbor.RawMessage
+		if _, err := cbor.Decode(payload, &reply); err == nil {
+			return reply, nil
+		}
+		if len(payload) > peerVersionQueryMaxReplySize {
+			return nil, errors.New("handshake reply too large")
+		}
+	}
+}
+
+func parseHandshakeReply(
+	reply []cbor.RawMessage,
+) ([]uint, NodeToNodeVersionData, error) {
+	if len(reply) < 2 {
+		return nil, NodeToNodeVersionData{}, fmt.Errorf("%w: malformed handshake reply", ErrHandshake)
+	}
+	var msgType uint
+	if _, err := cbor.Decode(reply[0], &msgType); err != nil {
+		return nil, NodeToNodeVersionData{}, fmt.Errorf("%w: %w", ErrHandshake, err)
+	}
+	versionMap := map[uint16]cbor.RawMessage{}
+	switch msgType {
+	case handshakeMessageTypeQueryReply:
+		if _, err := cbor.Decode(reply[1], &versionMap); err != nil {
+			return nil, NodeToNodeVersionData{}, fmt.Errorf("%w: %w", ErrHandshake, err)
+		}
+	case handshake.MessageTypeAcceptVersion:
+		if len(reply) < 3 {
+			return nil, NodeToNodeVersionData{}, fmt.Errorf("%w: malformed handshake reply", ErrHandshake)
+		}
+		var version uint16
+		if _, err := cbor.Decode(reply[1], &version); err != nil {
+			return nil, NodeToNodeVersionData{}, fmt.Errorf("%w: %w", ErrHandshake, err)
+		}
+		versionMap[version] = reply[2]
+	case handshake.MessageTypeRefuse:
+		var reason []any
+		if _, err := cbor.Decode(reply[1], &reason); err != nil {
+			return nil, NodeToNodeVersionData{}, fmt.Errorf("%w: %w", ErrHandshake, err)
+		}
+		return nil, NodeToNodeVersionData{}, fmt.Errorf("%w: peer refused: %v", ErrHandshake, reason)
+	default:
+		return nil, NodeToNodeVersionData{}, fmt.Errorf("%w: unexpected handshake message type %d", ErrHandshake, msgType)
+	}
+	versions := make([]uint, 0, len(versionMap))
+	for version := range versionMap {
+		versions = append(versions, uint(version))
+	}
+	slices.Sort(versions)
+	// Use the version data from the highest version that we know how to decode
+	var versionData NodeToNodeVersionData
+	for i := len(versions) - 1; i >= 0; i-- {
+		// #nosec G115
+		version := uint16(versions[i])
+		protoVersion := protocol.GetProtocolVersion(version)
+		if protoVersion.NewVersionDataFromCborFunc == nil {
+			continue
+		}
+		tmpVersionData, err := protoVersion.NewVersionDataFromCborFunc(versionMap[version])
+		if err != nil {
+			return nil, NodeToNodeVersionData{}, fmt.Errorf(
+				"%w: failed to decode version data for version %d: %w",
+				ErrHandshake,
+				version,
+				err,
+			)
+		}
+		versionData = NodeToNodeVersionData{
+			NetworkMagic:  tmpVersionData.NetworkMagic(),
+			DiffusionMode: tmpVersionData.DiffusionMode(),
+			PeerSharing:   tmpVersionData.PeerSharing(),
+		}
+		break
+	}
+	return versions, versionData, nil
+}
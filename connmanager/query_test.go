@@ -0,0 +1,96 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/muxer"
+	"github.com/blinklabs-io/gouroboros/protocol"
+	"github.com/blinklabs-io/gouroboros/protocol/handshake"
+)
+
+func TestQueryPeerVersions(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error creating listener: %s", err)
+	}
+	defer listener.Close()
+	versionData := protocol.VersionDataNtN13andUp{
+		VersionDataNtN11to12: protocol.VersionDataNtN11to12{
+			CborNetworkMagic: 2,
+			CborPeerSharing:  protocol.PeerSharingModePeerSharingPublic,
+			CborQuery:        true,
+		},
+	}
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Make sure we were sent a version proposal with query mode enabled
+		propose, err := readHandshakeReply(conn)
+		if err != nil || len(propose) != 2 {
+			return
+		}
+		var proposedVersions map[uint16]cbor.RawMessage
+		if _, err := cbor.Decode(propose[1], &proposedVersions); err != nil {
+			return
+		}
+		var proposedVersionData protocol.VersionDataNtN13andUp
+		if _, err := cbor.Decode(proposedVersions[14], &proposedVersionData); err != nil ||
+			!proposedVersionData.CborQuery {
+			return
+		}
+		reply, _ := cbor.Encode(
+			[]any{
+				handshakeMessageTypeQueryReply,
+				map[uint16]any{
+					13: versionData,
+					14: versionData,
+				},
+			},
+		)
+		_ = writeSegment(
+			conn,
+			muxer.NewSegment(handshake.ProtocolId, reply, true),
+		)
+	}()
+	c := NewConnectionManager(ConnectionManagerConfig{NetworkMagic: 2})
+	versions, peerVersionData, err := c.QueryPeerVersions(
+		listener.Addr().String(),
+	)
+	if err != nil {
+		t.Fatalf("unexpected error querying peer versions: %s", err)
+	}
+	if !slices.Equal(versions, []uint{13, 14}) {
+		t.Fatalf("did not get expected versions: got %v", versions)
+	}
+	expectedVersionData := NodeToNodeVersionData{
+		NetworkMagic: 2,
+		PeerSharing:  true,
+	}
+	if peerVersionData != expectedVersionData {
+		t.Fatalf(
+			"did not get expected version data:\n  got:    %#v\n  wanted: %#v",
+			peerVersionData,
+			expectedVersionData,
+		)
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package connmanager
+
+import (
+	"syscall"
+)
+
+// socketReusePortSupported indicates whether SO_REUSEPORT is available on this platform
+const socketReusePortSupported = false
+
+// socketControl is a no-op on platforms without SO_REUSEPORT support. Binding an outbound connection to our
+// listening port will generally fail on these platforms, so outbound source port binding is best avoided
+func socketControl(network, address string, c syscall.RawConn) error {
+	return nil
+}
@@ -12,14 +12,20 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
 package connmanager
 
 import (
+	"fmt"
 	"syscall"
 
 	"golang.org/x/sys/unix"
 )
 
+// socketReusePortSupported indicates whether SO_REUSEPORT is available on this platform
+const socketReusePortSupported = true
+
 // socketControl is a helper function for setting socket options on outbound and listener sockets
 func socketControl(network, address string, c syscall.RawConn) error {
 	var innerErr error
@@ -46,10 +52,10 @@ func socketControl(network, address string, c syscall.RawConn) error {
 		}
 	})
 	if innerErr != nil {
-		return innerErr
+		return fmt.Errorf("%w: %w", ErrSocketControl, innerErr)
 	}
 	if err != nil {
-		return err
+		return fmt.Errorf("%w: %w", ErrSocketControl, err)
 	}
 	return nil
 }
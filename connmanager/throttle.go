@@ -0,0 +1,195 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// bandwidthLimiter is a token bucket rate limiter shared by multiple connections. Callers reserve tokens for the
+// bytes they transfer and wait until the bucket catches up, which keeps the combined rate at or below the limit
+type bandwidthLimiter struct {
+	mu          sync.Mutex
+	bytesPerSec float64
+	tokens      float64
+	lastUpdate  time.Time
+}
+
+func newBandwidthLimiter(bytesPerSec uint64) *bandwidthLimiter {
+	return &bandwidthLimiter{
+		bytesPerSec: float64(bytesPerSec),
+		// Start with a full bucket
+		tokens:     float64(bytesPerSec),
+		lastUpdate: time.Now(),
+	}
+}
+
+// maxChunk returns the largest number of bytes to transfer at once, which is one second worth of data
+func (l *bandwidthLimiter) maxChunk() int {
+	return max(int(l.bytesPerSec), 1)
+}
+
+// reserve takes the specified number of bytes from the bucket and returns how long the caller must wait before
+// transferring them
+func (l *bandwidthLimiter) reserve(n int) time.Duration {
+	if n <= 0 {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := time.Now()
+	// Refill the bucket for the elapsed time, holding at most one second worth of data
+	l.tokens = min(
+		l.tokens+now.Sub(l.lastUpdate).Seconds()*l.bytesPerSec,
+		l.bytesPerSec,
+	)
+	l.lastUpdate = now
+	// The bucket can go negative, which delays later callers until the debt is paid off
+	l.tokens -= float64(n)
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.bytesPerSec * float64(time.Second))
+}
+
+// throttledConn wraps a net.Conn and limits its reads and writes using bandwidth limiters shared with other
+// connections. Waiting for the limiter is interrupted by the read/write deadlines and by closing the connection
+type throttledConn struct {
+	net.Conn
+	readLimiter   *bandwidthLimiter
+	writeLimiter  *bandwidthLimiter
+	mu            sync.Mutex
+	readDeadline  time.Time
+	writeDeadline time.Time
+	closeChan     chan struct{}
+	closeOnce     sync.Once
+}
+
+func newThrottledConn(
+	conn net.Conn,
+	readLimiter *bandwidthLimiter,
+	writeLimiter *bandwidthLimiter,
+) *throttledConn {
+	return &throttledConn{
+		Conn:         conn,
+		readLimiter:  readLimiter,
+		writeLimiter: writeLimiter,
+		closeChan:    make(chan struct{}),
+	}
+}
+
+func (c *throttledConn) Read(b []byte) (int, error) {
+	// Limit the read size so that a single read can't exceed the limit by more than one second worth of data
+	if len(b) > c.readLimiter.maxChunk() {
+		b = b[:c.readLimiter.maxChunk()]
+	}
+	n, err := c.Conn.Read(b)
+	if waitErr := c.wait(c.readLimiter.reserve(n), c.deadline(true)); waitErr != nil && err == nil {
+		err = waitErr
+	}
+	return n, err
+}
+
+func (c *throttledConn) Write(b []byte) (int, error) {
+	var written int
+	for len(b) > 0 {
+		chunk := b[:min(len(b), c.writeLimiter.maxChunk())]
+		if err := c.wait(c.writeLimiter.reserve(len(chunk)), c.deadline(false)); err != nil {
+			return written, err
+		}
+		n, err := c.Conn.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		b = b[n:]
+	}
+	return written, nil
+}
+
+func (c *throttledConn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.closeChan)
+	})
+	return c.Conn.Close()
+}
+
+func (c *throttledConn) SetDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline = t
+	c.writeDeadline = t
+	c.mu.Unlock()
+	return c.Conn.SetDeadline(t)
+}
+
+func (c *throttledConn) SetReadDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.readDeadline = t
+	c.mu.Unlock()
+	return c.Conn.SetReadDeadline(t)
+}
+
+func (c *throttledConn) SetWriteDeadline(t time.Time) error {
+	c.mu.Lock()
+	c.writeDeadline = t
+	c.mu.Unlock()
+	return c.Conn.SetWriteDeadline(t)
+}
+
+func (c *throttledConn) deadline(read bool) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if read {
+		return c.readDeadline
+	}
+	return c.writeDeadline
+}
+
+// wait blocks for the specified delay. It returns early with an error if the connection is closed or the deadline
+// passes first
+func (c *throttledConn) wait(delay time.Duration, deadline time.Time) error {
+	if delay <= 0 {
+		return nil
+	}
+	var deadlineExceeded bool
+	if !deadline.IsZero() {
+		if untilDeadline := time.Until(deadline); untilDeadline < delay {
+			delay = untilDeadline
+			deadlineExceeded = true
+		}
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-c.closeChan:
+		return net.ErrClosed
+	case <-timer.C:
+		if deadlineExceeded {
+			return os.ErrDeadlineExceeded
+		}
+		return nil
+	}
+}
+
+// throttleConn wraps the connection to enforce the bandwidth limit, if any
+func (c *ConnectionManager) throttleConn(conn net.Conn) net.Conn {
+	if c.readLimiter == nil || c.writeLimiter == nil {
+		return conn
+	}
+	return newThrottledConn(conn, c.readLimiter, c.writeLimiter)
+}
@@ -0,0 +1,130 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package connmanager
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestThrottledConnSharedLimit(t *testing.T) {
+	const bandwidthLimit = 10000
+	c := NewConnectionManager(
+		ConnectionManagerConfig{
+			BandwidthLimit: bandwidthLimit,
+		},
+	)
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 2 {
+		clientConn, serverConn := net.Pipe()
+		defer clientConn.Close()
+		defer serverConn.Close()
+		go func() {
+			_, _ = io.Copy(io.Discard, serverConn)
+		}()
+		conn := c.throttleConn(clientConn)
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := conn.Write(make([]byte, bandwidthLimit)); err != nil {
+				t.Errorf("unexpected error writing to connection: %s", err)
+			}
+		}()
+	}
+	wg.Wait()
+	// The first second worth of data is allowed immediately, and the rest is throttled
+	elapsed := time.Since(start)
+	if elapsed < 900*time.Millisecond {
+		t.Fatalf("writes were not throttled across connections: took %s", elapsed)
+	}
+	if elapsed > 3*time.Second {
+		t.Fatalf("writes were throttled too much: took %s", elapsed)
+	}
+}
+
+func TestThrottleConnDisabled(t *testing.T) {
+	c := NewConnectionManager(ConnectionManagerConfig{})
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+	if conn := c.throttleConn(clientConn); conn != clientConn {
+		t.Fatalf("connection was wrapped with no bandwidth limit")
+	}
+}
+
+// newTestThrottledConn returns a throttled connection that has already used up one second worth of its write limit
+func newTestThrottledConn(t *testing.T, bandwidthLimit uint64) net.Conn {
+	c := NewConnectionManager(
+		ConnectionManagerConfig{
+			BandwidthLimit: bandwidthLimit,
+		},
+	)
+	clientConn, serverConn := net.Pipe()
+	t.Cleanup(func() {
+		clientConn.Close()
+		serverConn.Close()
+	})
+	go func() {
+		_, _ = io.Copy(io.Discard, serverConn)
+	}()
+	conn := c.throttleConn(clientConn)
+	if _, err := conn.Write(make([]byte, bandwidthLimit)); err != nil {
+		t.Fatalf("unexpected error writing to connection: %s", err)
+	}
+	return conn
+}
+
+func TestThrottledConnCloseInterruptsWait(t *testing.T) {
+	const bandwidthLimit = 1000
+	conn := newTestThrottledConn(t, bandwidthLimit)
+	errChan := make(chan error, 1)
+	go func() {
+		// This would take 10s to get through the limiter
+		_, err := conn.Write(make([]byte, 10*bandwidthLimit))
+		errChan <- err
+	}()
+	time.Sleep(100 * time.Millisecond)
+	conn.Close()
+	select {
+	case err := <-errChan:
+		if !errors.Is(err, net.ErrClosed) {
+			t.Fatalf("did not get expected error: got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("write was not interrupted by closing the connection")
+	}
+}
+
+func TestThrottledConnWriteDeadline(t *testing.T) {
+	const bandwidthLimit = 1000
+	conn := newTestThrottledConn(t, bandwidthLimit)
+	if err := conn.SetWriteDeadline(time.Now().Add(200 * time.Millisecond)); err != nil {
+		t.Fatalf("unexpected error setting write deadline: %s", err)
+	}
+	start := time.Now()
+	_, err := conn.Write(make([]byte, 10*bandwidthLimit))
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Fatalf("did not get expected error: got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("write deadline was not respected: took %s", elapsed)
+	}
+}
@@ -49,6 +49,23 @@ func (d *Database) GetAccount(
 	return tmpAccount, nil
 }
 
+// GetDelegatedAccounts returns all active accounts that are delegated to a pool
+func (d *Database) GetDelegatedAccounts(txn *Txn) ([]Account, error) {
+	if txn == nil {
+		txn = d.Transaction(false)
+		defer txn.Commit() //nolint:errcheck
+	}
+	accounts, err := d.metadata.GetDelegatedAccounts(txn.Metadata())
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Account, 0, len(accounts))
+	for _, account := range accounts {
+		ret = append(ret, Account(account))
+	}
+	return ret, nil
+}
+
 // SetAccount saves an account
 func (d *Database) SetAccount(
 	stakeKey, pkh, drep []byte,
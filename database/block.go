@@ -15,6 +15,7 @@
 package database
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 	"math/big"
@@ -33,9 +34,15 @@ const (
 	blockBlobKeyPrefix         = "bp"
 	blockBlobIndexKeyPrefix    = "bi"
 	blockBlobMetadataKeySuffix = "_metadata"
+
+	// Badger user metadata flag set on block content keys whose content has been pruned
+	blockBlobPrunedUserMeta byte = 0x01
 )
 
-var ErrBlockNotFound = errors.New("block not found")
+var (
+	ErrBlockNotFound = errors.New("block not found")
+	ErrBlockPruned   = errors.New("block has been pruned")
+)
 
 type Block struct {
 	ID       uint64
@@ -45,9 +52,14 @@ type Block struct {
 	Type     uint
 	PrevHash []byte
 	Cbor     []byte
+	// Pruned is set when the block content has been removed from the block store
+	Pruned bool
 }
 
 func (b Block) Decode() (ledger.Block, error) {
+	if b.Pruned {
+		return nil, ErrBlockPruned
+	}
 	return ledger.NewBlockFromCbor(b.Type, b.Cbor)
 }
 
@@ -137,6 +149,7 @@ func blockByKey(txn *Txn, blockKey []byte) (Block, error) {
 		}
 		return ret, err
 	}
+	ret.Pruned = item.UserMeta() == blockBlobPrunedUserMeta
 	ret.Cbor, err = item.ValueCopy(nil)
 	if err != nil {
 		return ret, err
@@ -305,6 +318,54 @@ func BlocksAfterSlotTxn(txn *Txn, slotNumber uint64) ([]Block, error) {
 	return ret, nil
 }
 
+// BlocksPruneCborBeforeSlotTxn removes the CBOR content of up to limit blocks with a slot from startSlot up to but not
+// including endSlot. The block metadata and index are kept, so the blocks can still be found for intersection. It
+// returns the number of blocks pruned and the slot to resume pruning from in the next batch
+func BlocksPruneCborBeforeSlotTxn(
+	txn *Txn,
+	startSlot uint64,
+	endSlot uint64,
+	limit int,
+) (int, uint64, error) {
+	iterOpts := badger.IteratorOptions{}
+	it := txn.Blob().NewIterator(iterOpts)
+	startKey := slices.Concat(
+		[]byte(blockBlobKeyPrefix),
+		blockBlobKeyUint64ToBytes(startSlot),
+	)
+	endKey := slices.Concat(
+		[]byte(blockBlobKeyPrefix),
+		blockBlobKeyUint64ToBytes(endSlot),
+	)
+	var pruneKeys [][]byte
+	for it.Seek(startKey); it.ValidForPrefix([]byte(blockBlobKeyPrefix)); it.Next() {
+		item := it.Item()
+		k := item.KeyCopy(nil)
+		if bytes.Compare(k, endKey) >= 0 {
+			break
+		}
+		// Skip the metadata key and already pruned blocks
+		if strings.HasSuffix(string(k), blockBlobMetadataKeySuffix) ||
+			item.UserMeta() == blockBlobPrunedUserMeta {
+			continue
+		}
+		pruneKeys = append(pruneKeys, k)
+		if len(pruneKeys) >= limit {
+			break
+		}
+	}
+	it.Close()
+	nextSlot := startSlot
+	for _, k := range pruneKeys {
+		entry := badger.NewEntry(k, []byte{}).WithMeta(blockBlobPrunedUserMeta)
+		if err := txn.Blob().SetEntry(entry); err != nil {
+			return 0, startSlot, err
+		}
+		nextSlot = blockBlobKeyToPoint(k).Slot
+	}
+	return len(pruneKeys), nextSlot, nil
+}
+
 func blockBlobKeyUint64ToBytes(input uint64) []byte {
 	ret := make([]byte, 8)
 	new(big.Int).SetUint64(input).FillBytes(ret)
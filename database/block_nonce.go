@@ -62,24 +62,26 @@ func (d *Database) GetBlockNonce(
 	return d.metadata.GetBlockNonce(blockHash, slotNumber, txn.Metadata())
 }
 
-// DeleteBlockNoncesBeforeSlot removes all block_nonces older than the given slot number
+// DeleteBlockNoncesBeforeSlot removes all block_nonces older than the given slot number and returns the number removed
 func (d *Database) DeleteBlockNoncesBeforeSlot(
 	slotNumber uint64,
 	txn *Txn,
-) error {
+) (int, error) {
 	if txn == nil {
 		return d.metadata.DeleteBlockNoncesBeforeSlot(slotNumber, nil)
 	}
 	return d.metadata.DeleteBlockNoncesBeforeSlot(slotNumber, txn.Metadata())
 }
 
-// DeleteBlockNoncesBeforeSlotWithoutCheckpoints removes non-checkpoint block_nonces older than the given slot number
+// DeleteBlockNoncesBeforeSlotWithoutCheckpoints removes up to limit non-checkpoint block_nonces older than the given
+// slot number and returns the number removed
 func (d *Database) DeleteBlockNoncesBeforeSlotWithoutCheckpoints(
 	slotNumber uint64,
+	limit int,
 	txn *Txn,
-) error {
+) (int, error) {
 	if txn == nil {
-		return d.metadata.DeleteBlockNoncesBeforeSlotWithoutCheckpoints(slotNumber, nil)
+		return d.metadata.DeleteBlockNoncesBeforeSlotWithoutCheckpoints(slotNumber, limit, nil)
 	}
-	return d.metadata.DeleteBlockNoncesBeforeSlotWithoutCheckpoints(slotNumber, txn.Metadata())
+	return d.metadata.DeleteBlockNoncesBeforeSlotWithoutCheckpoints(slotNumber, limit, txn.Metadata())
 }
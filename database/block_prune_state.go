@@ -0,0 +1,31 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+// GetBlockPrunedSlot returns the slot before which block bodies have been pruned from the block store
+func (d *Database) GetBlockPrunedSlot(txn *Txn) (uint64, error) {
+	if txn == nil {
+		return d.metadata.GetBlockPrunedSlot(nil)
+	}
+	return d.metadata.GetBlockPrunedSlot(txn.Metadata())
+}
+
+// SetBlockPrunedSlot saves the slot before which block bodies have been pruned from the block store
+func (d *Database) SetBlockPrunedSlot(slot uint64, txn *Txn) error {
+	if txn == nil {
+		return d.metadata.SetBlockPrunedSlot(slot, nil)
+	}
+	return d.metadata.SetBlockPrunedSlot(slot, txn.Metadata())
+}
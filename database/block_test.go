@@ -0,0 +1,124 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+func TestBlocksPruneCborBeforeSlot(t *testing.T) {
+	const testCacheSize int64 = 1 << 20
+	db, err := database.New(nil, nil, "", testCacheSize) // in-memory
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+	testBlocks := []database.Block{
+		{ID: 1, Slot: 10, Number: 1, Hash: bytes.Repeat([]byte{0x01}, 32), Cbor: []byte{0xa1}},
+		{ID: 2, Slot: 20, Number: 2, Hash: bytes.Repeat([]byte{0x02}, 32), Cbor: []byte{0xa2}},
+		{ID: 3, Slot: 30, Number: 3, Hash: bytes.Repeat([]byte{0x03}, 32), Cbor: []byte{0xa3}},
+	}
+	for _, block := range testBlocks {
+		if err := db.BlockCreate(block, nil); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	// Prune in batches of 1 to exercise the limit
+	var pruned int
+	var startSlot uint64
+	for {
+		txn := db.BlobTxn(true)
+		var count int
+		err := txn.Do(func(txn *database.Txn) error {
+			var err error
+			count, startSlot, err = database.BlocksPruneCborBeforeSlotTxn(
+				txn,
+				startSlot,
+				30,
+				1,
+			)
+			return err
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if count == 0 {
+			break
+		}
+		pruned += count
+	}
+	if startSlot != 20 {
+		t.Fatalf("did not get expected resume slot: got %d, wanted 20", startSlot)
+	}
+	if pruned != 2 {
+		t.Fatalf("did not get expected pruned count: got %d, wanted 2", pruned)
+	}
+	for _, testBlock := range testBlocks {
+		block, err := database.BlockByPoint(
+			db,
+			ocommon.NewPoint(testBlock.Slot, testBlock.Hash),
+		)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if block.ID != testBlock.ID || block.Number != testBlock.Number {
+			t.Fatalf("block metadata was not retained for slot %d", testBlock.Slot)
+		}
+		if testBlock.Slot < 30 {
+			if !block.Pruned {
+				t.Fatalf("expected block at slot %d to be pruned", testBlock.Slot)
+			}
+			if _, err := block.Decode(); !errors.Is(err, database.ErrBlockPruned) {
+				t.Fatalf("did not get expected error decoding pruned block: %v", err)
+			}
+		}
+		if testBlock.Slot >= 30 && block.Pruned {
+			t.Fatalf("expected block at slot %d to be retained", testBlock.Slot)
+		}
+	}
+}
+
+func TestBlockPrunedSlot(t *testing.T) {
+	const testCacheSize int64 = 1 << 20
+	db, err := database.New(nil, nil, "", testCacheSize) // in-memory
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+	prunedSlot, err := db.GetBlockPrunedSlot(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if prunedSlot != 0 {
+		t.Fatalf("did not get expected initial pruned slot: got %d, wanted 0", prunedSlot)
+	}
+	for _, testSlot := range []uint64{1000, 2000} {
+		if err := db.SetBlockPrunedSlot(testSlot, nil); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		prunedSlot, err := db.GetBlockPrunedSlot(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if prunedSlot != testSlot {
+			t.Fatalf("did not get expected pruned slot: got %d, wanted %d", prunedSlot, testSlot)
+		}
+	}
+}
@@ -27,12 +27,21 @@ type CommitTimestampError struct {
 // Error returns the stringified error
 func (e CommitTimestampError) Error() string {
 	return fmt.Sprintf(
-		"commit timestamp mismatch: %d (metadata) != %d (blob)",
+		"commit timestamp mismatch: %d (metadata) != %d (blob), %s store is ahead",
 		e.MetadataTimestamp,
 		e.BlobTimestamp,
+		e.AheadStore(),
 	)
 }
 
+// AheadStore returns the name of the store with the most recent commit timestamp
+func (e CommitTimestampError) AheadStore() string {
+	if e.MetadataTimestamp > e.BlobTimestamp {
+		return "metadata"
+	}
+	return "blob"
+}
+
 func (b *Database) checkCommitTimestamp() error {
 	// Get value from metadata
 	metadataTimestamp, metadataErr := b.Metadata().GetCommitTimestamp()
@@ -101,7 +101,13 @@ func New(
 ) (*Database, error) {
 	metadataDb, err := metadata.New("sqlite", dataDir, logger, promRegistry)
 	if err != nil {
-		return nil, err
+		if metadataDb == nil {
+			return nil, err
+		}
+		// The metadata store is available for recovery
+		if err := recoverMetadata(logger, metadataDb, err); err != nil {
+			return nil, err
+		}
 	}
 	blobDb, err := blob.New("badger", dataDir, logger, promRegistry, badgerCacheSize)
 	if err != nil {
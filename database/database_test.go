@@ -15,6 +15,10 @@
 package database_test
 
 import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -61,3 +65,36 @@ func TestInMemorySqliteMultipleTransaction(t *testing.T) {
 		t.Fatalf("unexpected error: %s", err)
 	}
 }
+
+func TestMetadataIntegrityRecoverAndReset(t *testing.T) {
+	const testCacheSize int64 = 1 << 20
+	dataDir := t.TempDir()
+	db, err := database.New(nil, nil, dataDir, testCacheSize)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	problems, err := db.Metadata().IntegrityCheck()
+	if err != nil {
+		t.Fatalf("unexpected error running integrity check: %s", err)
+	}
+	if len(problems) > 0 {
+		t.Fatalf("unexpected integrity check problems: %v", problems)
+	}
+	// Recovery steps should succeed against a healthy database
+	if err := db.Metadata().Recover(); err != nil {
+		t.Fatalf("unexpected error recovering metadata store: %s", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := database.ResetMetadata(dataDir, nil); err != nil {
+		t.Fatalf("unexpected error resetting metadata store: %s", err)
+	}
+	if _, err := os.Stat(filepath.Join(dataDir, "metadata.sqlite")); !errors.Is(err, fs.ErrNotExist) {
+		t.Fatalf("expected metadata store to be moved aside, got: %v", err)
+	}
+	matches, err := filepath.Glob(filepath.Join(dataDir, "metadata.sqlite.corrupt-*"))
+	if err != nil || len(matches) == 0 {
+		t.Fatalf("expected old metadata store to be kept")
+	}
+}
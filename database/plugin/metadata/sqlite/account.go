@@ -41,6 +41,24 @@ func (d *MetadataStoreSqlite) GetAccount(
 	return ret, nil
 }
 
+// GetDelegatedAccounts returns all active accounts that are delegated to a pool
+func (d *MetadataStoreSqlite) GetDelegatedAccounts(
+	txn *gorm.DB,
+) ([]models.Account, error) {
+	var ret []models.Account
+	db := txn
+	if db == nil {
+		db = d.DB()
+	}
+	result := db.
+		Where("active = ? AND pool IS NOT NULL AND length(pool) > 0", true).
+		Find(&ret)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ret, nil
+}
+
 // SetAccount saves an account
 func (d *MetadataStoreSqlite) SetAccount(
 	stakeKey, pkh, drep []byte,
@@ -75,41 +75,47 @@ func (d *MetadataStoreSqlite) GetBlockNonce(
 	return ret.Nonce, nil
 }
 
-// DeleteBlockNoncesBeforeSlot deletes block_nonce records with slot_number less than the specified value
+// DeleteBlockNoncesBeforeSlot deletes block_nonce records with slot less than the specified value
 func (d *MetadataStoreSqlite) DeleteBlockNoncesBeforeSlot(
 	slotNumber uint64,
 	txn *gorm.DB,
-) error {
+) (int, error) {
 	var result *gorm.DB
 	if txn != nil {
 		result = txn.
-			Where("slot_number < ?", slotNumber).
+			Where("slot < ?", slotNumber).
 			Delete(&models.BlockNonce{})
 	} else {
 		result = d.DB().
-			Where("slot_number < ?", slotNumber).
+			Where("slot < ?", slotNumber).
 			Delete(&models.BlockNonce{})
 	}
 
 	if result.Error != nil {
-		return result.Error
+		return 0, result.Error
 	}
 
-	return nil
+	return int(result.RowsAffected), nil
 }
 
-// DeleteBlockNoncesBeforeSlotWithoutCheckpoints deletes block_nonce records with slot_number < given value AND is_checkpoint = false
+// DeleteBlockNoncesBeforeSlotWithoutCheckpoints deletes up to limit block_nonce records with slot < given value AND
+// is_checkpoint = false
 func (d *MetadataStoreSqlite) DeleteBlockNoncesBeforeSlotWithoutCheckpoints(
 	slotNumber uint64,
+	limit int,
 	txn *gorm.DB,
-) error {
+) (int, error) {
 	db := txn
 	if db == nil {
 		db = d.DB()
 	}
-	result := db.
-		Where("slot_number < ? AND is_checkpoint = ?", slotNumber, false).
-		Delete(&models.BlockNonce{})
+	result := db.Where(
+		"id IN (?)",
+		db.Model(&models.BlockNonce{}).
+			Select("id").
+			Where("slot < ? AND is_checkpoint = ?", slotNumber, false).
+			Limit(limit),
+	).Delete(&models.BlockNonce{})
 
-	return result.Error
+	return int(result.RowsAffected), result.Error
 }
@@ -0,0 +1,63 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"errors"
+
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	blockPruneStateEntryId = 1
+)
+
+// GetBlockPrunedSlot returns the slot before which block bodies have been pruned, or 0 if no blocks have been pruned
+func (d *MetadataStoreSqlite) GetBlockPrunedSlot(
+	txn *gorm.DB,
+) (uint64, error) {
+	if txn == nil {
+		txn = d.DB()
+	}
+	tmpState := models.BlockPruneState{}
+	result := txn.Where("id = ?", blockPruneStateEntryId).First(&tmpState)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, result.Error
+	}
+	return tmpState.Slot, nil
+}
+
+// SetBlockPrunedSlot saves the slot before which block bodies have been pruned
+func (d *MetadataStoreSqlite) SetBlockPrunedSlot(
+	slot uint64,
+	txn *gorm.DB,
+) error {
+	if txn == nil {
+		txn = d.DB()
+	}
+	tmpState := models.BlockPruneState{
+		ID:   blockPruneStateEntryId,
+		Slot: slot,
+	}
+	if result := txn.Clauses(clause.OnConflict{UpdateAll: true}).Create(&tmpState); result.Error != nil {
+		return result.Error
+	}
+	return nil
+}
@@ -15,6 +15,7 @@
 package sqlite
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -33,6 +34,9 @@ import (
 	"gorm.io/plugin/opentelemetry/tracing"
 )
 
+// Value reported by PRAGMA auto_vacuum for incremental auto-vacuum
+const sqliteAutoVacuumIncremental = 2
+
 // Register plugin
 func init() {
 	plugin.Register(
@@ -47,9 +51,27 @@ func init() {
 type MetadataStoreSqlite struct {
 	dataDir      string
 	db           *gorm.DB
+	readDb       *gorm.DB
 	logger       *slog.Logger
 	promRegistry prometheus.Registerer
 	timerVacuum  *time.Timer
+	mmapSize     int64
+}
+
+// OptionFunc is a type that represents functions that modify the sqlite metadata store config
+type OptionFunc func(*MetadataStoreSqlite)
+
+// WithMmapSize sets the max number of bytes of the database file that sqlite will access using memory-mapped I/O.
+// This can significantly speed up read-heavy query workloads by avoiding a copy from the OS page cache. The tradeoffs
+// are that I/O errors on the mapped file (such as a full or failing disk) surface as a SIGBUS that crashes the process
+// rather than as an error, and that the mapped pages count towards the process memory footprint. In WAL mode, only
+// reads from the main database file use the mapping, while writes still go through the WAL and are copied into the
+// database at checkpoint time, so this mostly benefits reads. It has no effect on an in-memory database. A value of 0
+// (the default) disables memory-mapped I/O
+func WithMmapSize(size int64) OptionFunc {
+	return func(d *MetadataStoreSqlite) {
+		d.mmapSize = size
+	}
 }
 
 // New creates a new database
@@ -57,7 +79,19 @@ func New(
 	dataDir string,
 	logger *slog.Logger,
 	promRegistry prometheus.Registerer,
+	opts ...OptionFunc,
 ) (*MetadataStoreSqlite, error) {
+	db := &MetadataStoreSqlite{
+		dataDir:      dataDir,
+		logger:       logger,
+		promRegistry: promRegistry,
+	}
+	for _, opt := range opts {
+		opt(db)
+	}
+	if db.mmapSize < 0 {
+		return nil, fmt.Errorf("invalid mmap size: %d", db.mmapSize)
+	}
 	var metadataDb *gorm.DB
 	var err error
 	if dataDir == "" {
@@ -88,8 +122,8 @@ func New(
 			dataDir,
 			"metadata.sqlite",
 		)
-		// WAL journal mode, disable sync on write, increase cache size to 50MB (from 2MB)
-		metadataConnOpts := "_pragma=journal_mode(WAL)&_pragma=sync(OFF)&_pragma=cache_size(-50000)"
+		// WAL journal mode, disable sync on write, increase cache size to 50MB (from 2MB), incremental auto-vacuum
+		metadataConnOpts := "_pragma=auto_vacuum(incremental)&_pragma=journal_mode(WAL)&_pragma=sync(OFF)&_pragma=cache_size(-50000)" + db.mmapConnOpts()
 		metadataDb, err = gorm.Open(
 			sqlite.Open(
 				fmt.Sprintf("file:%s?%s", metadataDbPath, metadataConnOpts),
@@ -103,28 +137,70 @@ func New(
 			return nil, err
 		}
 	}
-	db := &MetadataStoreSqlite{
-		db:           metadataDb,
-		dataDir:      dataDir,
-		logger:       logger,
-		promRegistry: promRegistry,
-	}
+	db.db = metadataDb
 	if err := db.init(); err != nil {
 		// MetadataStoreSqlite is available for recovery, so return it with error
 		return db, err
 	}
-	// Create table schemas
-	db.logger.Debug(fmt.Sprintf("creating table: %#v", &CommitTimestamp{}))
-	if err := db.db.AutoMigrate(&CommitTimestamp{}); err != nil {
+	if err := db.migrate(); err != nil {
+		// MetadataStoreSqlite is available for recovery, so return it with error
 		return db, err
 	}
+	if err := db.enableIncrementalAutoVacuum(); err != nil {
+		db.logger.Warn(
+			"failed to enable incremental auto-vacuum on metadata database",
+			"component", "database",
+			"error", err,
+		)
+	}
+	return db, nil
+}
+
+// enableIncrementalAutoVacuum switches an existing database to incremental auto-vacuum. The auto_vacuum pragma in the
+// connection options only applies to new databases, and changing the mode on an existing database requires a full
+// VACUUM, which is run once here
+func (d *MetadataStoreSqlite) enableIncrementalAutoVacuum() error {
+	if d.dataDir == "" {
+		return nil
+	}
+	var autoVacuumMode int
+	if err := d.db.Raw("PRAGMA auto_vacuum").Scan(&autoVacuumMode).Error; err != nil {
+		return err
+	}
+	if autoVacuumMode == sqliteAutoVacuumIncremental {
+		return nil
+	}
+	d.logger.Info(
+		"converting metadata database to incremental auto-vacuum, this may take a while",
+		"component", "database",
+	)
+	if err := d.db.Exec("PRAGMA auto_vacuum = INCREMENTAL").Error; err != nil {
+		return err
+	}
+	return d.db.Exec("VACUUM").Error
+}
+
+// mmapConnOpts returns the connection string pragma for memory-mapped I/O, if enabled
+func (d *MetadataStoreSqlite) mmapConnOpts() string {
+	if d.mmapSize == 0 {
+		return ""
+	}
+	return fmt.Sprintf("&_pragma=mmap_size(%d)", d.mmapSize)
+}
+
+// migrate creates or updates the table schemas
+func (d *MetadataStoreSqlite) migrate() error {
+	d.logger.Debug(fmt.Sprintf("creating table: %#v", &CommitTimestamp{}))
+	if err := d.db.AutoMigrate(&CommitTimestamp{}); err != nil {
+		return err
+	}
 	for _, model := range models.MigrateModels {
-		db.logger.Debug(fmt.Sprintf("creating table: %#v", model))
-		if err := db.db.AutoMigrate(model); err != nil {
-			return db, err
+		d.logger.Debug(fmt.Sprintf("creating table: %#v", model))
+		if err := d.db.AutoMigrate(model); err != nil {
+			return err
 		}
 	}
-	return db, nil
+	return nil
 }
 
 func (d *MetadataStoreSqlite) init() error {
@@ -152,6 +228,29 @@ func (d *MetadataStoreSqlite) runVacuum() error {
 	return nil
 }
 
+// IncrementalVacuum returns free pages to the filesystem without the cost of a full vacuum. This only has an
+// effect on databases using incremental auto-vacuum
+func (d *MetadataStoreSqlite) IncrementalVacuum() error {
+	if d.dataDir == "" {
+		return nil
+	}
+	return d.DB().Exec("PRAGMA incremental_vacuum").Error
+}
+
+// IntegrityCheck runs PRAGMA integrity_check and returns the reported problems. An empty list means that no problems
+// were found. The check is run on the read replica, if enabled, and only needs a read transaction, so it doesn't
+// block writes in WAL mode
+func (d *MetadataStoreSqlite) IntegrityCheck() ([]string, error) {
+	var results []string
+	if err := d.ReadDB().Raw("PRAGMA integrity_check").Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	if len(results) == 1 && results[0] == "ok" {
+		return []string{}, nil
+	}
+	return results, nil
+}
+
 func (d *MetadataStoreSqlite) scheduleDailyVacuum() {
 	if d.timerVacuum != nil {
 		d.timerVacuum.Stop()
@@ -178,8 +277,48 @@ func (d *MetadataStoreSqlite) AutoMigrate(dst ...any) error {
 	return d.DB().AutoMigrate(dst...)
 }
 
+// EnableReadReplica opens an additional read-only connection to the database, which is used for read queries so that
+// they don't contend with the write path. This has no effect for an in-memory database
+func (d *MetadataStoreSqlite) EnableReadReplica() error {
+	if d.dataDir == "" || d.readDb != nil {
+		return nil
+	}
+	metadataDbPath := filepath.Join(
+		d.dataDir,
+		"metadata.sqlite",
+	)
+	// Read-only mode, increase cache size to 50MB (from 2MB)
+	metadataConnOpts := "mode=ro&_pragma=query_only(true)&_pragma=cache_size(-50000)" + d.mmapConnOpts()
+	readDb, err := gorm.Open(
+		sqlite.Open(
+			fmt.Sprintf("file:%s?%s", metadataDbPath, metadataConnOpts),
+		),
+		&gorm.Config{
+			Logger:                 gormlogger.Discard,
+			SkipDefaultTransaction: true,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to open read replica: %w", err)
+	}
+	if err := readDb.Use(tracing.NewPlugin(tracing.WithoutMetrics())); err != nil {
+		return err
+	}
+	d.readDb = readDb
+	return nil
+}
+
 // Close gets the database handle from our MetadataStore and closes it
 func (d *MetadataStoreSqlite) Close() error {
+	if d.readDb != nil {
+		readDb, err := d.readDb.DB()
+		if err != nil {
+			return err
+		}
+		if err := readDb.Close(); err != nil {
+			return err
+		}
+	}
 	// get DB handle from gorm.DB
 	db, err := d.DB().DB()
 	if err != nil {
@@ -190,7 +329,12 @@ func (d *MetadataStoreSqlite) Close() error {
 
 // Create creates a record
 func (d *MetadataStoreSqlite) Create(value any) *gorm.DB {
-	return d.DB().Create(value)
+	return d.CreateCtx(context.Background(), value)
+}
+
+// CreateCtx creates a record using the provided context for cancellation and tracing
+func (d *MetadataStoreSqlite) CreateCtx(ctx context.Context, value any) *gorm.DB {
+	return d.DB().WithContext(ctx).Create(value)
 }
 
 // DB returns the database handle
@@ -198,14 +342,32 @@ func (d *MetadataStoreSqlite) DB() *gorm.DB {
 	return d.db
 }
 
+// ReadDB returns the read-only database handle if a read replica is enabled, or the regular database handle otherwise
+func (d *MetadataStoreSqlite) ReadDB() *gorm.DB {
+	if d.readDb != nil {
+		return d.readDb
+	}
+	return d.db
+}
+
 // First returns the first DB entry
 func (d *MetadataStoreSqlite) First(args any) *gorm.DB {
-	return d.DB().First(args)
+	return d.FirstCtx(context.Background(), args)
+}
+
+// FirstCtx returns the first DB entry using the provided context for cancellation and tracing
+func (d *MetadataStoreSqlite) FirstCtx(ctx context.Context, args any) *gorm.DB {
+	return d.ReadDB().WithContext(ctx).First(args)
 }
 
 // Order orders a DB query
 func (d *MetadataStoreSqlite) Order(args any) *gorm.DB {
-	return d.DB().Order(args)
+	return d.OrderCtx(context.Background(), args)
+}
+
+// OrderCtx orders a DB query using the provided context for cancellation and tracing
+func (d *MetadataStoreSqlite) OrderCtx(ctx context.Context, args any) *gorm.DB {
+	return d.ReadDB().WithContext(ctx).Order(args)
 }
 
 // Transaction creates a gorm transaction
@@ -218,5 +380,14 @@ func (d *MetadataStoreSqlite) Where(
 	query any,
 	args ...any,
 ) *gorm.DB {
-	return d.DB().Where(query, args...)
+	return d.WhereCtx(context.Background(), query, args...)
+}
+
+// WhereCtx constrains a DB query using the provided context for cancellation and tracing
+func (d *MetadataStoreSqlite) WhereCtx(
+	ctx context.Context,
+	query any,
+	args ...any,
+) *gorm.DB {
+	return d.ReadDB().WithContext(ctx).Where(query, args...)
 }
@@ -0,0 +1,184 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+func TestMmapSize(t *testing.T) {
+	testDefs := []struct {
+		name     string
+		mmapSize int64
+	}{
+		{name: "disabled", mmapSize: 0},
+		{name: "enabled", mmapSize: 64 * 1024 * 1024},
+	}
+	for _, testDef := range testDefs {
+		t.Run(testDef.name, func(t *testing.T) {
+			db, err := New(t.TempDir(), nil, nil, WithMmapSize(testDef.mmapSize))
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			defer db.Close()
+			if err := db.EnableReadReplica(); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			var mmapSize int64
+			if err := db.DB().Raw("PRAGMA mmap_size").Scan(&mmapSize).Error; err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if mmapSize != testDef.mmapSize {
+				t.Fatalf("did not get expected mmap_size: got %d, wanted %d", mmapSize, testDef.mmapSize)
+			}
+			if err := db.ReadDB().Raw("PRAGMA mmap_size").Scan(&mmapSize).Error; err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if mmapSize != testDef.mmapSize {
+				t.Fatalf("did not get expected read replica mmap_size: got %d, wanted %d", mmapSize, testDef.mmapSize)
+			}
+		})
+	}
+}
+
+func TestMmapSizeInvalid(t *testing.T) {
+	if _, err := New(t.TempDir(), nil, nil, WithMmapSize(-1)); err == nil {
+		t.Fatal("did not get expected error")
+	}
+}
+
+func TestEnableIncrementalAutoVacuum(t *testing.T) {
+	dataDir := t.TempDir()
+	// Create a database without auto-vacuum, as older versions did
+	oldDb, err := gorm.Open(
+		sqlite.Open("file:"+filepath.Join(dataDir, "metadata.sqlite")),
+		&gorm.Config{},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := oldDb.Exec("CREATE TABLE test (id INTEGER PRIMARY KEY)").Error; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sqlDb, err := oldDb.DB()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	sqlDb.Close()
+	db, err := New(dataDir, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+	var autoVacuumMode int
+	if err := db.DB().Raw("PRAGMA auto_vacuum").Scan(&autoVacuumMode).Error; err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if autoVacuumMode != sqliteAutoVacuumIncremental {
+		t.Fatalf("did not get expected auto_vacuum mode: got %d, wanted %d", autoVacuumMode, sqliteAutoVacuumIncremental)
+	}
+}
+
+func TestDeleteBlockNoncesBeforeSlotWithoutCheckpoints(t *testing.T) {
+	db, err := New(t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+	for i := range 5 {
+		if err := db.SetBlockNonce([]byte{byte(i)}, uint64(i), []byte{0x01}, false, nil); err != nil { // #nosec G115
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if err := db.SetBlockNonce([]byte{0xff}, 1, []byte{0x01}, true, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Delete in batches of 2
+	var counts []int
+	for {
+		count, err := db.DeleteBlockNoncesBeforeSlotWithoutCheckpoints(4, 2, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if count == 0 {
+			break
+		}
+		counts = append(counts, count)
+	}
+	if len(counts) != 2 || counts[0] != 2 || counts[1] != 2 {
+		t.Fatalf("did not get expected batch counts: %v", counts)
+	}
+	// The checkpoint and the nonce at slot 4 are kept
+	for _, testDef := range []struct {
+		hash []byte
+		slot uint64
+	}{
+		{hash: []byte{0xff}, slot: 1},
+		{hash: []byte{0x04}, slot: 4},
+	} {
+		nonce, err := db.GetBlockNonce(testDef.hash, testDef.slot, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if nonce == nil {
+			t.Fatalf("block nonce at slot %d was unexpectedly deleted", testDef.slot)
+		}
+	}
+}
+
+func TestDeleteBlockNoncesBeforeSlot(t *testing.T) {
+	db, err := New(t.TempDir(), nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+	for i := range 5 {
+		if err := db.SetBlockNonce([]byte{byte(i)}, uint64(i), []byte{0x01}, false, nil); err != nil { // #nosec G115
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	if err := db.SetBlockNonce([]byte{0xff}, 1, []byte{0x01}, true, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	// Checkpoints are deleted along with everything else
+	count, err := db.DeleteBlockNoncesBeforeSlot(4, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if count != 5 {
+		t.Fatalf("did not get expected deleted count: got %d, wanted 5", count)
+	}
+	for _, testDef := range []struct {
+		hash    []byte
+		slot    uint64
+		deleted bool
+	}{
+		{hash: []byte{0xff}, slot: 1, deleted: true},
+		{hash: []byte{0x03}, slot: 3, deleted: true},
+		{hash: []byte{0x04}, slot: 4, deleted: false},
+	} {
+		nonce, err := db.GetBlockNonce(testDef.hash, testDef.slot, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if (nonce == nil) != testDef.deleted {
+			t.Fatalf("block nonce at slot %d: got deleted %v, wanted %v", testDef.slot, nonce == nil, testDef.deleted)
+		}
+	}
+}
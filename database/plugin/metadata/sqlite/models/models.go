@@ -19,6 +19,7 @@ var MigrateModels = []any{
 	&Account{},
 	&AuthCommitteeHot{},
 	&BlockNonce{},
+	&BlockPruneState{},
 	&Datum{},
 	&Deregistration{},
 	&DeregistrationDrep{},
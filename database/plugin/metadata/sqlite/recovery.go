@@ -0,0 +1,89 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sqlite
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Recover attempts to repair a database that failed to initialize. The WAL is checkpointed into the main database
+// file, the database is checked for corruption, and the table schemas are migrated again
+func (d *MetadataStoreSqlite) Recover() error {
+	d.logger.Info(
+		"recovering metadata store: checkpointing WAL",
+		"component", "database",
+	)
+	if err := d.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error; err != nil {
+		return fmt.Errorf("WAL checkpoint failed: %w", err)
+	}
+	d.logger.Info(
+		"recovering metadata store: running integrity check",
+		"component", "database",
+	)
+	problems, err := d.IntegrityCheck()
+	if err != nil {
+		return fmt.Errorf("integrity check failed: %w", err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf(
+			"integrity check failed: %s",
+			strings.Join(problems, "; "),
+		)
+	}
+	d.logger.Info(
+		"recovering metadata store: migrating table schemas",
+		"component", "database",
+	)
+	if err := d.migrate(); err != nil {
+		return fmt.Errorf("schema migration failed: %w", err)
+	}
+	return nil
+}
+
+// Reset moves the database files in the specified data dir aside so that a new, empty database is created the next
+// time it's opened. The old files are kept with a ".corrupt-<timestamp>" suffix for troubleshooting
+func Reset(dataDir string, logger *slog.Logger) error {
+	if dataDir == "" {
+		return errors.New("cannot reset an in-memory database")
+	}
+	suffix := fmt.Sprintf(".corrupt-%d", time.Now().Unix())
+	metadataDbPath := filepath.Join(dataDir, "metadata.sqlite")
+	for _, path := range []string{
+		metadataDbPath,
+		metadataDbPath + "-wal",
+		metadataDbPath + "-shm",
+	} {
+		if err := os.Rename(path, path+suffix); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("failed to move %s aside: %w", path, err)
+		}
+		if logger != nil {
+			logger.Warn(
+				fmt.Sprintf("moved %s to %s", path, path+suffix),
+				"component", "database",
+			)
+		}
+	}
+	return nil
+}
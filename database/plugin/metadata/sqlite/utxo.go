@@ -47,6 +47,25 @@ func (d *MetadataStoreSqlite) GetUtxo(
 	return ret, nil
 }
 
+// GetUtxosByStakingKey returns all unspent Utxos with the specified staking key
+func (d *MetadataStoreSqlite) GetUtxosByStakingKey(
+	stakeKey []byte,
+	txn *gorm.DB,
+) ([]models.Utxo, error) {
+	var ret []models.Utxo
+	db := txn
+	if db == nil {
+		db = d.DB()
+	}
+	result := db.
+		Where("deleted_slot = 0 AND staking_key = ?", stakeKey).
+		Find(&ret)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ret, nil
+}
+
 // GetUtxosAddedAfterSlot returns a list of Utxos added after a given slot
 func (d *MetadataStoreSqlite) GetUtxosAddedAfterSlot(
 	slot uint64,
@@ -31,7 +31,12 @@ type MetadataStore interface {
 	// Database
 	Close() error
 	DB() *gorm.DB
+	EnableReadReplica() error
 	GetCommitTimestamp() (int64, error)
+	IncrementalVacuum() error
+	IntegrityCheck() ([]string, error)
+	ReadDB() *gorm.DB
+	Recover() error
 	SetCommitTimestamp(*gorm.DB, int64) error
 	Transaction() *gorm.DB
 
@@ -59,6 +64,7 @@ type MetadataStore interface {
 		uint64, // slotNumber
 		*gorm.DB,
 	) ([]byte, error)
+	GetDelegatedAccounts(*gorm.DB) ([]models.Account, error)
 	GetDatum(
 		lcommon.Blake2b256,
 		*gorm.DB,
@@ -76,6 +82,10 @@ type MetadataStore interface {
 		uint32, // idx
 		*gorm.DB,
 	) (models.Utxo, error)
+	GetUtxosByStakingKey(
+		[]byte, // stakeKey
+		*gorm.DB,
+	) ([]models.Utxo, error)
 
 	SetAccount(
 		[]byte, // stakeKey
@@ -217,9 +227,13 @@ type MetadataStore interface {
 		*gorm.DB,
 	) error
 
+	// Block pruning
+	GetBlockPrunedSlot(*gorm.DB) (uint64, error)
+	SetBlockPrunedSlot(uint64, *gorm.DB) error
+
 	// Helpers
-	DeleteBlockNoncesBeforeSlot(uint64, *gorm.DB) error
-	DeleteBlockNoncesBeforeSlotWithoutCheckpoints(uint64, *gorm.DB) error
+	DeleteBlockNoncesBeforeSlot(uint64, *gorm.DB) (int, error)
+	DeleteBlockNoncesBeforeSlotWithoutCheckpoints(uint64, int, *gorm.DB) (int, error)
 	DeleteUtxo(any, *gorm.DB) error
 	DeleteUtxos([]any, *gorm.DB) error
 	DeleteUtxosAfterSlot(uint64, *gorm.DB) error
@@ -239,5 +253,15 @@ func New(
 	logger *slog.Logger,
 	promRegistry prometheus.Registerer,
 ) (MetadataStore, error) {
-	return sqlite.New(dataDir, logger, promRegistry)
+	store, err := sqlite.New(dataDir, logger, promRegistry)
+	if store == nil {
+		// Avoid returning a non-nil interface wrapping a nil pointer
+		return nil, err
+	}
+	return store, err
+}
+
+// Reset moves the existing database aside so that an empty database is created the next time it's opened
+func Reset(pluginName, dataDir string, logger *slog.Logger) error {
+	return sqlite.Reset(dataDir, logger)
 }
@@ -17,6 +17,7 @@ package database
 import (
 	"fmt"
 
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
 	"github.com/blinklabs-io/gouroboros/cbor"
 	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
 )
@@ -125,6 +126,17 @@ func (d *Database) ApplyPParamUpdates(
 	)
 }
 
+// GetPParamUpdates returns the protocol parameter updates proposed for the specified epoch, ordered from newest to oldest
+func (d *Database) GetPParamUpdates(
+	epoch uint64,
+	txn *Txn,
+) ([]models.PParamUpdate, error) {
+	if txn == nil {
+		return d.metadata.GetPParamUpdates(epoch, nil)
+	}
+	return d.metadata.GetPParamUpdates(epoch, txn.Metadata())
+}
+
 func (d *Database) SetPParamUpdate(
 	genesis, params []byte,
 	slot, epoch uint64,
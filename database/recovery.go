@@ -0,0 +1,69 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+
+	"github.com/blinklabs-io/dingo/database/plugin/metadata"
+)
+
+// MetadataInitError is returned when the metadata store fails to initialize and can't be recovered in place
+type MetadataInitError struct {
+	Err error
+}
+
+func (e MetadataInitError) Error() string {
+	return fmt.Sprintf("metadata store initialization failed: %s", e.Err)
+}
+
+func (e MetadataInitError) Unwrap() error {
+	return e.Err
+}
+
+// recoverMetadata attempts to recover a metadata store that failed to initialize
+func recoverMetadata(
+	logger *slog.Logger,
+	metadataDb metadata.MetadataStore,
+	initErr error,
+) error {
+	if logger == nil {
+		logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
+	}
+	logger.Warn(
+		"metadata store initialization failed, attempting recovery",
+		"component", "database",
+		"error", initErr,
+	)
+	if err := metadataDb.Recover(); err != nil {
+		_ = metadataDb.Close()
+		return MetadataInitError{
+			Err: fmt.Errorf("%w (recovery failed: %w)", initErr, err),
+		}
+	}
+	logger.Info(
+		"metadata store recovered",
+		"component", "database",
+	)
+	return nil
+}
+
+// ResetMetadata moves the metadata store in the specified data dir aside so that it's recreated empty when the
+// database is next opened. The ledger state is then rebuilt by replaying the blocks in the blob store
+func ResetMetadata(dataDir string, logger *slog.Logger) error {
+	return metadata.Reset("sqlite", dataDir, logger)
+}
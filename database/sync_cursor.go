@@ -0,0 +1,129 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database
+
+import (
+	"slices"
+
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+	"github.com/dgraph-io/badger/v4"
+)
+
+const (
+	syncCursorBlobKeyPrefix = "sc"
+)
+
+// SyncCursorBlobKey returns the blob store key for a chainsync cursor point. Keys sort by slot
+func SyncCursorBlobKey(point ocommon.Point) []byte {
+	return slices.Concat(
+		[]byte(syncCursorBlobKeyPrefix),
+		blockBlobKeyUint64ToBytes(point.Slot),
+		point.Hash,
+	)
+}
+
+func syncCursorBlobKeyToPoint(key []byte) ocommon.Point {
+	prefixLen := len(syncCursorBlobKeyPrefix)
+	return blockBlobKeyToPoint(
+		slices.Concat([]byte(blockBlobKeyPrefix), key[prefixLen:]),
+	)
+}
+
+// GetSyncCursor returns the persisted chainsync cursor points in descending slot order. The cursor is kept in the
+// blob store alongside the blocks, so it survives a reset of the metadata store
+func (d *Database) GetSyncCursor(txn *Txn) ([]ocommon.Point, error) {
+	if txn == nil {
+		txn = d.BlobTxn(false)
+		defer txn.Commit() //nolint:errcheck
+	}
+	var ret []ocommon.Point
+	syncCursorIterate(txn, func(key []byte) bool {
+		ret = append(ret, syncCursorBlobKeyToPoint(key))
+		return true
+	})
+	return ret, nil
+}
+
+// AddSyncCursorPoint records an accepted chain point in the chainsync cursor. Old points are removed separately by
+// TrimSyncCursor
+func (d *Database) AddSyncCursorPoint(point ocommon.Point, txn *Txn) error {
+	if txn == nil {
+		txn = d.BlobTxn(true)
+		defer txn.Commit() //nolint:errcheck
+	}
+	return txn.Blob().Set(SyncCursorBlobKey(point), []byte{})
+}
+
+// TrimSyncCursor removes all but the most recent maxPoints points from the chainsync cursor
+func (d *Database) TrimSyncCursor(maxPoints int, txn *Txn) error {
+	if txn == nil {
+		txn = d.BlobTxn(true)
+		defer txn.Commit() //nolint:errcheck
+	}
+	var deleteKeys [][]byte
+	var count int
+	syncCursorIterate(txn, func(key []byte) bool {
+		count++
+		if count > maxPoints {
+			deleteKeys = append(deleteKeys, key)
+		}
+		return true
+	})
+	for _, key := range deleteKeys {
+		if err := txn.Blob().Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteSyncCursorAfterSlot removes chainsync cursor points after the specified slot
+func (d *Database) DeleteSyncCursorAfterSlot(slot uint64, txn *Txn) error {
+	if txn == nil {
+		txn = d.BlobTxn(true)
+		defer txn.Commit() //nolint:errcheck
+	}
+	var deleteKeys [][]byte
+	syncCursorIterate(txn, func(key []byte) bool {
+		if syncCursorBlobKeyToPoint(key).Slot <= slot {
+			return false
+		}
+		deleteKeys = append(deleteKeys, key)
+		return true
+	})
+	for _, key := range deleteKeys {
+		if err := txn.Blob().Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// syncCursorIterate calls the provided function with each chainsync cursor key in descending slot order, until it
+// returns false
+func syncCursorIterate(txn *Txn, fn func([]byte) bool) {
+	iterOpts := badger.IteratorOptions{
+		Reverse: true,
+	}
+	it := txn.Blob().NewIterator(iterOpts)
+	defer it.Close()
+	// Seek to the end of our key prefix, as in BlocksRecentTxn
+	seekKey := append([]byte(syncCursorBlobKeyPrefix), 0xff)
+	for it.Seek(seekKey); it.ValidForPrefix([]byte(syncCursorBlobKeyPrefix)); it.Next() {
+		if !fn(it.Item().KeyCopy(nil)) {
+			break
+		}
+	}
+}
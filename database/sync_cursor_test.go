@@ -0,0 +1,67 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package database_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/database"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+func TestSyncCursor(t *testing.T) {
+	const testCacheSize int64 = 1 << 20
+	db, err := database.New(nil, nil, "", testCacheSize) // in-memory
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	defer db.Close()
+	for i := range 5 {
+		slot := uint64(i+1) * 10
+		point := ocommon.NewPoint(slot, bytes.Repeat([]byte{byte(i + 1)}, 32))
+		if err := db.AddSyncCursorPoint(point, nil); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+	checkSlots := func(expectedSlots []uint64) {
+		t.Helper()
+		points, err := db.GetSyncCursor(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if len(points) != len(expectedSlots) {
+			t.Fatalf("did not get expected point count: got %d, wanted %d", len(points), len(expectedSlots))
+		}
+		for i, point := range points {
+			if point.Slot != expectedSlots[i] {
+				t.Fatalf("did not get expected slot at index %d: got %d, wanted %d", i, point.Slot, expectedSlots[i])
+			}
+			if !bytes.Equal(point.Hash, bytes.Repeat([]byte{byte(point.Slot / 10)}, 32)) {
+				t.Fatalf("did not get expected hash for slot %d: got %x", point.Slot, point.Hash)
+			}
+		}
+	}
+	// Points are returned most recent first
+	checkSlots([]uint64{50, 40, 30, 20, 10})
+	if err := db.TrimSyncCursor(3, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	checkSlots([]uint64{50, 40, 30})
+	if err := db.DeleteSyncCursorAfterSlot(40, nil); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	checkSlots([]uint64{40, 30})
+}
@@ -168,6 +168,31 @@ func (d *Database) UtxosByAddress(
 	return ret, nil
 }
 
+// UtxosByStakingKey returns all unspent UTxOs with the specified staking key
+func (d *Database) UtxosByStakingKey(
+	stakeKey []byte,
+	txn *Txn,
+) ([]Utxo, error) {
+	ret := []Utxo{}
+	if txn == nil {
+		txn = d.Transaction(false)
+		defer txn.Commit() //nolint:errcheck
+	}
+	utxos, err := d.metadata.GetUtxosByStakingKey(stakeKey, txn.Metadata())
+	if err != nil {
+		return ret, err
+	}
+	var tmpUtxo Utxo
+	for _, utxo := range utxos {
+		tmpUtxo = Utxo(utxo)
+		if err := tmpUtxo.loadCbor(txn); err != nil {
+			return ret, err
+		}
+		ret = append(ret, tmpUtxo)
+	}
+	return ret, nil
+}
+
 func (d *Database) UtxosDeleteConsumed(
 	slot uint64,
 	limit int,
@@ -0,0 +1,133 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"errors"
+	"fmt"
+
+	gledger "github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+// ErrNoScriptEvaluator is returned when evaluating a transaction without a configured script evaluator. We don't
+// include a Plutus script evaluator, so one must be provided with WithScriptEvaluator to use EvaluateTransaction
+var ErrNoScriptEvaluator = errors.New("no script evaluator configured")
+
+// ExUnits represents the memory and CPU steps used by a script
+type ExUnits = lcommon.ExUnits
+
+// ScriptEvaluatorFunc evaluates the Plutus scripts in a transaction against the resolved inputs and cost models, and
+// returns the execution units used by each redeemer. Results should be keyed using RedeemerKey
+type ScriptEvaluatorFunc func(
+	tx gledger.Transaction,
+	resolvedInputs []lcommon.Utxo,
+	costModels map[string][]int64,
+) (map[string]ExUnits, error)
+
+var redeemerTagNames = map[lcommon.RedeemerTag]string{
+	lcommon.RedeemerTagSpend:     "spend",
+	lcommon.RedeemerTagMint:      "mint",
+	lcommon.RedeemerTagCert:      "cert",
+	lcommon.RedeemerTagReward:    "reward",
+	lcommon.RedeemerTagVoting:    "voting",
+	lcommon.RedeemerTagProposing: "proposing",
+}
+
+// RedeemerKey returns the key used for a redeemer in the result of EvaluateTransaction, such as "spend:0"
+func RedeemerKey(tag lcommon.RedeemerTag, index uint) string {
+	tagName, ok := redeemerTagNames[tag]
+	if !ok {
+		tagName = fmt.Sprintf("tag%d", tag)
+	}
+	return fmt.Sprintf("%s:%d", tagName, index)
+}
+
+// EvaluateTransaction evaluates the scripts in the provided transaction CBOR against the current ledger state and
+// protocol parameter cost models, and returns the execution units used by each redeemer keyed by RedeemerKey. The
+// transaction is not added to the mempool. Script evaluation is performed by the evaluator configured with
+// WithScriptEvaluator, and ErrNoScriptEvaluator is returned if there isn't one. The evaluator is called without
+// holding the ledger lock, so a slow evaluation doesn't block block processing
+func (n *Node) EvaluateTransaction(cborBytes []byte) (map[string]ExUnits, error) {
+	if n.ledgerState == nil {
+		return nil, ErrNodeNotRunning
+	}
+	if n.config.scriptEvaluator == nil {
+		return nil, ErrNoScriptEvaluator
+	}
+	txType, err := gledger.DetermineTransactionType(cborBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine transaction type: %w", err)
+	}
+	tx, err := gledger.NewTransactionFromCbor(txType, cborBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	resolvedInputs, costModels, err := n.evaluateTransactionContext(tx)
+	if err != nil {
+		return nil, err
+	}
+	return n.config.scriptEvaluator(tx, resolvedInputs, costModels)
+}
+
+// evaluateTransactionContext resolves the inputs of the transaction and gets the current cost models from a consistent
+// view of the ledger state
+func (n *Node) evaluateTransactionContext(
+	tx gledger.Transaction,
+) ([]lcommon.Utxo, map[string][]int64, error) {
+	n.ledgerState.RLock()
+	defer n.ledgerState.RUnlock()
+	// Resolve inputs, including reference inputs and collateral, which scripts may need to inspect
+	var resolvedInputs []lcommon.Utxo
+	for _, inputs := range [][]lcommon.TransactionInput{
+		tx.Inputs(),
+		tx.ReferenceInputs(),
+		tx.Collateral(),
+	} {
+		for _, input := range inputs {
+			utxo, err := n.ledgerState.UtxoByRef(
+				input.Id().Bytes(),
+				input.Index(),
+			)
+			if err != nil {
+				return nil, nil, fmt.Errorf(
+					"failed to resolve input %s: %w",
+					input.String(),
+					err,
+				)
+			}
+			output, err := utxo.Decode()
+			if err != nil {
+				return nil, nil, fmt.Errorf(
+					"failed to decode input %s: %w",
+					input.String(),
+					err,
+				)
+			}
+			resolvedInputs = append(
+				resolvedInputs,
+				lcommon.Utxo{
+					Id:     input,
+					Output: output,
+				},
+			)
+		}
+	}
+	costModels, err := n.ledgerState.CostModels()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get cost models: %w", err)
+	}
+	return resolvedInputs, costModels, nil
+}
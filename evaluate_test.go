@@ -0,0 +1,133 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/ledger"
+	gledger "github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+// newTestEvaluateNode returns a node with an empty ledger state and the specified script evaluator
+func newTestEvaluateNode(t *testing.T, evaluator ScriptEvaluatorFunc) *Node {
+	t.Helper()
+	db, err := database.New(nil, nil, "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error creating database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	cm, err := chain.NewManager(db, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain manager: %s", err)
+	}
+	ls, err := ledger.NewLedgerState(
+		ledger.LedgerStateConfig{
+			Database:     db,
+			ChainManager: cm,
+		},
+	)
+	if err != nil {
+		t.Fatalf("unexpected error creating ledger state: %s", err)
+	}
+	return &Node{
+		config: Config{
+			scriptEvaluator: evaluator,
+		},
+		ledgerState: ls,
+	}
+}
+
+// newTestEvaluateTx returns the CBOR for a transaction spending the first output of the specified transaction
+func newTestEvaluateTx(t *testing.T, inputTxId string) []byte {
+	t.Helper()
+	// [{0: [[txId, 0]], 1: [], 2: 1000}, {}, true, null]
+	txCbor, err := hex.DecodeString(
+		"84a3008182" + "5820" + inputTxId + "00" + "0180021903e8a0f5f6",
+	)
+	if err != nil {
+		t.Fatalf("unexpected error decoding transaction hex: %s", err)
+	}
+	return txCbor
+}
+
+func TestEvaluateTransactionNotRunning(t *testing.T) {
+	n := &Node{}
+	if _, err := n.EvaluateTransaction(nil); !errors.Is(err, ErrNodeNotRunning) {
+		t.Fatalf("did not get expected error: %v", err)
+	}
+}
+
+func TestEvaluateTransactionNoScriptEvaluator(t *testing.T) {
+	n := newTestEvaluateNode(t, nil)
+	txCbor := newTestEvaluateTx(t, strings.Repeat("01", 32))
+	if _, err := n.EvaluateTransaction(txCbor); !errors.Is(err, ErrNoScriptEvaluator) {
+		t.Fatalf("did not get expected error: %v", err)
+	}
+}
+
+func TestEvaluateTransactionUnresolvedInput(t *testing.T) {
+	var evaluatorCalled bool
+	n := newTestEvaluateNode(
+		t,
+		func(gledger.Transaction, []lcommon.Utxo, map[string][]int64) (map[string]ExUnits, error) {
+			evaluatorCalled = true
+			return nil, nil
+		},
+	)
+	// Invalid transaction CBOR
+	if _, err := n.EvaluateTransaction(bytes.Repeat([]byte{0xff}, 4)); err == nil {
+		t.Fatal("did not get expected error for invalid transaction")
+	}
+	// Transaction spending an unknown input
+	txCbor := newTestEvaluateTx(t, strings.Repeat("01", 32))
+	_, err := n.EvaluateTransaction(txCbor)
+	if err == nil || !strings.Contains(err.Error(), "failed to resolve input") {
+		t.Fatalf("did not get expected error: %v", err)
+	}
+	if evaluatorCalled {
+		t.Fatal("script evaluator was called for a transaction with unresolved inputs")
+	}
+	// The ledger lock must be released
+	if !n.ledgerState.TryLock() {
+		t.Fatal("ledger state lock was not released")
+	}
+	n.ledgerState.Unlock()
+}
+
+func TestRedeemerKey(t *testing.T) {
+	testDefs := []struct {
+		tag      lcommon.RedeemerTag
+		index    uint
+		expected string
+	}{
+		{tag: lcommon.RedeemerTagSpend, index: 0, expected: "spend:0"},
+		{tag: lcommon.RedeemerTagMint, index: 2, expected: "mint:2"},
+		{tag: lcommon.RedeemerTagProposing, index: 1, expected: "proposing:1"},
+		{tag: lcommon.RedeemerTag(99), index: 3, expected: "tag99:3"},
+	}
+	for _, testDef := range testDefs {
+		if key := RedeemerKey(testDef.tag, testDef.index); key != testDef.expected {
+			t.Errorf("did not get expected key: got %s, wanted %s", key, testDef.expected)
+		}
+	}
+}
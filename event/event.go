@@ -15,6 +15,8 @@
 package event
 
 import (
+	"io"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -45,17 +47,63 @@ func NewEvent(eventType EventType, eventData any) Event {
 	}
 }
 
+// OverflowPolicy determines what happens when an event is published while a subscriber's event queue is full
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the publisher until there is room in the subscriber's queue. This is the default, and
+	// should be used for subscribers that can't lose events
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDrop drops the event for the subscriber, for event types where losing events is acceptable
+	OverflowDrop
+	// OverflowDetach removes the subscriber and closes its channel, with a logged warning. This is for subscribers
+	// that can't lose events but shouldn't be allowed to hold up publishers
+	OverflowDetach
+)
+
+// SubscribeOptionFunc is a type that represents functions that modify a subscription
+type SubscribeOptionFunc func(*subscriber)
+
+// WithQueueSize specifies the size of the subscriber's event queue. The default is EventQueueSize
+func WithQueueSize(queueSize int) SubscribeOptionFunc {
+	return func(s *subscriber) {
+		if queueSize > 0 {
+			s.queueSize = queueSize
+		}
+	}
+}
+
+// WithOverflowPolicy specifies what happens when an event is published while the subscriber's event queue is full.
+// The default is OverflowBlock
+func WithOverflowPolicy(overflowPolicy OverflowPolicy) SubscribeOptionFunc {
+	return func(s *subscriber) {
+		s.overflowPolicy = overflowPolicy
+	}
+}
+
+type subscriber struct {
+	ch             chan Event
+	queueSize      int
+	overflowPolicy OverflowPolicy
+}
+
 type EventBus struct {
-	mu          sync.RWMutex
-	subscribers map[EventType]map[EventSubscriberId]chan Event
+	mu          sync.Mutex
+	subscribers map[EventType]map[EventSubscriberId]*subscriber
 	lastSubId   EventSubscriberId
+	logger      *slog.Logger
 	metrics     *eventMetrics
+	// Latest event for each sticky event type. Only one event is kept per type
+	stickyEvents map[EventType]*Event
 }
 
 // NewEventBus creates a new EventBus
 func NewEventBus(promRegistry prometheus.Registerer) *EventBus {
 	e := &EventBus{
-		subscribers: make(map[EventType]map[EventSubscriberId]chan Event),
+		subscribers: make(map[EventType]map[EventSubscriberId]*subscriber),
+		// Create logger to throw away logs until one is set
+		logger:       slog.New(slog.NewJSONHandler(io.Discard, nil)),
+		stickyEvents: make(map[EventType]*Event),
 	}
 	if promRegistry != nil {
 		e.initMetrics(promRegistry)
@@ -63,35 +111,75 @@ func NewEventBus(promRegistry prometheus.Registerer) *EventBus {
 	return e
 }
 
-// Subscribe allows a consumer to receive events of a particular type via a channel
+// SetLogger sets the logger used for reporting detached subscribers
+func (e *EventBus) SetLogger(logger *slog.Logger) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if logger != nil {
+		e.logger = logger
+	}
+}
+
+func (e *EventBus) getLogger() *slog.Logger {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.logger
+}
+
+// SetSticky enables last-value mode for an event type. The most recent event of a sticky type is kept and delivered
+// immediately to new subscribers, so that late subscribers can get the latest state. Only the latest event is kept,
+// so this is safe for high-volume event types
+func (e *EventBus) SetSticky(eventType EventType) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if _, ok := e.stickyEvents[eventType]; !ok {
+		e.stickyEvents[eventType] = nil
+	}
+}
+
+// Subscribe allows a consumer to receive events of a particular type via a channel. For sticky event types, the most
+// recent event (if any) is delivered first. By default, the publisher blocks while the subscriber's queue is full,
+// which can be changed with WithOverflowPolicy
 func (e *EventBus) Subscribe(
 	eventType EventType,
+	opts ...SubscribeOptionFunc,
 ) (EventSubscriberId, <-chan Event) {
+	sub := &subscriber{
+		queueSize: EventQueueSize,
+	}
+	for _, opt := range opts {
+		opt(sub)
+	}
+	// Create event channel
+	sub.ch = make(chan Event, sub.queueSize)
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	// Create event channel
-	evtCh := make(chan Event, EventQueueSize)
 	// Increment subscriber ID
 	subId := e.lastSubId + 1
 	e.lastSubId = subId
 	// Add new subscriber
 	if _, ok := e.subscribers[eventType]; !ok {
-		e.subscribers[eventType] = make(map[EventSubscriberId]chan Event)
+		e.subscribers[eventType] = make(map[EventSubscriberId]*subscriber)
 	}
 	evtTypeSubs := e.subscribers[eventType]
-	evtTypeSubs[subId] = evtCh
+	evtTypeSubs[subId] = sub
+	// Deliver the latest sticky event. This can't block, since the channel is new
+	if stickyEvt := e.stickyEvents[eventType]; stickyEvt != nil {
+		sub.ch <- *stickyEvt
+	}
 	if e.metrics != nil {
 		e.metrics.subscribers.WithLabelValues(string(eventType)).Inc()
 	}
-	return subId, evtCh
+	return subId, sub.ch
 }
 
 // SubscribeFunc allows a consumer to receive events of a particular type via a callback function
 func (e *EventBus) SubscribeFunc(
 	eventType EventType,
 	handlerFunc EventHandlerFunc,
+	opts ...SubscribeOptionFunc,
 ) EventSubscriberId {
-	subId, evtCh := e.Subscribe(eventType)
+	subId, evtCh := e.Subscribe(eventType, opts...)
 	go func(evtCh <-chan Event, handlerFunc EventHandlerFunc) {
 		for {
 			evt, ok := <-evtCh
@@ -108,9 +196,14 @@ func (e *EventBus) SubscribeFunc(
 func (e *EventBus) Unsubscribe(eventType EventType, subId EventSubscriberId) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
-	if evtTypeSubs, ok := e.subscribers[eventType]; ok {
-		delete(evtTypeSubs, subId)
+	evtTypeSubs, ok := e.subscribers[eventType]
+	if !ok {
+		return
 	}
+	if _, ok := evtTypeSubs[subId]; !ok {
+		return
+	}
+	delete(evtTypeSubs, subId)
 	if e.metrics != nil {
 		e.metrics.subscribers.WithLabelValues(string(eventType)).Dec()
 	}
@@ -118,21 +211,50 @@ func (e *EventBus) Unsubscribe(eventType EventType, subId EventSubscriberId) {
 
 // Publish allows a producer to send an event of a particular type to all subscribers
 func (e *EventBus) Publish(eventType EventType, evt Event) {
-	// Build list of channels inside read lock to avoid map race condition
-	e.mu.RLock()
-	subs, ok := e.subscribers[eventType]
-	subChans := make([]chan Event, 0, len(subs))
-	if ok {
-		for _, subCh := range subs {
-			subChans = append(subChans, subCh)
+	// We record any sticky event under the same lock as building the subscriber list, so that a new subscriber gets
+	// either the sticky event or the published event, but not both
+	e.mu.Lock()
+	if _, ok := e.stickyEvents[eventType]; ok {
+		e.stickyEvents[eventType] = &evt
+	}
+	// Send to non-blocking subscribers inside the lock, which makes it safe to close the channel of a detached
+	// subscriber. Blocking subscribers are gathered to send to outside the lock
+	subs := e.subscribers[eventType]
+	blockingChans := make([]chan Event, 0, len(subs))
+	for subId, sub := range subs {
+		if sub.overflowPolicy == OverflowBlock {
+			blockingChans = append(blockingChans, sub.ch)
+			continue
+		}
+		select {
+		case sub.ch <- evt:
+			continue
+		default:
+		}
+		if e.metrics != nil {
+			e.metrics.dropped.WithLabelValues(string(eventType)).Inc()
+		}
+		switch sub.overflowPolicy {
+		case OverflowBlock, OverflowDrop:
+			// Drop the event for this subscriber
+		case OverflowDetach:
+			delete(subs, subId)
+			close(sub.ch)
+			e.logger.Warn(
+				"detached slow event subscriber with full queue",
+				"component", "event",
+				"event_type", string(eventType),
+				"subscriber_id", int(subId),
+			)
+			if e.metrics != nil {
+				e.metrics.subscribers.WithLabelValues(string(eventType)).Dec()
+			}
 		}
 	}
-	e.mu.RUnlock()
+	e.mu.Unlock()
 	// Send event on gathered channels
-	for _, subCh := range subChans {
+	for _, subCh := range blockingChans {
 		// NOTE: this is purposely a blocking operation to prevent dropping data
-		// XXX: do we maybe want to detect a blocked channel and temporarily set it aside
-		// to get the event sent to the other subscribers?
 		subCh <- evt
 	}
 	if e.metrics != nil {
@@ -19,6 +19,7 @@ import (
 	"time"
 
 	"github.com/blinklabs-io/dingo/event"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 func TestEventBusSingleSubscriber(t *testing.T) {
@@ -113,3 +114,181 @@ func TestEventBusUnsubscribe(t *testing.T) {
 		// NOTE: this is the expected way for the test to end
 	}
 }
+
+func TestEventBusSticky(t *testing.T) {
+	var testEvtType event.EventType = "test.event"
+	eb := event.NewEventBus(nil)
+	eb.SetSticky(testEvtType)
+	// Only the latest event is kept
+	for i := range 3 {
+		eb.Publish(testEvtType, event.NewEvent(testEvtType, i))
+	}
+	_, subCh := eb.Subscribe(testEvtType)
+	select {
+	case evt := <-subCh:
+		if v, ok := evt.Data.(int); !ok || v != 2 {
+			t.Fatalf("did not get expected sticky event: got %v", evt.Data)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatalf("timeout waiting for sticky event")
+	}
+	select {
+	case evt := <-subCh:
+		t.Fatalf("got unexpected extra event: %v", evt.Data)
+	default:
+	}
+	// Non-sticky event types aren't replayed
+	var otherEvtType event.EventType = "test.other"
+	eb.Publish(otherEvtType, event.NewEvent(otherEvtType, 1))
+	_, otherSubCh := eb.Subscribe(otherEvtType)
+	select {
+	case evt := <-otherSubCh:
+		t.Fatalf("got unexpected event for non-sticky type: %v", evt.Data)
+	default:
+	}
+}
+
+func TestEventBusOverflowDrop(t *testing.T) {
+	var testEvtType event.EventType = "test.event"
+	eb := event.NewEventBus(nil)
+	_, subCh := eb.Subscribe(
+		testEvtType,
+		event.WithQueueSize(2),
+		event.WithOverflowPolicy(event.OverflowDrop),
+	)
+	// This would block with the default policy
+	for i := range 5 {
+		eb.Publish(testEvtType, event.NewEvent(testEvtType, i))
+	}
+	for i := range 2 {
+		evt, ok := <-subCh
+		if !ok {
+			t.Fatalf("event channel closed unexpectedly")
+		}
+		if v, ok := evt.Data.(int); !ok || v != i {
+			t.Fatalf("did not get expected event: got %v, expected %d", evt.Data, i)
+		}
+	}
+	select {
+	case evt := <-subCh:
+		t.Fatalf("got unexpected event: %v", evt.Data)
+	default:
+	}
+	// The subscriber is still attached after dropping events
+	eb.Publish(testEvtType, event.NewEvent(testEvtType, 999))
+	select {
+	case evt := <-subCh:
+		if v, ok := evt.Data.(int); !ok || v != 999 {
+			t.Fatalf("did not get expected event: got %v", evt.Data)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatalf("timeout waiting for event")
+	}
+}
+
+func TestEventBusOverflowDetach(t *testing.T) {
+	var testEvtType event.EventType = "test.event"
+	eb := event.NewEventBus(nil)
+	_, subCh := eb.Subscribe(
+		testEvtType,
+		event.WithQueueSize(2),
+		event.WithOverflowPolicy(event.OverflowDetach),
+	)
+	for i := range 3 {
+		eb.Publish(testEvtType, event.NewEvent(testEvtType, i))
+	}
+	// Queued events are still delivered before the channel is closed
+	for i := range 2 {
+		evt, ok := <-subCh
+		if !ok {
+			t.Fatalf("event channel closed unexpectedly")
+		}
+		if v, ok := evt.Data.(int); !ok || v != i {
+			t.Fatalf("did not get expected event: got %v, expected %d", evt.Data, i)
+		}
+	}
+	select {
+	case evt, ok := <-subCh:
+		if ok {
+			t.Fatalf("got unexpected event: %v", evt.Data)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatalf("timeout waiting for event channel to close")
+	}
+}
+
+func TestEventBusMetrics(t *testing.T) {
+	var testEvtType event.EventType = "test.event"
+	promRegistry := prometheus.NewRegistry()
+	eb := event.NewEventBus(promRegistry)
+	eb.Subscribe(
+		testEvtType,
+		event.WithQueueSize(2),
+		event.WithOverflowPolicy(event.OverflowDrop),
+	)
+	for i := range 5 {
+		eb.Publish(testEvtType, event.NewEvent(testEvtType, i))
+	}
+	metricFamilies, err := promRegistry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error gathering metrics: %s", err)
+	}
+	metricValues := make(map[string]float64)
+	for _, mf := range metricFamilies {
+		for _, m := range mf.GetMetric() {
+			switch {
+			case m.GetCounter() != nil:
+				metricValues[mf.GetName()] += m.GetCounter().GetValue()
+			case m.GetGauge() != nil:
+				metricValues[mf.GetName()] += m.GetGauge().GetValue()
+			}
+		}
+	}
+	expectedValues := map[string]float64{
+		"event_total":                  5,
+		"event_dropped_total":          3,
+		"event_subscribers":            1,
+		"event_subscriber_queue_depth": 2,
+	}
+	for name, expected := range expectedValues {
+		if metricValues[name] != expected {
+			t.Errorf(
+				"did not get expected value for metric %s: got %v, expected %v",
+				name,
+				metricValues[name],
+				expected,
+			)
+		}
+	}
+}
+
+func TestSubscribeTyped(t *testing.T) {
+	type testEvent struct {
+		Value int
+	}
+	var testEvtType event.EventType = "test.event"
+	eb := event.NewEventBus(nil)
+	subCh, cancelFunc := event.SubscribeTyped[testEvent](eb, testEvtType)
+	// Events with a mismatched payload are skipped
+	eb.Publish(testEvtType, event.NewEvent(testEvtType, "bad"))
+	eb.Publish(testEvtType, event.NewEvent(testEvtType, testEvent{Value: 999}))
+	select {
+	case evt := <-subCh:
+		if evt.Value != 999 {
+			t.Fatalf("did not get expected event: got %d, expected 999", evt.Value)
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatalf("timeout waiting for event")
+	}
+	cancelFunc()
+	select {
+	case _, ok := <-subCh:
+		if ok {
+			t.Fatalf("received unexpected event")
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatalf("timeout waiting for event channel to close")
+	}
+	// Calling the cancel function again is harmless
+	cancelFunc()
+}
@@ -15,6 +15,8 @@
 package event
 
 import (
+	"strconv"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -22,6 +24,7 @@ import (
 type eventMetrics struct {
 	eventsTotal *prometheus.CounterVec
 	subscribers *prometheus.GaugeVec
+	dropped     *prometheus.CounterVec
 }
 
 func (e *EventBus) initMetrics(promRegistry prometheus.Registerer) {
@@ -41,4 +44,44 @@ func (e *EventBus) initMetrics(promRegistry prometheus.Registerer) {
 		},
 		[]string{"type"},
 	)
+	e.metrics.dropped = promautoFactory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "event_dropped_total",
+			Help: "events not delivered to a subscriber with a full queue by type",
+		},
+		[]string{"type"},
+	)
+	promRegistry.MustRegister(&queueDepthCollector{eventBus: e})
+}
+
+var queueDepthDesc = prometheus.NewDesc(
+	"event_subscriber_queue_depth",
+	"queued events by event type and subscriber",
+	[]string{"type", "subscriber"},
+	nil,
+)
+
+// queueDepthCollector reports the current queue depth of each subscriber when metrics are gathered
+type queueDepthCollector struct {
+	eventBus *EventBus
+}
+
+func (c *queueDepthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDepthDesc
+}
+
+func (c *queueDepthCollector) Collect(ch chan<- prometheus.Metric) {
+	c.eventBus.mu.Lock()
+	defer c.eventBus.mu.Unlock()
+	for eventType, subs := range c.eventBus.subscribers {
+		for subId, sub := range subs {
+			ch <- prometheus.MustNewConstMetric(
+				queueDepthDesc,
+				prometheus.GaugeValue,
+				float64(len(sub.ch)),
+				string(eventType),
+				strconv.Itoa(int(subId)),
+			)
+		}
+	}
 }
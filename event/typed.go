@@ -0,0 +1,74 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package event
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// SubscribeTyped subscribes to events of a particular type and delivers their payloads as T via a channel, along with
+// a function to cancel the subscription and close the channel. Events with a payload that isn't a T are skipped and
+// logged
+func SubscribeTyped[T any](
+	e *EventBus,
+	eventType EventType,
+	opts ...SubscribeOptionFunc,
+) (<-chan T, func()) {
+	subId, evtCh := e.Subscribe(eventType, opts...)
+	retCh := make(chan T, cap(evtCh))
+	doneCh := make(chan struct{})
+	go func() {
+		defer close(retCh)
+		for {
+			select {
+			case <-doneCh:
+				return
+			case evt, ok := <-evtCh:
+				if !ok {
+					// The subscriber was detached
+					return
+				}
+				data, ok := evt.Data.(T)
+				if !ok {
+					e.getLogger().Warn(
+						fmt.Sprintf(
+							"skipping event with unexpected payload type: got %T, expected %s",
+							evt.Data,
+							reflect.TypeFor[T](),
+						),
+						"component", "event",
+						"event_type", string(eventType),
+					)
+					continue
+				}
+				select {
+				case <-doneCh:
+					return
+				case retCh <- data:
+				}
+			}
+		}
+	}()
+	var cancelOnce sync.Once
+	cancelFunc := func() {
+		cancelOnce.Do(func() {
+			e.Unsubscribe(eventType, subId)
+			close(doneCh)
+		})
+	}
+	return retCh, cancelFunc
+}
@@ -0,0 +1,191 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/ledger/eras"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	gledger "github.com/blinklabs-io/gouroboros/ledger"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+	_cbor "github.com/fxamacker/cbor/v2"
+)
+
+const (
+	// Number of imported blocks to add to the chain at a time
+	importBlockBatchSize = 50
+)
+
+// exportBlock is the CBOR record written for each block by ExportBlocks
+type exportBlock struct {
+	cbor.StructAsArray
+	Type uint
+	Cbor cbor.RawMessage
+}
+
+// ExportBlocks writes the blocks between the specified points, inclusive, to the writer in chain order. Each block is
+// written as a CBOR array containing the block type and the original block CBOR. Blocks are read one at a time from the
+// block store, so this doesn't block sync. An error is returned if either point isn't on the local chain, or if any
+// block in the range has been pruned
+func (n *Node) ExportBlocks(from, to ocommon.Point, w io.Writer) error {
+	if n.chainManager == nil {
+		return ErrNodeNotRunning
+	}
+	blockStore := n.chainManager.BlockStore()
+	fromBlock, err := blockStore.Get(from, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get start block: %w", err)
+	}
+	toBlock, err := blockStore.Get(to, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get end block: %w", err)
+	}
+	if toBlock.ID < fromBlock.ID {
+		return errors.New("end point is before start point")
+	}
+	var prevHash []byte
+	for blockIndex := fromBlock.ID; blockIndex <= toBlock.ID; blockIndex++ {
+		block, err := blockStore.GetByIndex(blockIndex, nil)
+		if err != nil {
+			if errors.Is(err, database.ErrBlockNotFound) {
+				return fmt.Errorf(
+					"block %d in range is no longer available, possibly due to a rollback: %w",
+					blockIndex,
+					err,
+				)
+			}
+			return fmt.Errorf("failed to get block: %w", err)
+		}
+		// Make sure that a rollback didn't replace part of the range while we were reading it
+		if prevHash != nil && string(block.PrevHash) != string(prevHash) {
+			return errors.New("chain changed during export")
+		}
+		prevHash = block.Hash
+		if block.Pruned {
+			return fmt.Errorf(
+				"block at slot %d is no longer available: %w: %w",
+				block.Slot,
+				ErrPointTooOld,
+				database.ErrBlockPruned,
+			)
+		}
+		record, err := cbor.Encode(
+			&exportBlock{
+				Type: block.Type,
+				Cbor: block.Cbor,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to encode block: %w", err)
+		}
+		if _, err := w.Write(record); err != nil {
+			return fmt.Errorf("failed to write block: %w", err)
+		}
+	}
+	return nil
+}
+
+// ImportBlocks reads blocks in the format written by ExportBlocks from the reader and adds them to the local chain in
+// order, where they are picked up by the ledger. This can be used to bootstrap a node from a trusted snapshot. Each
+// block must extend the previous one, starting from the current chain tip, and must not be from an earlier era than
+// the block before it
+func (n *Node) ImportBlocks(r io.Reader) error {
+	if n.chainManager == nil {
+		return ErrNodeNotRunning
+	}
+	c := n.chainManager.PrimaryChain()
+	// Start from the current chain tip
+	var prevHash []byte
+	var prevEraId uint
+	tipBlock, err := n.chainManager.BlockStore().Tip(nil)
+	if err != nil {
+		if !errors.Is(err, database.ErrBlockNotFound) {
+			return fmt.Errorf("failed to get chain tip: %w", err)
+		}
+	} else {
+		prevHash = tipBlock.Hash
+		if len(tipBlock.Cbor) > 0 {
+			tmpBlock, err := tipBlock.Decode()
+			if err != nil {
+				return fmt.Errorf("failed to decode chain tip block: %w", err)
+			}
+			prevEraId = uint(tmpBlock.Era().Id)
+		}
+	}
+	decoder := _cbor.NewDecoder(r)
+	batch := make([]gledger.Block, 0, importBlockBatchSize)
+	for {
+		var record exportBlock
+		if err := decoder.Decode(&record); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return fmt.Errorf("failed to decode block record: %w", err)
+		}
+		block, err := gledger.NewBlockFromCbor(record.Type, record.Cbor)
+		if err != nil {
+			return fmt.Errorf("failed to decode block: %w", err)
+		}
+		// Check that the block extends the previous block
+		if prevHash != nil &&
+			string(block.PrevHash().Bytes()) != string(prevHash) {
+			return fmt.Errorf(
+				"block %s at slot %d does not extend previous block %s",
+				block.Hash().String(),
+				block.SlotNumber(),
+				hex.EncodeToString(prevHash),
+			)
+		}
+		prevHash = block.Hash().Bytes()
+		// Check that the block is from a known era that doesn't precede the previous block's era
+		eraId := uint(block.Era().Id)
+		if eraId >= uint(len(eras.Eras)) {
+			return fmt.Errorf(
+				"block %s at slot %d is from unknown era %d",
+				block.Hash().String(),
+				block.SlotNumber(),
+				eraId,
+			)
+		}
+		if eraId < prevEraId {
+			return fmt.Errorf(
+				"block %s at slot %d is from era %s, which precedes era %s of the previous block",
+				block.Hash().String(),
+				block.SlotNumber(),
+				eras.Eras[eraId].Name,
+				eras.Eras[prevEraId].Name,
+			)
+		}
+		prevEraId = eraId
+		batch = append(batch, block)
+		if len(batch) == importBlockBatchSize {
+			if err := c.AddBlocks(batch); err != nil {
+				return fmt.Errorf("failed to add blocks: %w", err)
+			}
+			batch = batch[:0]
+		}
+	}
+	if len(batch) > 0 {
+		if err := c.AddBlocks(batch); err != nil {
+			return fmt.Errorf("failed to add blocks: %w", err)
+		}
+	}
+	return nil
+}
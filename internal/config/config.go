@@ -58,6 +58,8 @@ type Config struct {
 	RelayPort       uint   `                   yaml:"relayPort"       envconfig:"port"`
 	UtxorpcPort     uint   `split_words:"true" yaml:"utxorpcPort"`
 	IntersectTip    bool   `split_words:"true" yaml:"intersectTip"`
+	AdminApiAddress string `split_words:"true" yaml:"adminApiAddress"`
+	AdminApiToken   string `split_words:"true" yaml:"adminApiToken"`
 }
 
 var globalConfig = &Config{
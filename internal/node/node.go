@@ -57,48 +57,6 @@ func Run(cfg *config.Config, logger *slog.Logger) error {
 			"component", "node",
 		)
 	}
-	listeners := []dingo.ListenerConfig{}
-	if cfg.RelayPort > 0 {
-		// Public "relay" port (node-to-node)
-		listeners = append(
-			listeners,
-			dingo.ListenerConfig{
-				ListenNetwork: "tcp",
-				ListenAddress: fmt.Sprintf(
-					"%s:%d",
-					cfg.BindAddr,
-					cfg.RelayPort,
-				),
-				ReuseAddress: true,
-			},
-		)
-	}
-	if cfg.PrivatePort > 0 {
-		// Private TCP port (node-to-client)
-		listeners = append(
-			listeners,
-			dingo.ListenerConfig{
-				ListenNetwork: "tcp",
-				ListenAddress: fmt.Sprintf(
-					"%s:%d",
-					cfg.PrivateBindAddr,
-					cfg.PrivatePort,
-				),
-				UseNtC: true,
-			},
-		)
-	}
-	if cfg.SocketPath != "" {
-		// Private UNIX socket (node-to-client)
-		listeners = append(
-			listeners,
-			dingo.ListenerConfig{
-				ListenNetwork: "unix",
-				ListenAddress: cfg.SocketPath,
-				UseNtC:        true,
-			},
-		)
-	}
 	d, err := dingo.New(
 		dingo.NewConfig(
 			dingo.WithIntersectTip(cfg.IntersectTip),
@@ -107,11 +65,21 @@ func Run(cfg *config.Config, logger *slog.Logger) error {
 			dingo.WithBadgerCacheSize(cfg.BadgerCacheSize),
 			dingo.WithNetwork(cfg.Network),
 			dingo.WithCardanoNodeConfig(nodeCfg),
-			dingo.WithListeners(listeners...),
+			// Public "relay" port (node-to-node)
+			dingo.WithNodeToNodeListenAddress(cfg.BindAddr, cfg.RelayPort),
+			// Private TCP port (node-to-client)
+			dingo.WithNodeToClientListenAddress(
+				cfg.PrivateBindAddr,
+				cfg.PrivatePort,
+			),
+			// Private UNIX socket (node-to-client)
+			dingo.WithNodeToClientSocketPath(cfg.SocketPath),
 			dingo.WithOutboundSourcePort(cfg.RelayPort),
 			dingo.WithUtxorpcPort(cfg.UtxorpcPort),
 			dingo.WithUtxorpcTlsCertFilePath(cfg.TlsCertFilePath),
 			dingo.WithUtxorpcTlsKeyFilePath(cfg.TlsKeyFilePath),
+			// Admin API (disabled if address is empty)
+			dingo.WithAdminApi(cfg.AdminApiAddress, cfg.AdminApiToken),
 			// Enable metrics with default prometheus registry
 			dingo.WithPrometheusRegistry(prometheus.DefaultRegisterer),
 			// TODO: make this configurable (#387)
@@ -0,0 +1,169 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package chainsynctest provides an in-process harness for testing the chainsync server logic without a real
+// network. A chainsync client and server are connected over an in-memory pipe, with the server backed by a
+// MockLedgerState
+package chainsynctest
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/blinklabs-io/dingo/chainsync"
+	ouroboros "github.com/blinklabs-io/gouroboros"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+const (
+	// Network magic used for both sides of the harness connection
+	harnessNetworkMagic = 764824073
+	// Number of client events to buffer before the client blocks
+	harnessEventQueueSize = 100
+)
+
+// ErrEventTimeout is returned by Harness.NextEvent when no event is received from the server in time
+var ErrEventTimeout = errors.New("timed out waiting for chainsync event")
+
+// ClientEvent describes a roll forward or roll backward received by the harness chainsync client
+type ClientEvent struct {
+	Rollback  bool
+	Point     ocommon.Point // Rollback point
+	BlockType uint
+	BlockCbor []byte
+	Tip       ochainsync.Tip
+}
+
+// Harness connects a chainsync client to a chainsync server backed by a chainsync.State
+type Harness struct {
+	LedgerState *MockLedgerState
+	State       *chainsync.State
+	clientConn  *ouroboros.Connection
+	serverConn  *ouroboros.Connection
+	eventChan   chan ClientEvent
+}
+
+// NewHarness returns a harness with a chainsync client connected to a chainsync server for the specified ledger
+// state. The server uses a new chainsync.State unless one is provided
+func NewHarness(
+	ledgerState *MockLedgerState,
+	state *chainsync.State,
+) (*Harness, error) {
+	if state == nil {
+		state = chainsync.NewState(nil, ledgerState, nil)
+	}
+	h := &Harness{
+		LedgerState: ledgerState,
+		State:       state,
+		eventChan:   make(chan ClientEvent, harnessEventQueueSize),
+	}
+	clientPipe, serverPipe := net.Pipe()
+	// The handshake needs both sides running, so we start the server in the background
+	serverResultChan := make(chan error, 1)
+	go func() {
+		serverConn, err := ouroboros.NewConnection(
+			ouroboros.WithConnection(serverPipe),
+			ouroboros.WithNetworkMagic(harnessNetworkMagic),
+			ouroboros.WithServer(true),
+			ouroboros.WithChainSyncConfig(
+				ochainsync.NewConfig(
+					state.ServerConnOpts()...,
+				),
+			),
+		)
+		h.serverConn = serverConn
+		serverResultChan <- err
+	}()
+	clientConn, err := ouroboros.NewConnection(
+		ouroboros.WithConnection(clientPipe),
+		ouroboros.WithNetworkMagic(harnessNetworkMagic),
+		ouroboros.WithChainSyncConfig(
+			ochainsync.NewConfig(
+				ochainsync.WithRollForwardRawFunc(h.clientRollForward),
+				ochainsync.WithRollBackwardFunc(h.clientRollBackward),
+			),
+		),
+	)
+	if err != nil {
+		serverPipe.Close()
+		<-serverResultChan
+		return nil, fmt.Errorf("failed to create client connection: %w", err)
+	}
+	h.clientConn = clientConn
+	if err := <-serverResultChan; err != nil {
+		clientConn.Close()
+		return nil, fmt.Errorf("failed to create server connection: %w", err)
+	}
+	return h, nil
+}
+
+// Client returns the chainsync client
+func (h *Harness) Client() *ochainsync.Client {
+	return h.clientConn.ChainSync().Client
+}
+
+// ServerConnectionId returns the connection ID used by the server for the client
+func (h *Harness) ServerConnectionId() ouroboros.ConnectionId {
+	return h.serverConn.Id()
+}
+
+// NextEvent returns the next roll forward or roll backward received by the client, or ErrEventTimeout if none is
+// received within the specified timeout
+func (h *Harness) NextEvent(timeout time.Duration) (ClientEvent, error) {
+	select {
+	case evt := <-h.eventChan:
+		return evt, nil
+	case <-time.After(timeout):
+		return ClientEvent{}, ErrEventTimeout
+	}
+}
+
+// Close removes the client from the chainsync state and closes both sides of the connection
+func (h *Harness) Close() error {
+	h.State.RemoveClient(h.serverConn.Id())
+	return errors.Join(
+		h.clientConn.Close(),
+		h.serverConn.Close(),
+	)
+}
+
+func (h *Harness) clientRollForward(
+	_ ochainsync.CallbackContext,
+	blockType uint,
+	blockCbor []byte,
+	tip ochainsync.Tip,
+) error {
+	h.eventChan <- ClientEvent{
+		BlockType: blockType,
+		BlockCbor: blockCbor,
+		Tip:       tip,
+	}
+	return nil
+}
+
+func (h *Harness) clientRollBackward(
+	_ ochainsync.CallbackContext,
+	point ocommon.Point,
+	tip ochainsync.Tip,
+) error {
+	h.eventChan <- ClientEvent{
+		Rollback: true,
+		Point:    point,
+		Tip:      tip,
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package chainsynctest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	gledger "github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+// MockBlock is a minimal block that can be added to a MockLedgerState chain. Its CBOR content is an encoding of its
+// slot and block number, and its hash is derived from that
+type MockBlock struct {
+	gledger.ConwayBlock
+	MockPrevHash    lcommon.Blake2b256
+	MockSlot        uint64
+	MockBlockNumber uint64
+}
+
+// NewMockBlock returns a new mock block at the specified slot that extends the specified block. The first block in a
+// chain is created by passing a nil previous block
+func NewMockBlock(prevBlock *MockBlock, slot uint64) *MockBlock {
+	ret := &MockBlock{
+		MockSlot:        slot,
+		MockBlockNumber: 1,
+	}
+	if prevBlock != nil {
+		ret.MockPrevHash = prevBlock.Hash()
+		ret.MockBlockNumber = prevBlock.MockBlockNumber + 1
+	}
+	return ret
+}
+
+func (b *MockBlock) Hash() lcommon.Blake2b256 {
+	return lcommon.Blake2b256Hash(b.Cbor())
+}
+
+func (b *MockBlock) PrevHash() lcommon.Blake2b256 {
+	return b.MockPrevHash
+}
+
+func (b *MockBlock) SlotNumber() uint64 {
+	return b.MockSlot
+}
+
+func (b *MockBlock) BlockNumber() uint64 {
+	return b.MockBlockNumber
+}
+
+func (b *MockBlock) Cbor() []byte {
+	cborData, _ := cbor.Encode([]uint64{b.MockSlot, b.MockBlockNumber})
+	return cborData
+}
+
+// Point returns the chain point for the block
+func (b *MockBlock) Point() ocommon.Point {
+	return ocommon.NewPoint(b.MockSlot, b.Hash().Bytes())
+}
+
+// MockLedgerState is an in-memory ledger state for serving chainsync clients in tests. It satisfies the
+// chainsync.LedgerState interface, and is backed by a non-persistent chain
+type MockLedgerState struct {
+	sync.RWMutex
+	chain *chain.Chain
+}
+
+// NewMockLedgerState returns a new MockLedgerState with an empty chain
+func NewMockLedgerState() (*MockLedgerState, error) {
+	cm, err := chain.NewManager(nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create chain manager: %w", err)
+	}
+	return &MockLedgerState{
+		chain: cm.PrimaryChain(),
+	}, nil
+}
+
+// AddBlocks adds the specified blocks to the chain in order
+func (m *MockLedgerState) AddBlocks(blocks ...*MockBlock) error {
+	for _, block := range blocks {
+		if err := m.chain.AddBlock(block, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rollback rolls the chain back to the specified point
+func (m *MockLedgerState) Rollback(point ocommon.Point) error {
+	return m.chain.Rollback(point)
+}
+
+// GetIntersectPoint returns the latest of the specified points found on the chain, or nil if none are found
+func (m *MockLedgerState) GetIntersectPoint(
+	points []ocommon.Point,
+) (*ocommon.Point, error) {
+	var ret *ocommon.Point
+	for _, point := range points {
+		if ret != nil && point.Slot < ret.Slot {
+			continue
+		}
+		// Check for special origin point
+		if point.Slot == 0 && len(point.Hash) == 0 {
+			if ret == nil {
+				ret = &ocommon.Point{}
+			}
+			continue
+		}
+		tmpBlock, err := m.chain.BlockByPoint(point, nil)
+		if err != nil {
+			if errors.Is(err, chain.ErrBlockNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		tmpPoint := ocommon.NewPoint(tmpBlock.Slot, tmpBlock.Hash)
+		ret = &tmpPoint
+	}
+	return ret, nil
+}
+
+// GetChainFromPoint returns a ChainIterator starting at the specified point
+func (m *MockLedgerState) GetChainFromPoint(
+	point ocommon.Point,
+	inclusive bool,
+) (*chain.ChainIterator, error) {
+	return m.chain.FromPoint(point, inclusive)
+}
+
+// Tip returns the current chain tip
+func (m *MockLedgerState) Tip() ochainsync.Tip {
+	return m.chain.Tip()
+}
@@ -189,3 +189,85 @@ func (ls *LedgerState) processTransactionCertificates(
 	}
 	return nil
 }
+
+// newCertificateEvent builds a CertificateEvent with the credentials and pool ID involved in the certificate
+func newCertificateEvent(
+	point pcommon.Point,
+	txHash lcommon.Blake2b256,
+	tmpCert lcommon.Certificate,
+) CertificateEvent {
+	evt := CertificateEvent{
+		Point:       point,
+		TxHash:      txHash,
+		Certificate: tmpCert,
+	}
+	poolId := func(poolKeyHash []byte) string {
+		return lcommon.PoolId(lcommon.NewBlake2b224(poolKeyHash)).String()
+	}
+	switch cert := tmpCert.(type) {
+	case *lcommon.StakeRegistrationCertificate:
+		evt.CertificateType = lcommon.CertificateTypeStakeRegistration
+		evt.StakeCredential = cert.StakeRegistration.Credential.Bytes()
+	case *lcommon.StakeDeregistrationCertificate:
+		evt.CertificateType = lcommon.CertificateTypeStakeDeregistration
+		evt.StakeCredential = cert.StakeDeregistration.Credential.Bytes()
+	case *lcommon.StakeDelegationCertificate:
+		evt.CertificateType = lcommon.CertificateTypeStakeDelegation
+		if cert.StakeCredential != nil {
+			evt.StakeCredential = cert.StakeCredential.Credential.Bytes()
+		}
+		evt.PoolId = poolId(cert.PoolKeyHash.Bytes())
+	case *lcommon.PoolRegistrationCertificate:
+		evt.CertificateType = lcommon.CertificateTypePoolRegistration
+		evt.PoolId = poolId(cert.Operator.Bytes())
+	case *lcommon.PoolRetirementCertificate:
+		evt.CertificateType = lcommon.CertificateTypePoolRetirement
+		evt.PoolId = poolId(cert.PoolKeyHash.Bytes())
+	case *lcommon.GenesisKeyDelegationCertificate:
+		evt.CertificateType = lcommon.CertificateTypeGenesisKeyDelegation
+	case *lcommon.MoveInstantaneousRewardsCertificate:
+		evt.CertificateType = lcommon.CertificateTypeMoveInstantaneousRewards
+	case *lcommon.RegistrationCertificate:
+		evt.CertificateType = lcommon.CertificateTypeRegistration
+		evt.StakeCredential = cert.StakeCredential.Credential.Bytes()
+	case *lcommon.DeregistrationCertificate:
+		evt.CertificateType = lcommon.CertificateTypeDeregistration
+		evt.StakeCredential = cert.StakeCredential.Credential.Bytes()
+	case *lcommon.VoteDelegationCertificate:
+		evt.CertificateType = lcommon.CertificateTypeVoteDelegation
+		evt.StakeCredential = cert.StakeCredential.Credential.Bytes()
+		evt.Drep = cert.Drep.Credential
+	case *lcommon.StakeVoteDelegationCertificate:
+		evt.CertificateType = lcommon.CertificateTypeStakeVoteDelegation
+		evt.StakeCredential = cert.StakeCredential.Credential.Bytes()
+		evt.PoolId = poolId(cert.PoolKeyHash)
+		evt.Drep = cert.Drep.Credential
+	case *lcommon.StakeRegistrationDelegationCertificate:
+		evt.CertificateType = lcommon.CertificateTypeStakeRegistrationDelegation
+		evt.StakeCredential = cert.StakeCredential.Credential.Bytes()
+		evt.PoolId = poolId(cert.PoolKeyHash)
+	case *lcommon.VoteRegistrationDelegationCertificate:
+		evt.CertificateType = lcommon.CertificateTypeVoteRegistrationDelegation
+		evt.StakeCredential = cert.StakeCredential.Credential.Bytes()
+		evt.Drep = cert.Drep.Credential
+	case *lcommon.StakeVoteRegistrationDelegationCertificate:
+		evt.CertificateType = lcommon.CertificateTypeStakeVoteRegistrationDelegation
+		evt.StakeCredential = cert.StakeCredential.Credential.Bytes()
+		evt.PoolId = poolId(cert.PoolKeyHash.Bytes())
+		evt.Drep = cert.Drep.Credential
+	case *lcommon.AuthCommitteeHotCertificate:
+		evt.CertificateType = lcommon.CertificateTypeAuthCommitteeHot
+	case *lcommon.ResignCommitteeColdCertificate:
+		evt.CertificateType = lcommon.CertificateTypeResignCommitteeCold
+	case *lcommon.RegistrationDrepCertificate:
+		evt.CertificateType = lcommon.CertificateTypeRegistrationDrep
+		evt.Drep = cert.DrepCredential.Credential.Bytes()
+	case *lcommon.DeregistrationDrepCertificate:
+		evt.CertificateType = lcommon.CertificateTypeDeregistrationDrep
+		evt.Drep = cert.DrepCredential.Credential.Bytes()
+	case *lcommon.UpdateDrepCertificate:
+		evt.CertificateType = lcommon.CertificateTypeUpdateDrep
+		evt.Drep = cert.DrepCredential.Credential.Bytes()
+	}
+	return evt
+}
@@ -23,18 +23,24 @@ import (
 	"time"
 
 	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/dingo/connmanager"
 	"github.com/blinklabs-io/dingo/database"
 	"github.com/blinklabs-io/dingo/event"
 	ouroboros "github.com/blinklabs-io/gouroboros"
 	"github.com/blinklabs-io/gouroboros/cbor"
 	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
 	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
 )
 
 const (
-	// Max number of blocks to fetch in a single blockfetch call
+	// Default max number of blocks to fetch in a single blockfetch call
 	// This prevents us exceeding the configured recv queue size in the block-fetch protocol
-	blockfetchBatchSize = 500
+	DefaultBlockfetchBatchSize = 500
+
+	// Default distance in slots from the upstream tip at which bulk sync mode is switched off, if it can't be
+	// determined from the Shelley genesis. This matches the security parameter (k) on mainnet
+	DefaultBulkSyncSlotThreshold = 2160
 
 	// TODO: calculate from protocol params
 	// Number of slots from upstream tip to stop doing blockfetch batches
@@ -43,6 +49,15 @@ const (
 	// Timeout for updates on a blockfetch operation. This is based on a 2s BatchStart
 	// and a 2s Block timeout for blockfetch
 	blockfetchBusyTimeout = 5 * time.Second
+
+	// Minimum interval between blockfetch progress events
+	blockfetchProgressInterval = 5 * time.Second
+
+	// Number of blockfetch batches worth of block headers to collect ahead of blockfetch
+	blockfetchHeaderBatches = 4
+
+	// Number of blockfetch batches worth of block headers to collect ahead of blockfetch in bulk sync mode
+	bulkSyncBlockfetchHeaderBatches = 8
 )
 
 func (ls *LedgerState) handleEventChainsync(evt event.Event) {
@@ -74,6 +89,16 @@ func (ls *LedgerState) handleEventBlockfetch(evt event.Event) {
 	ls.chainsyncBlockfetchMutex.Lock()
 	defer ls.chainsyncBlockfetchMutex.Unlock()
 	e := evt.Data.(BlockfetchEvent)
+	// Ignore events from connections other than the one we requested the current range from, such as a late
+	// batch done from a connection that closed and had its range re-requested elsewhere
+	if e.ConnectionId != ls.chainsyncBlockfetchConnId {
+		ls.config.Logger.Debug(
+			"ignoring blockfetch event from unexpected connection",
+			"component", "ledger",
+			"connection_id", e.ConnectionId.String(),
+		)
+		return
+	}
 	if e.BatchDone {
 		if err := ls.handleEventBlockfetchBatchDone(e); err != nil {
 			// TODO: actually handle this error
@@ -94,6 +119,86 @@ func (ls *LedgerState) handleEventBlockfetch(evt event.Event) {
 	}
 }
 
+func (ls *LedgerState) handleEventConnectionClosed(evt event.Event) {
+	ls.chainsyncBlockfetchMutex.Lock()
+	defer ls.chainsyncBlockfetchMutex.Unlock()
+	e := evt.Data.(connmanager.ConnectionClosedEvent)
+	if err := ls.handleBlockfetchConnectionClosed(e.ConnectionId); err != nil {
+		ls.config.Logger.Error(
+			fmt.Sprintf(
+				"ledger: failed to resume blockfetch after connection closed: %s",
+				err,
+			),
+		)
+	}
+}
+
+// handleBlockfetchConnectionClosed re-requests the unfetched part of the in-flight block range from another peer if
+// the connection it was requested from has closed
+func (ls *LedgerState) handleBlockfetchConnectionClosed(
+	connId ouroboros.ConnectionId,
+) error {
+	if ls.chainsyncBlockfetchReadyChan == nil ||
+		ls.chainsyncBlockfetchConnId != connId {
+		return nil
+	}
+	// Cancel our blockfetch timeout watcher
+	if ls.chainsyncBlockfetchBatchDoneChan != nil {
+		close(ls.chainsyncBlockfetchBatchDoneChan)
+		ls.chainsyncBlockfetchBatchDoneChan = nil
+	}
+	// Process the blocks we received before the connection closed. This removes their headers from the chain, which
+	// leaves only the unfetched suffix of the range
+	if err := ls.processBlockEvents(); err != nil {
+		ls.blockfetchRequestRangeCleanup(true)
+		return fmt.Errorf("process block events: %w", err)
+	}
+	if ls.chain.HeaderCount() == 0 {
+		ls.blockfetchRequestRangeCleanup(true)
+		return nil
+	}
+	// Find another connection to request the rest of the range from
+	var nextConnId *ouroboros.ConnectionId
+	if ls.config.BlockfetchConnectionIdsFunc != nil {
+		for _, tmpConnId := range ls.config.BlockfetchConnectionIdsFunc() {
+			if tmpConnId != connId {
+				nextConnId = &tmpConnId
+				break
+			}
+		}
+	}
+	if nextConnId == nil {
+		ls.blockfetchRequestRangeCleanup(true)
+		ls.config.Logger.Warn(
+			"no connections available to resume blockfetch",
+			"component", "ledger",
+		)
+		return nil
+	}
+	ls.blockfetchRequestRangeCleanup(false)
+	headerStart, headerEnd := ls.chain.HeaderRange(ls.blockfetchBatchSize())
+	ls.config.Logger.Debug(
+		fmt.Sprintf(
+			"resuming blockfetch of %d.%s to %d.%s from %s",
+			headerStart.Slot,
+			hex.EncodeToString(headerStart.Hash),
+			headerEnd.Slot,
+			hex.EncodeToString(headerEnd.Hash),
+			nextConnId.String(),
+		),
+		"component", "ledger",
+	)
+	if err := ls.blockfetchRequestRangeStart(
+		*nextConnId,
+		headerStart,
+		headerEnd,
+	); err != nil {
+		ls.blockfetchRequestRangeCleanup(true)
+		return err
+	}
+	return nil
+}
+
 func (ls *LedgerState) handleEventChainsyncRollback(e ChainsyncEvent) error {
 	if ls.chainsyncState == SyncingChainsyncState {
 		ls.config.Logger.Warn(fmt.Sprintf("ledger: rolling back to %d.%s", e.Point.Slot, hex.EncodeToString(e.Point.Hash)))
@@ -102,17 +207,58 @@ func (ls *LedgerState) handleEventChainsyncRollback(e ChainsyncEvent) error {
 	if err := ls.chain.Rollback(e.Point); err != nil {
 		return fmt.Errorf("chain rollback failed: %w", err)
 	}
+	// Drop any cached intersect points that no longer exist on our chain
+	ls.intersectCache.invalidateAfter(e.Point)
 	return nil
 }
 
+// checkBulkSyncDone switches off bulk sync mode once the specified point is close enough to the upstream tip
+func (ls *LedgerState) checkBulkSyncDone(point ocommon.Point, tip ochainsync.Tip) {
+	if !ls.bulkSync.Load() {
+		return
+	}
+	threshold := ls.bulkSyncSlotThreshold()
+	if point.Slot < tip.Point.Slot &&
+		tip.Point.Slot-point.Slot > threshold {
+		return
+	}
+	ls.bulkSync.Store(false)
+	ls.config.Logger.Info(
+		fmt.Sprintf(
+			"switching from bulk sync to normal mode at slot %d, within %d slots of upstream tip",
+			point.Slot,
+			threshold,
+		),
+		"component", "ledger",
+	)
+}
+
+func (ls *LedgerState) bulkSyncSlotThreshold() uint64 {
+	if ls.config.BulkSyncSlotThreshold > 0 {
+		return ls.config.BulkSyncSlotThreshold
+	}
+	if ls.config.CardanoNodeConfig != nil {
+		if securityParam := ls.config.CardanoNodeConfig.SecurityParam(); securityParam > 0 {
+			return uint64(securityParam)
+		}
+	}
+	return DefaultBulkSyncSlotThreshold
+}
+
 func (ls *LedgerState) handleEventChainsyncBlockHeader(e ChainsyncEvent) error {
 	if ls.chainsyncState == RollbackChainsyncState {
 		ls.config.Logger.Info(fmt.Sprintf("ledger: switched to fork at %d.%s", e.Point.Slot, hex.EncodeToString(e.Point.Hash)))
 		ls.metrics.forks.Add(1)
 	}
 	ls.chainsyncState = SyncingChainsyncState
+	// Record upstream tip for progress reporting
+	ls.chainsyncUpstreamTip = e.Tip
+	ls.checkBulkSyncDone(e.Point, e.Tip)
 	// Allow us to build up a few blockfetch batches worth of headers
-	allowedHeaderCount := blockfetchBatchSize * 4
+	allowedHeaderCount := ls.blockfetchBatchSize() * blockfetchHeaderBatches
+	if ls.bulkSync.Load() {
+		allowedHeaderCount = ls.blockfetchBatchSize() * bulkSyncBlockfetchHeaderBatches
+	}
 	headerCount := ls.chain.HeaderCount()
 	// Wait for current blockfetch batch to finish before we collect more block headers
 	if headerCount >= allowedHeaderCount {
@@ -153,9 +299,9 @@ func (ls *LedgerState) handleEventChainsyncBlockHeader(e ChainsyncEvent) error {
 		return nil
 	}
 	// Request next bulk range
-	headerStart, headerEnd := ls.chain.HeaderRange(blockfetchBatchSize)
+	headerStart, headerEnd := ls.chain.HeaderRange(ls.blockfetchBatchSize())
 	err := ls.blockfetchRequestRangeStart(
-		e.ConnectionId,
+		ls.blockfetchSelectConnId(e.ConnectionId),
 		headerStart,
 		headerEnd,
 	)
@@ -166,7 +312,6 @@ func (ls *LedgerState) handleEventChainsyncBlockHeader(e ChainsyncEvent) error {
 	return nil
 }
 
-//nolint:unparam
 func (ls *LedgerState) handleEventBlockfetchBlock(e BlockfetchEvent) error {
 	ls.chainsyncBlockEvents = append(
 		ls.chainsyncBlockEvents,
@@ -174,9 +319,63 @@ func (ls *LedgerState) handleEventBlockfetchBlock(e BlockfetchEvent) error {
 	)
 	// Update busy time in order to detect fetch timeout
 	ls.chainsyncBlockfetchBusyTime = time.Now()
+	// Update progress
+	ls.blockfetchProgress.BlocksFetched++
+	ls.blockfetchProgress.BytesFetched += uint64(len(e.Block.Cbor()))
+	ls.blockfetchProgress.CurrentSlot = e.Point.Slot
+	if time.Since(ls.blockfetchProgressTime) >= blockfetchProgressInterval {
+		ls.publishBlockfetchProgress(e.Block.BlockNumber())
+	}
+	// Process pending blocks early if we've exceeded our in-flight bytes limit
+	ls.chainsyncBlockEventsBytes += len(e.Block.Cbor())
+	if ls.config.BlockfetchMaxInflightBytes > 0 &&
+		ls.chainsyncBlockEventsBytes >= ls.config.BlockfetchMaxInflightBytes {
+		if err := ls.processBlockEvents(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
+func (ls *LedgerState) blockfetchBatchSize() int {
+	if ls.config.BlockfetchBatchSize > 0 {
+		return ls.config.BlockfetchBatchSize
+	}
+	return DefaultBlockfetchBatchSize
+}
+
+// blockfetchSelectConnId returns the preferred connection to request a block range from, falling back to the
+// specified connection if none are available
+func (ls *LedgerState) blockfetchSelectConnId(
+	connId ouroboros.ConnectionId,
+) ouroboros.ConnectionId {
+	if ls.config.BlockfetchConnectionIdsFunc == nil {
+		return connId
+	}
+	connIds := ls.config.BlockfetchConnectionIdsFunc()
+	if len(connIds) == 0 {
+		return connId
+	}
+	return connIds[0]
+}
+
+func (ls *LedgerState) publishBlockfetchProgress(blockNumber uint64) {
+	ls.blockfetchProgressTime = time.Now()
+	tipBlockNumber := ls.chainsyncUpstreamTip.BlockNumber
+	if tipBlockNumber > blockNumber {
+		ls.blockfetchProgress.BlocksRemaining = tipBlockNumber - blockNumber
+	} else {
+		ls.blockfetchProgress.BlocksRemaining = 0
+	}
+	ls.config.EventBus.Publish(
+		BlockfetchProgressEventType,
+		event.NewEvent(
+			BlockfetchProgressEventType,
+			ls.blockfetchProgress,
+		),
+	)
+}
+
 func (ls *LedgerState) processBlockEvents() error {
 	batchOffset := 0
 	for {
@@ -205,6 +404,7 @@ func (ls *LedgerState) processBlockEvents() error {
 		batchOffset += batchSize
 	}
 	ls.chainsyncBlockEvents = nil
+	ls.chainsyncBlockEventsBytes = 0
 	return nil
 }
 
@@ -282,9 +482,9 @@ func (ls *LedgerState) calculateEpochNonce(
 	).Num().Uint64()
 	stabilityWindowStartSlot := epochStartSlot - stabilityWindow
 	// Get last block before stability window
-	blockBeforeStabilityWindow, err := database.BlockBeforeSlotTxn(
-		txn,
+	blockBeforeStabilityWindow, err := ls.config.ChainManager.BlockStore().GetBeforeSlot(
 		stabilityWindowStartSlot,
+		txn,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("lookup block before slot: %w", err)
@@ -294,9 +494,9 @@ func (ls *LedgerState) calculateEpochNonce(
 		return nil, fmt.Errorf("lookup block nonce: %w", err)
 	}
 	// Get last block in previous epoch
-	blockLastPrevEpoch, err := database.BlockBeforeSlotTxn(
-		txn,
+	blockLastPrevEpoch, err := ls.config.ChainManager.BlockStore().GetBeforeSlot(
 		ls.currentEpoch.StartSlot,
+		txn,
 	)
 	if err != nil {
 		if errors.Is(err, database.ErrBlockNotFound) {
@@ -313,9 +513,11 @@ func (ls *LedgerState) calculateEpochNonce(
 	return ret.Bytes(), err
 }
 
+// processEpochRollover creates the next epoch record and applies any pending pparam updates. If a pparam update
+// was enacted, an event describing it is returned to be published once the transaction has been committed
 func (ls *LedgerState) processEpochRollover(
 	txn *database.Txn,
-) error {
+) (*ProtocolParameterUpdateEvent, error) {
 	epochStartSlot := ls.currentEpoch.StartSlot + uint64(
 		ls.currentEpoch.LengthInSlots,
 	)
@@ -326,11 +528,11 @@ func (ls *LedgerState) processEpochRollover(
 			ls.config.CardanoNodeConfig,
 		)
 		if err != nil {
-			return fmt.Errorf("calculate epoch length: %w", err)
+			return nil, fmt.Errorf("calculate epoch length: %w", err)
 		}
 		tmpNonce, err := ls.calculateEpochNonce(txn, 0)
 		if err != nil {
-			return fmt.Errorf("calculate epoch nonce: %w", err)
+			return nil, fmt.Errorf("calculate epoch nonce: %w", err)
 		}
 		err = ls.db.SetEpoch(
 			epochStartSlot,
@@ -342,21 +544,26 @@ func (ls *LedgerState) processEpochRollover(
 			txn,
 		)
 		if err != nil {
-			return fmt.Errorf("set epoch: %w", err)
+			return nil, fmt.Errorf("set epoch: %w", err)
 		}
 		// Reload epoch info
 		if err := ls.loadEpochs(txn); err != nil {
-			return fmt.Errorf("load epochs: %w", err)
+			return nil, fmt.Errorf("load epochs: %w", err)
 		}
 		ls.config.Logger.Debug(
 			"added initial epoch to DB",
 			"epoch", fmt.Sprintf("%+v", ls.currentEpoch),
 			"component", "ledger",
 		)
-		return nil
+		return nil, nil
 	}
 	// Apply pending pparam updates
-	err := ls.db.ApplyPParamUpdates(
+	pparamUpdates, err := ls.db.GetPParamUpdates(ls.currentEpoch.EpochId, txn)
+	if err != nil {
+		return nil, fmt.Errorf("get pparam updates: %w", err)
+	}
+	prevPParams := copyPParams(ls.currentPParams)
+	err = ls.db.ApplyPParamUpdates(
 		epochStartSlot,
 		ls.currentEpoch.EpochId,
 		ls.currentEra.Id,
@@ -366,18 +573,26 @@ func (ls *LedgerState) processEpochRollover(
 		txn,
 	)
 	if err != nil {
-		return fmt.Errorf("apply pparam updates: %w", err)
+		return nil, fmt.Errorf("apply pparam updates: %w", err)
+	}
+	var updateEvent *ProtocolParameterUpdateEvent
+	if len(pparamUpdates) > 0 {
+		updateEvent = ls.enactedPParamUpdateEvent(
+			epochStartSlot,
+			pparamUpdates,
+			prevPParams,
+		)
 	}
 	// Create next epoch record
 	epochSlotLength, epochLength, err := ls.currentEra.EpochLengthFunc(
 		ls.config.CardanoNodeConfig,
 	)
 	if err != nil {
-		return fmt.Errorf("calculate epoch length: %w", err)
+		return nil, fmt.Errorf("calculate epoch length: %w", err)
 	}
 	tmpNonce, err := ls.calculateEpochNonce(txn, epochStartSlot)
 	if err != nil {
-		return fmt.Errorf("calculate epoch nonce: %w", err)
+		return nil, fmt.Errorf("calculate epoch nonce: %w", err)
 	}
 	err = ls.db.SetEpoch(
 		epochStartSlot,
@@ -389,11 +604,11 @@ func (ls *LedgerState) processEpochRollover(
 		txn,
 	)
 	if err != nil {
-		return fmt.Errorf("set epoch: %w", err)
+		return nil, fmt.Errorf("set epoch: %w", err)
 	}
 	// Reload epoch info
 	if err := ls.loadEpochs(txn); err != nil {
-		return fmt.Errorf("load epochs: %w", err)
+		return nil, fmt.Errorf("load epochs: %w", err)
 	}
 	ls.config.Logger.Debug(
 		"added next epoch to DB",
@@ -402,7 +617,7 @@ func (ls *LedgerState) processEpochRollover(
 	)
 	// Start background cleanup of consumed UTxOs
 	go ls.cleanupConsumedUtxos()
-	return nil
+	return updateEvent, nil
 }
 
 func (ls *LedgerState) processBlockEvent(
@@ -441,14 +656,18 @@ func (ls *LedgerState) blockfetchRequestRangeStart(
 	}
 	// Reset blockfetch busy time
 	ls.chainsyncBlockfetchBusyTime = time.Now()
+	// Record the connection that we requested the range from
+	ls.chainsyncBlockfetchConnId = connId
 	// Create our blockfetch done signal channels
 	ls.chainsyncBlockfetchReadyChan = make(chan struct{})
-	ls.chainsyncBlockfetchBatchDoneChan = make(chan struct{})
-	// Start goroutine to handle blockfetch timeout
+	batchDoneChan := make(chan struct{})
+	ls.chainsyncBlockfetchBatchDoneChan = batchDoneChan
+	// Start goroutine to handle blockfetch timeout. We watch our own copy of the channel, since the field is
+	// cleared when it's closed and replaced when the next range is requested
 	go func() {
 		for {
 			select {
-			case <-ls.chainsyncBlockfetchBatchDoneChan:
+			case <-batchDoneChan:
 				return
 			case <-time.After(500 * time.Millisecond):
 			}
@@ -479,6 +698,7 @@ func (ls *LedgerState) blockfetchRequestRangeCleanup(resetFlags bool) {
 		0,
 		len(ls.chainsyncBlockEvents),
 	)
+	ls.chainsyncBlockEventsBytes = 0
 	// Close our blockfetch done signal channel
 	if ls.chainsyncBlockfetchReadyChan != nil {
 		close(ls.chainsyncBlockfetchReadyChan)
@@ -494,6 +714,7 @@ func (ls *LedgerState) handleEventBlockfetchBatchDone(e BlockfetchEvent) error {
 	// Cancel our blockfetch timeout watcher
 	if ls.chainsyncBlockfetchBatchDoneChan != nil {
 		close(ls.chainsyncBlockfetchBatchDoneChan)
+		ls.chainsyncBlockfetchBatchDoneChan = nil
 	}
 	// Process pending block events
 	if err := ls.processBlockEvents(); err != nil {
@@ -510,9 +731,9 @@ func (ls *LedgerState) handleEventBlockfetchBatchDone(e BlockfetchEvent) error {
 	// Clean up from blockfetch batch
 	ls.blockfetchRequestRangeCleanup(false)
 	// Request next waiting bulk range
-	headerStart, headerEnd := ls.chain.HeaderRange(blockfetchBatchSize)
+	headerStart, headerEnd := ls.chain.HeaderRange(ls.blockfetchBatchSize())
 	err := ls.blockfetchRequestRangeStart(
-		e.ConnectionId,
+		ls.blockfetchSelectConnId(e.ConnectionId),
 		headerStart,
 		headerEnd,
 	)
@@ -0,0 +1,268 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ledger
+
+import (
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/dingo/connmanager"
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/event"
+	ouroboros "github.com/blinklabs-io/gouroboros"
+	gledger "github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+type mockBlock struct {
+	gledger.ConwayBlock
+	mockHash     string
+	mockPrevHash string
+	mockSlot     uint64
+	mockNumber   uint64
+}
+
+func (b *mockBlock) Hash() lcommon.Blake2b256 {
+	hashBytes, _ := hex.DecodeString(b.mockHash)
+	return lcommon.NewBlake2b256(hashBytes)
+}
+
+func (b *mockBlock) PrevHash() lcommon.Blake2b256 {
+	hashBytes, _ := hex.DecodeString(b.mockPrevHash)
+	return lcommon.NewBlake2b256(hashBytes)
+}
+
+func (b *mockBlock) SlotNumber() uint64 {
+	return b.mockSlot
+}
+
+func (b *mockBlock) BlockNumber() uint64 {
+	return b.mockNumber
+}
+
+func (b *mockBlock) point() ocommon.Point {
+	return ocommon.NewPoint(b.mockSlot, b.Hash().Bytes())
+}
+
+type testBlockfetchRequest struct {
+	connId ouroboros.ConnectionId
+	start  ocommon.Point
+	end    ocommon.Point
+}
+
+type testBlockfetchState struct {
+	ls         *LedgerState
+	chain      *chain.Chain
+	testBlocks []*mockBlock
+	connIdA    ouroboros.ConnectionId
+	connIdB    ouroboros.ConnectionId
+	requests   []testBlockfetchRequest
+}
+
+// newTestBlockfetchState creates a ledger state with a chain containing the first 2 of 6 test blocks and headers
+// for the rest, with 2 connections available for blockfetch
+func newTestBlockfetchState(t *testing.T) *testBlockfetchState {
+	testHashPrefix := "000047442c8830c700ecb099064ee1b038ed6fd254133f582e906a4bc3fd"
+	ret := &testBlockfetchState{
+		connIdA: ouroboros.ConnectionId{
+			LocalAddr:  &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 3001},
+			RemoteAddr: &net.TCPAddr{IP: net.ParseIP("127.0.0.2"), Port: 3001},
+		},
+		connIdB: ouroboros.ConnectionId{
+			LocalAddr:  &net.TCPAddr{IP: net.ParseIP("127.0.0.1"), Port: 3001},
+			RemoteAddr: &net.TCPAddr{IP: net.ParseIP("127.0.0.3"), Port: 3001},
+		},
+	}
+	for i := range 6 {
+		// #nosec G115
+		testBlock := &mockBlock{
+			mockHash:   testHashPrefix + hex.EncodeToString([]byte{0, byte(i + 1)}),
+			mockSlot:   uint64(i * 20),
+			mockNumber: uint64(i + 1),
+		}
+		if i > 0 {
+			testBlock.mockPrevHash = ret.testBlocks[i-1].mockHash
+		}
+		ret.testBlocks = append(ret.testBlocks, testBlock)
+	}
+	db, err := database.New(nil, nil, "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error creating database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	cm, err := chain.NewManager(nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain manager: %s", err)
+	}
+	ret.chain = cm.PrimaryChain()
+	// Add first 2 blocks and headers for the rest
+	for _, testBlock := range ret.testBlocks[0:2] {
+		if err := ret.chain.AddBlock(testBlock, nil); err != nil {
+			t.Fatalf("unexpected error adding block to chain: %s", err)
+		}
+	}
+	for _, testBlock := range ret.testBlocks[2:] {
+		if err := ret.chain.AddBlockHeader(testBlock); err != nil {
+			t.Fatalf("unexpected error adding header to chain: %s", err)
+		}
+	}
+	ret.ls = &LedgerState{
+		config: LedgerStateConfig{
+			Logger:   slog.New(slog.NewJSONHandler(io.Discard, nil)),
+			EventBus: event.NewEventBus(nil),
+			BlockfetchRequestRangeFunc: func(connId ouroboros.ConnectionId, start ocommon.Point, end ocommon.Point) error {
+				ret.requests = append(
+					ret.requests,
+					testBlockfetchRequest{connId: connId, start: start, end: end},
+				)
+				return nil
+			},
+			BlockfetchConnectionIdsFunc: func() []ouroboros.ConnectionId {
+				return []ouroboros.ConnectionId{ret.connIdA, ret.connIdB}
+			},
+		},
+		db:    db,
+		chain: ret.chain,
+	}
+	return ret
+}
+
+// requestRange requests the full header range from the specified connection
+func (s *testBlockfetchState) requestRange(t *testing.T, connId ouroboros.ConnectionId) {
+	headerStart, headerEnd := s.chain.HeaderRange(s.ls.blockfetchBatchSize())
+	s.ls.chainsyncBlockfetchMutex.Lock()
+	defer s.ls.chainsyncBlockfetchMutex.Unlock()
+	if err := s.ls.blockfetchRequestRangeStart(connId, headerStart, headerEnd); err != nil {
+		t.Fatalf("unexpected error requesting block range: %s", err)
+	}
+}
+
+// deliverBlocks delivers blockfetch events for the specified blocks from the specified connection
+func (s *testBlockfetchState) deliverBlocks(connId ouroboros.ConnectionId, blocks []*mockBlock) {
+	for _, testBlock := range blocks {
+		s.ls.handleEventBlockfetch(
+			event.NewEvent(
+				BlockfetchEventType,
+				BlockfetchEvent{
+					ConnectionId: connId,
+					Point:        testBlock.point(),
+					Block:        testBlock,
+				},
+			),
+		)
+	}
+}
+
+func (s *testBlockfetchState) deliverBatchDone(connId ouroboros.ConnectionId) {
+	s.ls.handleEventBlockfetch(
+		event.NewEvent(
+			BlockfetchEventType,
+			BlockfetchEvent{
+				ConnectionId: connId,
+				BatchDone:    true,
+			},
+		),
+	)
+}
+
+func (s *testBlockfetchState) closeConnection(connId ouroboros.ConnectionId) {
+	s.ls.handleEventConnectionClosed(
+		event.NewEvent(
+			connmanager.ConnectionClosedEventType,
+			connmanager.ConnectionClosedEvent{ConnectionId: connId},
+		),
+	)
+}
+
+func TestBlockfetchResumeAfterConnectionClosed(t *testing.T) {
+	s := newTestBlockfetchState(t)
+	testBlocks := s.testBlocks
+	c := s.chain
+	// Request full range from first peer
+	s.requestRange(t, s.connIdA)
+	// Receive part of the range before the first peer disconnects
+	s.deliverBlocks(s.connIdA, testBlocks[2:4])
+	s.closeConnection(s.connIdA)
+	requests := s.requests
+	// Check that the rest of the range was requested from the second peer
+	if len(requests) != 2 {
+		t.Fatalf("did not get expected number of requests: got %d, wanted 2", len(requests))
+	}
+	if requests[1].connId != s.connIdB {
+		t.Fatalf("range was not re-requested from expected peer: got %s", requests[1].connId.String())
+	}
+	if requests[1].start.Slot != testBlocks[4].mockSlot ||
+		requests[1].end.Slot != testBlocks[5].mockSlot {
+		t.Fatalf(
+			"did not get expected range: got %d to %d, wanted %d to %d",
+			requests[1].start.Slot,
+			requests[1].end.Slot,
+			testBlocks[4].mockSlot,
+			testBlocks[5].mockSlot,
+		)
+	}
+	// Receive the rest of the range from the second peer
+	s.deliverBlocks(s.connIdB, testBlocks[4:])
+	s.deliverBatchDone(s.connIdB)
+	if c.HeaderCount() != 0 {
+		t.Fatalf("expected no remaining headers, got %d", c.HeaderCount())
+	}
+	tip := c.Tip()
+	if tip.Point.Slot != testBlocks[5].mockSlot {
+		t.Fatalf("did not get expected chain tip: got slot %d, wanted %d", tip.Point.Slot, testBlocks[5].mockSlot)
+	}
+	if s.ls.chainsyncBlockfetchReadyChan != nil {
+		t.Fatal("expected blockfetch to be finished")
+	}
+}
+
+func TestBlockfetchBatchDoneAfterConnectionClosed(t *testing.T) {
+	s := newTestBlockfetchState(t)
+	testBlocks := s.testBlocks
+	s.requestRange(t, s.connIdA)
+	s.deliverBlocks(s.connIdA, testBlocks[2:4])
+	s.closeConnection(s.connIdA)
+	if len(s.requests) != 2 {
+		t.Fatalf("did not get expected number of requests: got %d, wanted 2", len(s.requests))
+	}
+	// Late events from the closed connection are ignored, rather than closing the batch done channel again or
+	// finishing the range re-requested from the second peer
+	s.deliverBlocks(s.connIdA, testBlocks[4:5])
+	s.deliverBatchDone(s.connIdA)
+	if len(s.requests) != 2 {
+		t.Fatalf("did not get expected number of requests: got %d, wanted 2", len(s.requests))
+	}
+	if s.ls.chainsyncBlockfetchReadyChan == nil {
+		t.Fatal("expected blockfetch from second peer to still be in progress")
+	}
+	if s.chain.HeaderCount() != 2 {
+		t.Fatalf("expected 2 remaining headers, got %d", s.chain.HeaderCount())
+	}
+	// A batch done from the closed connection after the resumed range has finished is also harmless
+	s.deliverBlocks(s.connIdB, testBlocks[4:])
+	s.deliverBatchDone(s.connIdB)
+	s.deliverBatchDone(s.connIdA)
+	if s.chain.HeaderCount() != 0 {
+		t.Fatalf("expected no remaining headers, got %d", s.chain.HeaderCount())
+	}
+	if s.ls.chainsyncBlockfetchReadyChan != nil {
+		t.Fatal("expected blockfetch to be finished")
+	}
+}
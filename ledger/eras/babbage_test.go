@@ -0,0 +1,111 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eras_test
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/ledger/eras"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+func TestBabbagePParamsCborRoundTrip(t *testing.T) {
+	pparams := babbage.BabbageProtocolParameters{
+		MinFeeA:            44,
+		MinFeeB:            155381,
+		MaxBlockBodySize:   90112,
+		MaxTxSize:          16384,
+		MaxBlockHeaderSize: 1100,
+		KeyDeposit:         2000000,
+		PoolDeposit:        500000000,
+		MaxEpoch:           18,
+		NOpt:               500,
+		A0:                 &cbor.Rat{Rat: big.NewRat(3, 10)},
+		Rho:                &cbor.Rat{Rat: big.NewRat(3, 1000)},
+		Tau:                &cbor.Rat{Rat: big.NewRat(1, 5)},
+		ProtocolMajor:      8,
+		MinPoolCost:        340000000,
+		AdaPerUtxoByte:     4310,
+		CostModels: map[uint][]int64{
+			0: {100, 200, 300},
+			1: {400, 500},
+		},
+		ExecutionCosts: lcommon.ExUnitPrice{
+			MemPrice:  &cbor.Rat{Rat: big.NewRat(577, 10000)},
+			StepPrice: &cbor.Rat{Rat: big.NewRat(721, 10000000)},
+		},
+		MaxTxExUnits: lcommon.ExUnits{
+			Memory: 14000000,
+			Steps:  10000000000,
+		},
+		MaxBlockExUnits: lcommon.ExUnits{
+			Memory: 62000000,
+			Steps:  20000000000,
+		},
+		MaxValueSize:         5000,
+		CollateralPercentage: 150,
+		MaxCollateralInputs:  3,
+	}
+	pparamsCbor, err := cbor.Encode(&pparams)
+	if err != nil {
+		t.Fatalf("unexpected error encoding pparams: %s", err)
+	}
+	decoded, err := eras.BabbageEraDesc.DecodePParamsFunc(pparamsCbor)
+	if err != nil {
+		t.Fatalf("unexpected error decoding pparams: %s", err)
+	}
+	decodedPParams, ok := decoded.(*babbage.BabbageProtocolParameters)
+	if !ok {
+		t.Fatalf("decoded pparams are not expected type: %T", decoded)
+	}
+	decodedCbor, err := cbor.Encode(decodedPParams)
+	if err != nil {
+		t.Fatalf("unexpected error encoding decoded pparams: %s", err)
+	}
+	if !bytes.Equal(pparamsCbor, decodedCbor) {
+		t.Fatalf(
+			"pparams CBOR did not round-trip:\n  got:    %x\n  wanted: %x",
+			decodedCbor,
+			pparamsCbor,
+		)
+	}
+	// Apply an update changing the min fee
+	updateCbor, err := cbor.Encode(map[uint]uint{0: 45})
+	if err != nil {
+		t.Fatalf("unexpected error encoding pparams update: %s", err)
+	}
+	update, err := eras.BabbageEraDesc.DecodePParamsUpdateFunc(updateCbor)
+	if err != nil {
+		t.Fatalf("unexpected error decoding pparams update: %s", err)
+	}
+	updated, err := eras.BabbageEraDesc.PParamsUpdateFunc(decoded, update)
+	if err != nil {
+		t.Fatalf("unexpected error applying pparams update: %s", err)
+	}
+	updatedPParams := updated.(*babbage.BabbageProtocolParameters)
+	if updatedPParams.MinFeeA != 45 {
+		t.Fatalf(
+			"did not get expected MinFeeA after update: got %d, wanted 45",
+			updatedPParams.MinFeeA,
+		)
+	}
+	if updatedPParams.MinFeeB != pparams.MinFeeB {
+		t.Fatalf("MinFeeB changed unexpectedly after update")
+	}
+}
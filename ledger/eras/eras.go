@@ -15,6 +15,8 @@
 package eras
 
 import (
+	"fmt"
+
 	"github.com/blinklabs-io/dingo/config/cardano"
 	"github.com/blinklabs-io/gouroboros/ledger"
 	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
@@ -42,3 +44,38 @@ var Eras = []EraDesc{
 	BabbageEraDesc,
 	ConwayEraDesc,
 }
+
+// ValidateEras walks the era sequence, performing each hard fork from the protocol parameters produced by the
+// previous era, to make sure that each hard fork function accepts the previous era's protocol parameters. This
+// catches a misordered era table at startup rather than when the affected hard fork is reached during sync
+func ValidateEras() error {
+	// Use an empty node config, since we only care about types here and genesis configs are optional
+	nodeConfig := &cardano.CardanoNodeConfig{}
+	var pparams lcommon.ProtocolParameters
+	for idx, era := range Eras {
+		// #nosec G115
+		if era.Id != uint(idx) {
+			return fmt.Errorf(
+				"era %s has ID %d but is at position %d in the era list",
+				era.Name,
+				era.Id,
+				idx,
+			)
+		}
+		if era.HardForkFunc == nil || idx == 0 {
+			continue
+		}
+		tmpPParams, err := era.HardForkFunc(nodeConfig, pparams)
+		if err != nil {
+			return fmt.Errorf(
+				"hard fork from era %s (pparams %T) to era %s failed: %w",
+				Eras[idx-1].Name,
+				pparams,
+				era.Name,
+				err,
+			)
+		}
+		pparams = tmpPParams
+	}
+	return nil
+}
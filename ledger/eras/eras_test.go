@@ -0,0 +1,77 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package eras_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/ledger/eras"
+	"github.com/blinklabs-io/gouroboros/ledger/byron"
+)
+
+func TestEraDescriptorsComplete(t *testing.T) {
+	for idx, era := range eras.Eras {
+		// #nosec G115
+		if era.Id != uint(idx) {
+			t.Fatalf(
+				"era %s has unexpected ID: got %d, wanted %d",
+				era.Name,
+				era.Id,
+				idx,
+			)
+		}
+		if era.EpochLengthFunc == nil {
+			t.Fatalf("era %s is missing EpochLengthFunc", era.Name)
+		}
+		// Byron has no protocol parameter updates, hard fork, or Praos nonce handling
+		if era.Id == byron.EraIdByron {
+			continue
+		}
+		funcs := map[string]bool{
+			"DecodePParamsFunc":       era.DecodePParamsFunc != nil,
+			"DecodePParamsUpdateFunc": era.DecodePParamsUpdateFunc != nil,
+			"PParamsUpdateFunc":       era.PParamsUpdateFunc != nil,
+			"HardForkFunc":            era.HardForkFunc != nil,
+			"CalculateEtaVFunc":       era.CalculateEtaVFunc != nil,
+			"CertDepositFunc":         era.CertDepositFunc != nil,
+			"ValidateTxFunc":          era.ValidateTxFunc != nil,
+		}
+		for name, ok := range funcs {
+			if !ok {
+				t.Fatalf("era %s is missing %s", era.Name, name)
+			}
+		}
+	}
+}
+
+func TestValidateEras(t *testing.T) {
+	if err := eras.ValidateEras(); err != nil {
+		t.Fatalf("unexpected error validating eras: %s", err)
+	}
+}
+
+func TestValidateErasMisordered(t *testing.T) {
+	origEras := eras.Eras
+	defer func() {
+		eras.Eras = origEras
+	}()
+	// Swap Mary and Alonzo hard fork functions
+	eras.Eras = slices.Clone(origEras)
+	eras.Eras[3].HardForkFunc, eras.Eras[4].HardForkFunc = eras.Eras[4].HardForkFunc, eras.Eras[3].HardForkFunc
+	if err := eras.ValidateEras(); err == nil {
+		t.Fatalf("did not get expected error validating misordered eras")
+	}
+}
@@ -14,6 +14,33 @@
 
 package ledger
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+)
 
 var ErrBlockNotFound = errors.New("block not found")
+
+// ErrCostModelsNotAvailable is returned when the current protocol parameters do not include Plutus cost models
+var ErrCostModelsNotAvailable = errors.New(
+	"cost models not available in current protocol parameters",
+)
+
+// TipMismatchError is returned when the ledger tip in the metadata store is not present in the chain in the blob
+// store, which means that the ledger is ahead of the chain
+type TipMismatchError struct {
+	LedgerTip ochainsync.Tip
+	ChainTip  ochainsync.Tip
+}
+
+func (e TipMismatchError) Error() string {
+	return fmt.Sprintf(
+		"ledger tip (slot %d, block %d) in metadata store is ahead of chain tip (slot %d, block %d) in blob store",
+		e.LedgerTip.Point.Slot,
+		e.LedgerTip.BlockNumber,
+		e.ChainTip.Point.Slot,
+		e.ChainTip.BlockNumber,
+	)
+}
@@ -18,15 +18,31 @@ import (
 	"github.com/blinklabs-io/dingo/event"
 	ouroboros "github.com/blinklabs-io/gouroboros"
 	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
 	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
 	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
 )
 
 const (
-	BlockfetchEventType event.EventType = "blockfetch.event"
-	ChainsyncEventType  event.EventType = "chainsync.event"
+	BlockEventType                   event.EventType = "ledger.block"
+	BlockfetchEventType              event.EventType = "blockfetch.event"
+	BlockfetchProgressEventType      event.EventType = "blockfetch.progress"
+	CertificateEventType             event.EventType = "ledger.certificate"
+	ChainsyncEventType               event.EventType = "chainsync.event"
+	EpochTransitionEventType         event.EventType = "ledger.epoch-transition"
+	EraTransitionEventType           event.EventType = "ledger.era-transition"
+	ProtocolParameterUpdateEventType event.EventType = "ledger.pparam-update"
 )
 
+// BlockEvent is generated for each block after it has been applied to the ledger
+type BlockEvent struct {
+	Point       ocommon.Point // Chain point (slot and hash) of the block
+	BlockNumber uint64
+	Type        uint   // Block type ID, which identifies the era
+	Cbor        []byte // Raw block CBOR
+	Block       ledger.Block
+}
+
 // BlockfetchEvent represents either a Block or BatchDone blockfetch event. We use
 // a single event type for both to make synchronization easier.
 type BlockfetchEvent struct {
@@ -37,6 +53,26 @@ type BlockfetchEvent struct {
 	BatchDone    bool // Set to true for a BatchDone event
 }
 
+// BlockfetchProgressEvent is periodically generated during block fetching to report sync progress
+type BlockfetchProgressEvent struct {
+	BlocksFetched   uint64 // Total blocks fetched
+	BytesFetched    uint64 // Total bytes of block data fetched
+	CurrentSlot     uint64 // Slot of the most recently fetched block
+	BlocksRemaining uint64 // Estimated blocks remaining based on the best known upstream tip
+}
+
+// CertificateEvent is generated for each certificate in a valid transaction after its block has been applied to the
+// ledger. Fields that don't apply to the certificate type are left empty
+type CertificateEvent struct {
+	Point           ocommon.Point // Chain point of the block containing the certificate
+	TxHash          lcommon.Blake2b256
+	CertificateType uint   // Certificate type, which matches the lcommon.CertificateType* constants
+	StakeCredential []byte // Stake credential hash
+	PoolId          string // Bech32 pool ID for pool registration, retirement, and delegation
+	Drep            []byte // DRep credential for DRep certificates and vote delegation
+	Certificate     lcommon.Certificate
+}
+
 // ChainsyncEvent represents either a RollForward or RollBackward chainsync event.
 // We use a single event type for both to make synchronization easier.
 type ChainsyncEvent struct {
@@ -48,3 +84,41 @@ type ChainsyncEvent struct {
 	Type         uint // Block or header type ID
 	Rollback     bool
 }
+
+// EpochTransitionEvent is generated when the ledger crosses an epoch boundary
+type EpochTransitionEvent struct {
+	EpochId       uint64 // New epoch number
+	StartSlot     uint64 // Boundary slot, which is the first slot of the new epoch
+	LengthInSlots uint   // Length of the new epoch, which is determined by its era
+	EraId         uint
+}
+
+// EraTransitionEvent is generated when the ledger crosses a hard fork boundary into a new era
+type EraTransitionEvent struct {
+	OldEraId   uint
+	OldEraName string
+	NewEraId   uint
+	NewEraName string
+	StartSlot  uint64 // First slot of the new era
+	StartEpoch uint64 // First epoch of the new era
+}
+
+// ProtocolParameterUpdateEvent is generated when a protocol parameter update proposal is observed in a valid
+// transaction, and again when a pre-Conway update is enacted at an epoch boundary
+type ProtocolParameterUpdateEvent struct {
+	Point            ocommon.Point        // Chain point of the proposing block, or the first slot of the new epoch when enacted
+	TxHash           lcommon.Blake2b256   // Proposing transaction. This is empty when enacted
+	Epoch            uint64               // Epoch that a pre-Conway update applies to, or the first epoch with the new params when enacted
+	Enacted          bool                 // Set when the update has been applied to the current protocol parameters
+	GenesisKeyHashes []lcommon.Blake2b224 // Proposing genesis delegate key hashes (pre-Conway)
+	GovActionId      *lcommon.GovActionId // Governance action ID for the parameter change proposal (Conway)
+	Update           any                  // Era-specific protocol parameter update
+	Changes          map[string]ProtocolParameterChange
+}
+
+// ProtocolParameterChange describes the change to a single protocol parameter, keyed by its field name in the
+// era-specific protocol parameters type
+type ProtocolParameterChange struct {
+	Old any
+	New any
+}
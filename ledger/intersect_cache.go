@@ -0,0 +1,136 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ledger
+
+import (
+	"container/list"
+	"encoding/binary"
+	"sync"
+	"time"
+
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+const (
+	intersectCacheMaxSize = 256
+	intersectCacheTTL     = 10 * time.Second
+)
+
+type intersectCacheEntry struct {
+	key       string
+	point     ocommon.Point
+	expiresAt time.Time
+}
+
+// intersectCache is a small LRU cache of resolved intersect points, keyed by the requested points. This avoids
+// repeated lookups when many chainsync clients intersect near the tip
+type intersectCache struct {
+	mutex   sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	// Incremented on each invalidation, so that lookups that raced with a rollback aren't cached
+	generation uint64
+}
+
+func newIntersectCache() *intersectCache {
+	return &intersectCache{
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// intersectCacheKey serializes the requested points into a cache key. The points are supplied by peers, so we use the
+// full point list rather than a hash, which would allow a crafted collision to return another client's intersect
+func intersectCacheKey(points []ocommon.Point) string {
+	buf := make([]byte, 0, len(points)*(8+4+32))
+	for _, point := range points {
+		buf = binary.BigEndian.AppendUint64(buf, point.Slot)
+		// Length-prefix hashes so that different point lists can't produce the same key
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(point.Hash))) // #nosec G115
+		buf = append(buf, point.Hash...)
+	}
+	return string(buf)
+}
+
+// currentGeneration returns the current generation, which should be passed to add for a lookup started now
+func (c *intersectCache) currentGeneration() uint64 {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.generation
+}
+
+func (c *intersectCache) get(key string) (ocommon.Point, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return ocommon.Point{}, false
+	}
+	entry := elem.Value.(*intersectCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		c.lru.Remove(elem)
+		delete(c.entries, key)
+		return ocommon.Point{}, false
+	}
+	c.lru.MoveToFront(elem)
+	return entry.point, true
+}
+
+// add caches the resolved point for the specified key, unless there has been an invalidation since the specified
+// generation
+func (c *intersectCache) add(key string, point ocommon.Point, generation uint64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if generation != c.generation {
+		return
+	}
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*intersectCacheEntry)
+		entry.point = point
+		entry.expiresAt = time.Now().Add(intersectCacheTTL)
+		c.lru.MoveToFront(elem)
+		return
+	}
+	if c.lru.Len() >= intersectCacheMaxSize {
+		oldest := c.lru.Back()
+		if oldest != nil {
+			c.lru.Remove(oldest)
+			delete(c.entries, oldest.Value.(*intersectCacheEntry).key)
+		}
+	}
+	c.entries[key] = c.lru.PushFront(
+		&intersectCacheEntry{
+			key:       key,
+			point:     point,
+			expiresAt: time.Now().Add(intersectCacheTTL),
+		},
+	)
+}
+
+// invalidateAfter removes any cached intersect points later than the specified rollback point
+func (c *intersectCache) invalidateAfter(point ocommon.Point) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	for elem := c.lru.Front(); elem != nil; {
+		next := elem.Next()
+		entry := elem.Value.(*intersectCacheEntry)
+		if entry.point.Slot > point.Slot {
+			c.lru.Remove(elem)
+			delete(c.entries, entry.key)
+		}
+		elem = next
+	}
+}
@@ -0,0 +1,102 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ledger
+
+import (
+	"testing"
+
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+func TestIntersectCacheInvalidateAfter(t *testing.T) {
+	cache := newIntersectCache()
+	testPoints := []ocommon.Point{
+		ocommon.NewPoint(100, []byte{0x01}),
+		ocommon.NewPoint(200, []byte{0x02}),
+		ocommon.NewPoint(300, []byte{0x03}),
+	}
+	for _, point := range testPoints {
+		cache.add(
+			intersectCacheKey([]ocommon.Point{point}),
+			point,
+			cache.currentGeneration(),
+		)
+	}
+	// Rollback past the last two cached points
+	cache.invalidateAfter(ocommon.NewPoint(150, []byte{0x04}))
+	for _, point := range testPoints {
+		cachedPoint, ok := cache.get(
+			intersectCacheKey([]ocommon.Point{point}),
+		)
+		if point.Slot > 150 {
+			if ok {
+				t.Fatalf(
+					"expected cached point at slot %d to be invalidated",
+					point.Slot,
+				)
+			}
+			continue
+		}
+		if !ok {
+			t.Fatalf("expected cached point at slot %d", point.Slot)
+		}
+		if cachedPoint.Slot != point.Slot ||
+			string(cachedPoint.Hash) != string(point.Hash) {
+			t.Fatalf(
+				"did not get expected cached point: got %d.%x, wanted %d.%x",
+				cachedPoint.Slot,
+				cachedPoint.Hash,
+				point.Slot,
+				point.Hash,
+			)
+		}
+	}
+}
+
+func TestIntersectCacheKeyExact(t *testing.T) {
+	// Lists that only differ in how the bytes are split between points get different keys
+	keyA := intersectCacheKey(
+		[]ocommon.Point{
+			ocommon.NewPoint(100, []byte{0x01, 0x02}),
+		},
+	)
+	keyB := intersectCacheKey(
+		[]ocommon.Point{
+			ocommon.NewPoint(100, []byte{0x01}),
+			ocommon.NewPoint(0x02, nil),
+		},
+	)
+	if keyA == keyB {
+		t.Fatalf("different point lists produced the same key")
+	}
+	cache := newIntersectCache()
+	cache.add(keyA, ocommon.NewPoint(100, []byte{0x01, 0x02}), cache.currentGeneration())
+	if _, ok := cache.get(keyB); ok {
+		t.Fatalf("got cached point for a different point list")
+	}
+}
+
+func TestIntersectCacheSkipsStaleGeneration(t *testing.T) {
+	cache := newIntersectCache()
+	point := ocommon.NewPoint(200, []byte{0x02})
+	key := intersectCacheKey([]ocommon.Point{point})
+	// A rollback happens while the intersect is being resolved
+	generation := cache.currentGeneration()
+	cache.invalidateAfter(ocommon.NewPoint(100, []byte{0x01}))
+	cache.add(key, point, generation)
+	if _, ok := cache.get(key); ok {
+		t.Fatalf("expected point resolved before a rollback not to be cached")
+	}
+}
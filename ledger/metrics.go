@@ -26,6 +26,9 @@ type stateMetrics struct {
 	slotInEpoch prometheus.Gauge
 	slotNum     prometheus.Gauge
 	forks       prometheus.Gauge
+	// Per-block ledger application, labeled by era
+	blockApplyDuration *prometheus.HistogramVec
+	blocksApplied      *prometheus.CounterVec
 }
 
 func (m *stateMetrics) init(promRegistry prometheus.Registerer) {
@@ -55,4 +58,20 @@ func (m *stateMetrics) init(promRegistry prometheus.Registerer) {
 		Name: "cardano_node_metrics_forks_int",
 		Help: "number of forks seen",
 	})
+	m.blockApplyDuration = promautoFactory.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name: "ledger_block_apply_duration_seconds",
+			Help: "time taken to validate and apply a block to the ledger",
+			// 0.5ms to ~4s
+			Buckets: prometheus.ExponentialBuckets(0.0005, 2, 14),
+		},
+		[]string{"era"},
+	)
+	m.blocksApplied = promautoFactory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ledger_blocks_applied_total",
+			Help: "total blocks applied to the ledger",
+		},
+		[]string{"era"},
+	)
 }
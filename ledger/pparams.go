@@ -0,0 +1,227 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ledger
+
+import (
+	"bytes"
+	"reflect"
+
+	"github.com/blinklabs-io/dingo/database/plugin/metadata/sqlite/models"
+	"github.com/blinklabs-io/dingo/event"
+	"github.com/blinklabs-io/dingo/ledger/eras"
+	"github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+// publishProtocolParameterUpdateEvents generates a ProtocolParameterUpdateEvent for each protocol parameter update
+// proposal in the valid transactions of an applied block
+func (ls *LedgerState) publishProtocolParameterUpdateEvents(block ledger.Block) {
+	blockPoint := ocommon.NewPoint(
+		block.SlotNumber(),
+		block.Hash().Bytes(),
+	)
+	for _, tx := range block.Transactions() {
+		// Proposals in invalid transactions are not applied
+		if !tx.IsValid() {
+			continue
+		}
+		// Pre-Conway updates, which are grouped so that identical updates from multiple genesis keys
+		// generate a single event
+		if updateEpoch, paramUpdates := tx.ProtocolParameterUpdates(); updateEpoch > 0 {
+			var updateEvents []*ProtocolParameterUpdateEvent
+			for genesisHash, update := range paramUpdates {
+				var updateEvent *ProtocolParameterUpdateEvent
+				for _, tmpEvent := range updateEvents {
+					tmpUpdate := tmpEvent.Update.(lcommon.ProtocolParameterUpdate)
+					if bytes.Equal(tmpUpdate.Cbor(), update.Cbor()) {
+						updateEvent = tmpEvent
+						break
+					}
+				}
+				if updateEvent == nil {
+					updateEvent = &ProtocolParameterUpdateEvent{
+						Point:   blockPoint,
+						TxHash:  tx.Hash(),
+						Epoch:   updateEpoch,
+						Update:  update,
+						Changes: pparamsUpdateChanges(ls.currentPParams, update),
+					}
+					updateEvents = append(updateEvents, updateEvent)
+				}
+				updateEvent.GenesisKeyHashes = append(
+					updateEvent.GenesisKeyHashes,
+					genesisHash,
+				)
+			}
+			for _, updateEvent := range updateEvents {
+				ls.config.EventBus.Publish(
+					ProtocolParameterUpdateEventType,
+					event.NewEvent(
+						ProtocolParameterUpdateEventType,
+						*updateEvent,
+					),
+				)
+			}
+		}
+		// Conway parameter change governance actions
+		for idx, proposal := range tx.ProposalProcedures() {
+			action, ok := proposal.GovAction.Action.(*lcommon.ParameterChangeGovAction)
+			if !ok {
+				continue
+			}
+			era := eras.Eras[block.Era().Id]
+			if era.DecodePParamsUpdateFunc == nil {
+				continue
+			}
+			update, err := era.DecodePParamsUpdateFunc(action.ParamUpdate)
+			if err != nil {
+				ls.config.Logger.Warn(
+					"failed to decode protocol parameter update proposal: "+err.Error(),
+					"component", "ledger",
+					"tx", tx.Hash().String(),
+				)
+				continue
+			}
+			ls.config.EventBus.Publish(
+				ProtocolParameterUpdateEventType,
+				event.NewEvent(
+					ProtocolParameterUpdateEventType,
+					ProtocolParameterUpdateEvent{
+						Point:  blockPoint,
+						TxHash: tx.Hash(),
+						GovActionId: &lcommon.GovActionId{
+							TransactionId: tx.Hash(),
+							// #nosec G115
+							GovActionIdx: uint32(idx),
+						},
+						Update:  update,
+						Changes: pparamsUpdateChanges(ls.currentPParams, update),
+					},
+				),
+			)
+		}
+	}
+}
+
+// pparamsUpdateChanges returns the parameters set in an era-specific protocol parameter update. The old value is
+// taken from the matching field in the current protocol parameters, if there is one
+func pparamsUpdateChanges(
+	currentPParams lcommon.ProtocolParameters,
+	update any,
+) map[string]ProtocolParameterChange {
+	ret := make(map[string]ProtocolParameterChange)
+	updateVal := reflect.Indirect(reflect.ValueOf(update))
+	if updateVal.Kind() != reflect.Struct {
+		return ret
+	}
+	currentVal := reflect.Indirect(reflect.ValueOf(currentPParams))
+	for i := range updateVal.NumField() {
+		field := updateVal.Type().Field(i)
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		fieldVal := updateVal.Field(i)
+		if isNilable(fieldVal.Kind()) && fieldVal.IsNil() {
+			continue
+		}
+		change := ProtocolParameterChange{
+			New: reflect.Indirect(fieldVal).Interface(),
+		}
+		if currentVal.Kind() == reflect.Struct {
+			if currentField := currentVal.FieldByName(field.Name); currentField.IsValid() {
+				change.Old = currentField.Interface()
+			}
+		}
+		ret[field.Name] = change
+	}
+	return ret
+}
+
+func isNilable(kind reflect.Kind) bool {
+	return kind == reflect.Pointer ||
+		kind == reflect.Map ||
+		kind == reflect.Slice ||
+		kind == reflect.Interface
+}
+
+// pparamsDiff returns the fields that differ between two sets of protocol parameters of the same type
+func pparamsDiff(
+	oldPParams, newPParams lcommon.ProtocolParameters,
+) map[string]ProtocolParameterChange {
+	ret := make(map[string]ProtocolParameterChange)
+	oldVal := reflect.Indirect(reflect.ValueOf(oldPParams))
+	newVal := reflect.Indirect(reflect.ValueOf(newPParams))
+	if oldVal.Kind() != reflect.Struct || oldVal.Type() != newVal.Type() {
+		return ret
+	}
+	for i := range oldVal.NumField() {
+		field := oldVal.Type().Field(i)
+		if !field.IsExported() || field.Anonymous {
+			continue
+		}
+		oldField := oldVal.Field(i).Interface()
+		newField := newVal.Field(i).Interface()
+		if reflect.DeepEqual(oldField, newField) {
+			continue
+		}
+		ret[field.Name] = ProtocolParameterChange{
+			Old: oldField,
+			New: newField,
+		}
+	}
+	return ret
+}
+
+// copyPParams returns a shallow copy of the protocol parameters. The era-specific update functions modify the
+// protocol parameters in place, so this is needed to keep the previous values around
+func copyPParams(pparams lcommon.ProtocolParameters) lcommon.ProtocolParameters {
+	pparamsVal := reflect.ValueOf(pparams)
+	if pparamsVal.Kind() != reflect.Pointer || pparamsVal.IsNil() {
+		return pparams
+	}
+	ret := reflect.New(pparamsVal.Elem().Type())
+	ret.Elem().Set(pparamsVal.Elem())
+	return ret.Interface().(lcommon.ProtocolParameters)
+}
+
+// enactedPParamUpdateEvent builds the event for a pre-Conway pparam update enacted at an epoch boundary. Only the
+// newest update proposed for the epoch is applied, so the proposing genesis keys are those that submitted it
+func (ls *LedgerState) enactedPParamUpdateEvent(
+	epochStartSlot uint64,
+	pparamUpdates []models.PParamUpdate,
+	prevPParams lcommon.ProtocolParameters,
+) *ProtocolParameterUpdateEvent {
+	appliedUpdate := pparamUpdates[0]
+	ret := &ProtocolParameterUpdateEvent{
+		Point:   ocommon.Point{Slot: epochStartSlot},
+		Epoch:   ls.currentEpoch.EpochId + 1,
+		Enacted: true,
+		Changes: pparamsDiff(prevPParams, ls.currentPParams),
+	}
+	for _, pparamUpdate := range pparamUpdates {
+		if !bytes.Equal(pparamUpdate.Cbor, appliedUpdate.Cbor) {
+			continue
+		}
+		ret.GenesisKeyHashes = append(
+			ret.GenesisKeyHashes,
+			lcommon.NewBlake2b224(pparamUpdate.GenesisHash),
+		)
+	}
+	if update, err := ls.currentEra.DecodePParamsUpdateFunc(appliedUpdate.Cbor); err == nil {
+		ret.Update = update
+	}
+	return ret
+}
@@ -0,0 +1,54 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ledger
+
+import (
+	"testing"
+
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
+)
+
+func TestPParamsUpdateChanges(t *testing.T) {
+	pparams := &babbage.BabbageProtocolParameters{
+		MinFeeA:     44,
+		MinFeeB:     155381,
+		MaxTxSize:   16384,
+		MinPoolCost: 340000000,
+	}
+	minFeeA := uint(45)
+	maxTxSize := uint(32768)
+	update := babbage.BabbageProtocolParameterUpdate{
+		MinFeeA:   &minFeeA,
+		MaxTxSize: &maxTxSize,
+	}
+	// Changes in the proposed update
+	changes := pparamsUpdateChanges(pparams, update)
+	if len(changes) != 2 {
+		t.Fatalf("did not get expected number of changes: got %d, wanted 2", len(changes))
+	}
+	if changes["MinFeeA"].Old != uint(44) || changes["MinFeeA"].New != uint(45) {
+		t.Fatalf("did not get expected MinFeeA change: got %+v", changes["MinFeeA"])
+	}
+	// Changes after applying the update
+	prevPParams := copyPParams(pparams)
+	pparams.Update(&update)
+	diff := pparamsDiff(prevPParams, pparams)
+	if len(diff) != 2 {
+		t.Fatalf("did not get expected number of differences: got %d, wanted 2", len(diff))
+	}
+	if diff["MaxTxSize"].Old != uint(16384) || diff["MaxTxSize"].New != uint(32768) {
+		t.Fatalf("did not get expected MaxTxSize difference: got %+v", diff["MaxTxSize"])
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ledger
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+
+	"github.com/blinklabs-io/dingo/database"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+// RewardCalculator is an extension point for calculating rewards at epoch boundaries. Dingo does not implement reward
+// calculation itself, so downstream projects can provide their own logic via LedgerStateConfig. Dingo does not track
+// the reserves or treasury, since they depend on the reward calculation, so calculators need to track them from the
+// genesis config
+type RewardCalculator interface {
+	// CalculateRewards is called once after each epoch boundary with the inputs for the epoch that just ended. Calls
+	// are made in epoch order from a dedicated goroutine, so they don't hold up block processing
+	CalculateRewards(RewardCalculationInput) error
+}
+
+// RewardCalculationInput contains the ledger inputs for calculating the rewards of an epoch
+type RewardCalculationInput struct {
+	// Epoch that just ended
+	EpochId uint64
+	// Protocol parameters in effect for the new epoch
+	ProtocolParams lcommon.ProtocolParameters
+	// Stake delegated to each pool at the end of the epoch, keyed by bech32 pool ID
+	StakeDistribution map[string]uint64
+	// Registration params of each pool in the stake distribution, keyed by bech32 pool ID. These are read when the
+	// calculation runs, so they may include registrations after the epoch boundary if calculations are backed up
+	PoolParams map[string]lcommon.PoolRegistrationCertificate
+	// Sum of transaction fees (and collateral from invalid transactions) in the epoch that just ended
+	Fees uint64
+}
+
+// noopRewardCalculator is the default RewardCalculator, which does nothing
+type noopRewardCalculator struct{}
+
+func (noopRewardCalculator) CalculateRewards(RewardCalculationInput) error {
+	return nil
+}
+
+// rewardCalculationJob holds the inputs captured at an epoch boundary for a queued reward calculation
+type rewardCalculationJob struct {
+	prevEpoch         database.Epoch
+	protocolParams    lcommon.ProtocolParameters
+	stakeDistribution *stakeDistributionSnapshot
+}
+
+// queueRewardCalculation captures the inputs for the epoch that just ended and queues the reward calculation for the
+// reward goroutine. This is called from the ledger goroutine and doesn't block
+func (ls *LedgerState) queueRewardCalculation(
+	prevEpoch database.Epoch,
+	newEpoch database.Epoch,
+	protocolParams lcommon.ProtocolParameters,
+) {
+	// Avoid the expensive input gathering when there's nothing to call
+	if _, ok := ls.config.RewardCalculator.(noopRewardCalculator); ok {
+		return
+	}
+	job := rewardCalculationJob{
+		prevEpoch:      prevEpoch,
+		protocolParams: protocolParams,
+		// The stake distribution computed at the start of the new epoch is the distribution at the end of this one
+		stakeDistribution: ls.startStakeDistribution(newEpoch.EpochId),
+	}
+	ls.rewardQueueMutex.Lock()
+	ls.rewardQueue = append(ls.rewardQueue, job)
+	ls.rewardQueueMutex.Unlock()
+	// Wake up the reward goroutine if it's not already pending
+	select {
+	case ls.rewardQueueWakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// processRewardQueue runs queued reward calculations in order
+func (ls *LedgerState) processRewardQueue() {
+	for range ls.rewardQueueWakeChan {
+		for {
+			ls.rewardQueueMutex.Lock()
+			if len(ls.rewardQueue) == 0 {
+				ls.rewardQueueMutex.Unlock()
+				break
+			}
+			job := ls.rewardQueue[0]
+			ls.rewardQueue = ls.rewardQueue[1:]
+			ls.rewardQueueMutex.Unlock()
+			if err := ls.calculateRewards(job); err != nil {
+				ls.config.Logger.Error(
+					"failed to calculate rewards: "+err.Error(),
+					"epoch", job.prevEpoch.EpochId,
+					"component", "ledger",
+				)
+			}
+		}
+	}
+}
+
+// calculateRewards gathers the remaining inputs for a queued reward calculation and passes them to the configured
+// RewardCalculator
+func (ls *LedgerState) calculateRewards(job rewardCalculationJob) error {
+	<-job.stakeDistribution.done
+	if err := job.stakeDistribution.err; err != nil {
+		return fmt.Errorf("get stake distribution: %w", err)
+	}
+	input := RewardCalculationInput{
+		EpochId:           job.prevEpoch.EpochId,
+		ProtocolParams:    job.protocolParams,
+		StakeDistribution: maps.Clone(job.stakeDistribution.result),
+		PoolParams:        make(map[string]lcommon.PoolRegistrationCertificate),
+	}
+	txn := ls.db.Transaction(false)
+	err := txn.Do(func(txn *database.Txn) error {
+		for poolIdStr := range input.StakeDistribution {
+			poolId, err := lcommon.NewPoolIdFromBech32(poolIdStr)
+			if err != nil {
+				return fmt.Errorf("decode pool ID: %w", err)
+			}
+			certs, err := ls.db.GetPoolRegistrations(
+				lcommon.PoolKeyHash(poolId),
+				txn,
+			)
+			if err != nil {
+				return fmt.Errorf("get pool registrations: %w", err)
+			}
+			// Registrations are returned newest first
+			if len(certs) > 0 {
+				input.PoolParams[poolIdStr] = certs[0]
+			}
+		}
+		fees, err := ls.epochFees(txn, job.prevEpoch)
+		if err != nil {
+			return err
+		}
+		input.Fees = fees
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ls.config.RewardCalculator.CalculateRewards(input)
+}
+
+// epochFees returns the sum of fees collected from the blocks in the specified epoch
+func (ls *LedgerState) epochFees(
+	txn *database.Txn,
+	epoch database.Epoch,
+) (uint64, error) {
+	blockIndex := database.BlockInitialIndex
+	blockStore := ls.config.ChainManager.BlockStore()
+	prevBlock, err := blockStore.GetBeforeSlot(epoch.StartSlot, txn)
+	if err != nil {
+		if !errors.Is(err, database.ErrBlockNotFound) {
+			return 0, fmt.Errorf("get block before epoch: %w", err)
+		}
+	} else {
+		blockIndex = prevBlock.ID + 1
+	}
+	endSlot := epoch.StartSlot + uint64(epoch.LengthInSlots)
+	var fees uint64
+	for ; ; blockIndex++ {
+		tmpBlock, err := blockStore.GetByIndex(blockIndex, txn)
+		if err != nil {
+			if errors.Is(err, database.ErrBlockNotFound) {
+				break
+			}
+			return 0, fmt.Errorf("get block: %w", err)
+		}
+		if tmpBlock.Slot >= endSlot {
+			break
+		}
+		block, err := tmpBlock.Decode()
+		if err != nil {
+			return 0, fmt.Errorf("decode block: %w", err)
+		}
+		for _, tx := range block.Transactions() {
+			// Invalid transactions forfeit their collateral instead of paying the fee
+			if tx.IsValid() {
+				fees += tx.Fee()
+			} else {
+				fees += tx.TotalCollateral()
+			}
+		}
+	}
+	return fees, nil
+}
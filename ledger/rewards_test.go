@@ -0,0 +1,104 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ledger
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/dingo/database"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+type testRewardCalculator struct {
+	inputChan   chan RewardCalculationInput
+	releaseChan chan struct{}
+}
+
+func (c *testRewardCalculator) CalculateRewards(input RewardCalculationInput) error {
+	<-c.releaseChan
+	c.inputChan <- input
+	return nil
+}
+
+func TestRewardCalculationQueue(t *testing.T) {
+	ls := newTestStakeLedgerState(t)
+	cm, err := chain.NewManager(ls.db, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain manager: %s", err)
+	}
+	calculator := &testRewardCalculator{
+		inputChan:   make(chan RewardCalculationInput, 10),
+		releaseChan: make(chan struct{}),
+	}
+	ls.config.ChainManager = cm
+	ls.config.RewardCalculator = calculator
+	ls.rewardQueueWakeChan = make(chan struct{}, 1)
+	go ls.processRewardQueue()
+	pool := bytes.Repeat([]byte{0xaa}, lcommon.Blake2b224Size)
+	poolId := lcommon.PoolId(lcommon.NewBlake2b224(pool)).String()
+	addTestStake(t, ls, bytes.Repeat([]byte{0x11}, lcommon.AddressHashSize), pool, 100)
+	// Queueing doesn't wait for the calculator, which is blocked until we release it
+	queuedChan := make(chan struct{})
+	go func() {
+		for epochId := range uint64(3) {
+			ls.queueRewardCalculation(
+				database.Epoch{EpochId: epochId, LengthInSlots: 100},
+				database.Epoch{EpochId: epochId + 1},
+				nil,
+			)
+		}
+		close(queuedChan)
+	}()
+	select {
+	case <-queuedChan:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("queueing reward calculations blocked on the calculator")
+	}
+	close(calculator.releaseChan)
+	// Calculations are run in epoch order
+	for epochId := range uint64(3) {
+		select {
+		case input := <-calculator.inputChan:
+			if input.EpochId != epochId {
+				t.Fatalf("did not get expected epoch: got %d, expected %d", input.EpochId, epochId)
+			}
+			if input.StakeDistribution[poolId] != 100 {
+				t.Fatalf("did not get expected stake distribution: got %v", input.StakeDistribution)
+			}
+			if input.Fees != 0 {
+				t.Fatalf("did not get expected fees: got %d, expected 0", input.Fees)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout waiting for reward calculation")
+		}
+	}
+}
+
+func TestRewardCalculationNoop(t *testing.T) {
+	ls := newTestStakeLedgerState(t)
+	ls.config.RewardCalculator = noopRewardCalculator{}
+	ls.queueRewardCalculation(
+		database.Epoch{EpochId: 0, LengthInSlots: 100},
+		database.Epoch{EpochId: 1},
+		nil,
+	)
+	// Nothing is gathered for the default calculator
+	if len(ls.rewardQueue) != 0 || ls.stakeDistribution != nil {
+		t.Fatalf("expected no reward calculation to be queued")
+	}
+}
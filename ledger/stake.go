@@ -0,0 +1,120 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ledger
+
+import (
+	"fmt"
+	"maps"
+
+	"github.com/blinklabs-io/dingo/database"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+// stakeDistributionSnapshot is a stake distribution computed for an epoch. The done channel is closed once the result
+// or error is available
+type stakeDistributionSnapshot struct {
+	epochId uint64
+	done    chan struct{}
+	result  map[string]uint64
+	err     error
+}
+
+// StakeDistribution returns the live stake delegated to each pool, keyed by bech32 pool ID. Computing this requires
+// visiting every delegated account and its UTxOs, so it's computed in the background at each epoch boundary and cached
+// for the rest of the epoch. The returned values reflect the ledger state shortly after the start of the current epoch,
+// not the current tip, and do not include rewards or deposits. If the distribution for the current epoch isn't ready
+// yet, such as while the node is bulk syncing or just after startup, this blocks until it has been computed
+func (ls *LedgerState) StakeDistribution() (map[string]uint64, error) {
+	ls.RLock()
+	epochId := ls.currentEpoch.EpochId
+	ls.RUnlock()
+	return ls.stakeDistributionForEpoch(epochId)
+}
+
+// stakeDistributionForEpoch returns the stake distribution for the specified epoch, starting the computation if
+// needed and waiting for it to finish
+func (ls *LedgerState) stakeDistributionForEpoch(epochId uint64) (map[string]uint64, error) {
+	snapshot := ls.startStakeDistribution(epochId)
+	<-snapshot.done
+	if snapshot.err != nil {
+		return nil, snapshot.err
+	}
+	return maps.Clone(snapshot.result), nil
+}
+
+// startStakeDistribution starts computing the stake distribution for the specified epoch in the background, unless
+// it's already been computed or is in progress
+func (ls *LedgerState) startStakeDistribution(epochId uint64) *stakeDistributionSnapshot {
+	ls.stakeDistributionMutex.Lock()
+	defer ls.stakeDistributionMutex.Unlock()
+	if ls.stakeDistribution != nil && ls.stakeDistribution.epochId == epochId {
+		return ls.stakeDistribution
+	}
+	snapshot := &stakeDistributionSnapshot{
+		epochId: epochId,
+		done:    make(chan struct{}),
+	}
+	ls.stakeDistribution = snapshot
+	go func() {
+		snapshot.result, snapshot.err = ls.computeStakeDistribution()
+		if snapshot.err != nil {
+			// Allow the next call to try again
+			ls.stakeDistributionMutex.Lock()
+			if ls.stakeDistribution == snapshot {
+				ls.stakeDistribution = nil
+			}
+			ls.stakeDistributionMutex.Unlock()
+		}
+		close(snapshot.done)
+	}()
+	return snapshot
+}
+
+// computeStakeDistribution sums the UTxO values of each delegated account by pool. This uses its own read-only
+// transaction and does not hold the ledger lock, so block processing continues while it runs
+func (ls *LedgerState) computeStakeDistribution() (map[string]uint64, error) {
+	ret := make(map[string]uint64)
+	txn := ls.db.Transaction(false)
+	err := txn.Do(func(txn *database.Txn) error {
+		accounts, err := ls.db.GetDelegatedAccounts(txn)
+		if err != nil {
+			return fmt.Errorf("failed to get delegated accounts: %w", err)
+		}
+		for _, account := range accounts {
+			if len(account.Pool) != lcommon.Blake2b224Size {
+				continue
+			}
+			utxos, err := ls.db.UtxosByStakingKey(account.StakingKey, txn)
+			if err != nil {
+				return fmt.Errorf("failed to get UTxOs for account: %w", err)
+			}
+			var stake uint64
+			for _, utxo := range utxos {
+				output, err := utxo.Decode()
+				if err != nil {
+					return fmt.Errorf("failed to decode UTxO: %w", err)
+				}
+				stake += output.Amount()
+			}
+			poolId := lcommon.PoolId(lcommon.NewBlake2b224(account.Pool))
+			ret[poolId.String()] += stake
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
@@ -0,0 +1,177 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ledger
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/types"
+	"github.com/blinklabs-io/gouroboros/cbor"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/shelley"
+)
+
+func newTestStakeLedgerState(t *testing.T) *LedgerState {
+	db, err := database.New(nil, nil, "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error creating database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &LedgerState{
+		config: LedgerStateConfig{
+			Logger: slog.New(slog.NewJSONHandler(io.Discard, nil)),
+		},
+		db: db,
+	}
+}
+
+// addTestStake adds an account with the specified stake key delegated to the specified pool, if any, along with a
+// UTxO holding each of the specified amounts
+func addTestStake(
+	t *testing.T,
+	ls *LedgerState,
+	stakeKey []byte,
+	pool []byte,
+	amounts ...uint64,
+) {
+	txn := ls.db.Transaction(true)
+	err := txn.Do(func(txn *database.Txn) error {
+		if err := ls.db.SetAccount(stakeKey, pool, nil, 0, true, txn); err != nil {
+			return err
+		}
+		for _, amount := range amounts {
+			addr, err := lcommon.NewAddressFromParts(
+				lcommon.AddressTypeKeyKey,
+				lcommon.AddressNetworkTestnet,
+				bytes.Repeat([]byte{0x01}, lcommon.AddressHashSize),
+				stakeKey,
+			)
+			if err != nil {
+				return err
+			}
+			outputCbor, err := cbor.Encode(
+				&shelley.ShelleyTransactionOutput{
+					OutputAddress: addr,
+					OutputAmount:  amount,
+				},
+			)
+			if err != nil {
+				return err
+			}
+			output, err := shelley.NewShelleyTransactionOutputFromCbor(outputCbor)
+			if err != nil {
+				return err
+			}
+			txId := lcommon.Blake2b256Hash(append(stakeKey, outputCbor...))
+			utxo := lcommon.Utxo{
+				Id:     shelley.NewShelleyTransactionInput(txId.String(), 0),
+				Output: output,
+			}
+			if err := ls.db.AddUtxos([]types.UtxoSlot{{Utxo: utxo}}, txn); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error adding test stake: %s", err)
+	}
+}
+
+func TestStakeDistribution(t *testing.T) {
+	ls := newTestStakeLedgerState(t)
+	poolA := bytes.Repeat([]byte{0xaa}, lcommon.Blake2b224Size)
+	poolB := bytes.Repeat([]byte{0xbb}, lcommon.Blake2b224Size)
+	addTestStake(t, ls, bytes.Repeat([]byte{0x11}, lcommon.AddressHashSize), poolA, 100, 200)
+	addTestStake(t, ls, bytes.Repeat([]byte{0x12}, lcommon.AddressHashSize), poolA, 50)
+	addTestStake(t, ls, bytes.Repeat([]byte{0x13}, lcommon.AddressHashSize), poolB, 1000)
+	// Undelegated stake isn't counted
+	addTestStake(t, ls, bytes.Repeat([]byte{0x14}, lcommon.AddressHashSize), nil, 5000)
+	stakeDistribution, err := ls.StakeDistribution()
+	if err != nil {
+		t.Fatalf("unexpected error getting stake distribution: %s", err)
+	}
+	expected := map[string]uint64{
+		lcommon.PoolId(lcommon.NewBlake2b224(poolA)).String(): 350,
+		lcommon.PoolId(lcommon.NewBlake2b224(poolB)).String(): 1000,
+	}
+	if len(stakeDistribution) != len(expected) {
+		t.Fatalf("did not get expected stake distribution: got %v, expected %v", stakeDistribution, expected)
+	}
+	for poolId, stake := range expected {
+		if stakeDistribution[poolId] != stake {
+			t.Fatalf("did not get expected stake distribution: got %v, expected %v", stakeDistribution, expected)
+		}
+	}
+}
+
+func TestStakeDistributionCachedPerEpoch(t *testing.T) {
+	ls := newTestStakeLedgerState(t)
+	pool := bytes.Repeat([]byte{0xaa}, lcommon.Blake2b224Size)
+	poolId := lcommon.PoolId(lcommon.NewBlake2b224(pool)).String()
+	addTestStake(t, ls, bytes.Repeat([]byte{0x11}, lcommon.AddressHashSize), pool, 100)
+	stakeDistribution, err := ls.stakeDistributionForEpoch(1)
+	if err != nil {
+		t.Fatalf("unexpected error getting stake distribution: %s", err)
+	}
+	if stakeDistribution[poolId] != 100 {
+		t.Fatalf("did not get expected stake: got %d, expected 100", stakeDistribution[poolId])
+	}
+	// Changes within the epoch aren't reflected
+	addTestStake(t, ls, bytes.Repeat([]byte{0x12}, lcommon.AddressHashSize), pool, 50)
+	stakeDistribution, err = ls.stakeDistributionForEpoch(1)
+	if err != nil {
+		t.Fatalf("unexpected error getting stake distribution: %s", err)
+	}
+	if stakeDistribution[poolId] != 100 {
+		t.Fatalf("did not get expected cached stake: got %d, expected 100", stakeDistribution[poolId])
+	}
+	// The next epoch is recomputed
+	stakeDistribution, err = ls.stakeDistributionForEpoch(2)
+	if err != nil {
+		t.Fatalf("unexpected error getting stake distribution: %s", err)
+	}
+	if stakeDistribution[poolId] != 150 {
+		t.Fatalf("did not get expected stake: got %d, expected 150", stakeDistribution[poolId])
+	}
+}
+
+func TestStakeDistributionWithoutLedgerLock(t *testing.T) {
+	ls := newTestStakeLedgerState(t)
+	addTestStake(
+		t,
+		ls,
+		bytes.Repeat([]byte{0x11}, lcommon.AddressHashSize),
+		bytes.Repeat([]byte{0xaa}, lcommon.Blake2b224Size),
+		100,
+	)
+	// Computing the distribution at an epoch boundary doesn't need the ledger lock, which is held while applying blocks
+	ls.Lock()
+	defer ls.Unlock()
+	snapshot := ls.startStakeDistribution(1)
+	select {
+	case <-snapshot.done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("stake distribution computation blocked on ledger lock")
+	}
+	if snapshot.err != nil {
+		t.Fatalf("unexpected error computing stake distribution: %s", snapshot.err)
+	}
+}
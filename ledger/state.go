@@ -15,23 +15,30 @@
 package ledger
 
 import (
+	"bytes"
 	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/blinklabs-io/dingo/chain"
 	"github.com/blinklabs-io/dingo/config/cardano"
+	"github.com/blinklabs-io/dingo/connmanager"
 	"github.com/blinklabs-io/dingo/database"
 	"github.com/blinklabs-io/dingo/event"
 	"github.com/blinklabs-io/dingo/ledger/eras"
 	ouroboros "github.com/blinklabs-io/gouroboros"
 	"github.com/blinklabs-io/gouroboros/cbor"
 	"github.com/blinklabs-io/gouroboros/ledger"
+	"github.com/blinklabs-io/gouroboros/ledger/alonzo"
+	"github.com/blinklabs-io/gouroboros/ledger/babbage"
 	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+	"github.com/blinklabs-io/gouroboros/ledger/conway"
 	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
 	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
 	"github.com/prometheus/client_golang/prometheus"
@@ -42,6 +49,27 @@ const (
 	cleanupConsumedUtxosSlotWindow = 50000 // TODO: calculate this from params (#395)
 
 	validateHistoricalThreshold = 14 * (24 * time.Hour) // 2 weeks
+
+	// Number of recently accepted chain points to persist for resuming chainsync
+	syncCursorPointCount = 100
+
+	// Number of chainsync cursor updates between removals of old cursor points
+	syncCursorTrimInterval = 100
+
+	// Default max number of blocks to apply in a single DB transaction
+	DefaultBlockApplyBatchSize = 50
+
+	// Default max time to spend applying blocks in a single DB transaction before committing
+	DefaultBlockApplyFlushInterval = 5 * time.Second
+
+	// Blocks with a slot time closer than this to the current time are committed individually
+	blockApplyTipThreshold = 10 * time.Minute
+
+	// Minimum number of blocks to apply in a single DB transaction in bulk sync mode
+	bulkSyncBlockApplyBatchSize = 200
+
+	// Minimum time to spend applying blocks in a single DB transaction in bulk sync mode
+	bulkSyncBlockApplyFlushInterval = 30 * time.Second
 )
 
 type ChainsyncState string
@@ -60,14 +88,44 @@ type LedgerStateConfig struct {
 	CardanoNodeConfig  *cardano.CardanoNodeConfig
 	PromRegistry       prometheus.Registerer
 	ValidateHistorical bool
+	// BlockfetchBatchSize is the max number of blocks to request in a single blockfetch range. This defaults to 500
+	BlockfetchBatchSize int
+	// BlockfetchMaxInflightBytes is the max bytes of fetched blocks to buffer before processing them. A value of 0
+	// means no limit
+	BlockfetchMaxInflightBytes int
+	// RewardCalculator is called at each epoch boundary. This defaults to a no-op implementation
+	RewardCalculator RewardCalculator
+	// VerifyCborRoundtrip enables re-encoding each block before it's applied and comparing the result to the
+	// original CBOR, logging a warning on mismatch. This is expensive and disabled by default
+	VerifyCborRoundtrip bool
+	// BlockApplyBatchSize is the max number of blocks to apply in a single DB transaction during bulk sync. Blocks near
+	// the current time are always committed individually. This defaults to 50
+	BlockApplyBatchSize int
+	// BlockApplyFlushInterval is the max time to spend applying blocks in a single DB transaction before committing
+	// them. This defaults to 5s
+	BlockApplyFlushInterval time.Duration
+	// BulkSync enables bulk sync mode, which uses larger DB write batches and collects more block headers ahead of
+	// blockfetch. This is intended for syncing from far behind the tip, and is switched off once we're within
+	// BulkSyncSlotThreshold slots of the upstream tip
+	BulkSync bool
+	// BulkSyncSlotThreshold is the distance in slots from the upstream tip at which bulk sync mode is switched off.
+	// This defaults to the security parameter (k) from the Shelley genesis
+	BulkSyncSlotThreshold uint64
 	// Callback(s)
 	BlockfetchRequestRangeFunc BlockfetchRequestRangeFunc
+	// BlockfetchConnectionIdsFunc returns the connections that a block range can be requested from, in order of
+	// preference. The first connection is used for each block range request, and the rest are used to re-request the
+	// unfetched part of a block range when a peer disconnects mid-range
+	BlockfetchConnectionIdsFunc BlockfetchConnectionIdsFunc
 }
 
 // BlockfetchRequestRangeFunc describes a callback function used to start a blockfetch request for
 // a range of blocks
 type BlockfetchRequestRangeFunc func(ouroboros.ConnectionId, ocommon.Point, ocommon.Point) error
 
+// BlockfetchConnectionIdsFunc describes a callback function used to list the connections available for blockfetch
+type BlockfetchConnectionIdsFunc func() []ouroboros.ConnectionId
+
 type LedgerState struct {
 	sync.RWMutex
 	chainsyncMutex                   sync.Mutex
@@ -83,26 +141,51 @@ type LedgerState struct {
 	currentTipBlockNonce             []byte
 	metrics                          stateMetrics
 	chainsyncBlockEvents             []BlockfetchEvent
+	chainsyncBlockEventsBytes        int
 	chainsyncBlockfetchBusyTime      time.Time
+	chainsyncBlockfetchConnId        ouroboros.ConnectionId
 	chainsyncBlockfetchBatchDoneChan chan struct{}
 	chainsyncBlockfetchReadyChan     chan struct{}
 	chainsyncBlockfetchMutex         sync.Mutex
 	chainsyncBlockfetchWaiting       bool
+	chainsyncUpstreamTip             ochainsync.Tip
+	bulkSync                         atomic.Bool
+	blockfetchProgress               BlockfetchProgressEvent
+	blockfetchProgressTime           time.Time
 	chain                            *chain.Chain
+	intersectCache                   *intersectCache
+	stakeDistribution                *stakeDistributionSnapshot
+	stakeDistributionMutex           sync.Mutex
+	rewardQueue                      []rewardCalculationJob
+	rewardQueueMutex                 sync.Mutex
+	rewardQueueWakeChan              chan struct{}
+	syncCursorUpdates                int
 }
 
 func NewLedgerState(cfg LedgerStateConfig) (*LedgerState, error) {
+	// Make sure the era hard fork functions line up before we start syncing
+	if err := eras.ValidateEras(); err != nil {
+		return nil, fmt.Errorf("invalid era configuration: %w", err)
+	}
 	ls := &LedgerState{
 		config:         cfg,
 		chainsyncState: InitChainsyncState,
 		db:             cfg.Database,
 		chain:          cfg.ChainManager.PrimaryChain(),
+		intersectCache: newIntersectCache(),
+		// Buffered so that a wakeup isn't lost while the reward goroutine is busy
+		rewardQueueWakeChan: make(chan struct{}, 1),
 	}
 	if cfg.Logger == nil {
 		// Create logger to throw away logs
 		// We do this so we don't have to add guards around every log operation
 		cfg.Logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
 	}
+	if cfg.RewardCalculator == nil {
+		cfg.RewardCalculator = noopRewardCalculator{}
+	}
+	ls.config = cfg
+	ls.bulkSync.Store(cfg.BulkSync)
 	return ls, nil
 }
 
@@ -118,8 +201,18 @@ func (ls *LedgerState) Start() error {
 		BlockfetchEventType,
 		ls.handleEventBlockfetch,
 	)
+	ls.config.EventBus.SubscribeFunc(
+		connmanager.ConnectionClosedEventType,
+		ls.handleEventConnectionClosed,
+	)
 	// Schedule periodic process to purge consumed UTxOs outside of the rollback window
 	ls.scheduleCleanupConsumedUtxos()
+	if ls.bulkSync.Load() {
+		ls.config.Logger.Info(
+			"starting in bulk sync mode",
+			"component", "ledger",
+		)
+	}
 	// Load epoch info from DB
 	if err := ls.loadEpochs(nil); err != nil {
 		return fmt.Errorf("failed to load epoch info: %w", err)
@@ -138,6 +231,28 @@ func (ls *LedgerState) Start() error {
 	}
 	// Start goroutine to process new blocks
 	go ls.ledgerProcessBlocks()
+	// Start goroutine to calculate rewards at epoch boundaries
+	go ls.processRewardQueue()
+	return nil
+}
+
+// CheckTipConsistency makes sure that the ledger tip stored in the metadata store can be found in the chain in the
+// blob store. A TipMismatchError is returned if it can't, which can be fixed with RecoverCommitTimestampConflict
+func (ls *LedgerState) CheckTipConsistency() error {
+	tmpTip, err := ls.db.GetTip(nil)
+	if err != nil {
+		return fmt.Errorf("failed to get tip: %w", err)
+	}
+	// Nothing has been applied to the ledger yet
+	if len(tmpTip.Point.Hash) == 0 {
+		return nil
+	}
+	if _, err := ls.chain.BlockByPoint(tmpTip.Point, nil); err != nil {
+		return TipMismatchError{
+			LedgerTip: tmpTip,
+			ChainTip:  ls.chain.Tip(),
+		}
+	}
 	return nil
 }
 
@@ -249,12 +364,17 @@ func (ls *LedgerState) rollback(point ocommon.Point) error {
 		if err = ls.db.SetTip(ls.currentTip, txn); err != nil {
 			return fmt.Errorf("failed to set tip: %w", err)
 		}
+		// Remove rolled-back points from chainsync cursor
+		if err = ls.db.DeleteSyncCursorAfterSlot(point.Slot, txn); err != nil {
+			return fmt.Errorf("failed to update sync cursor: %w", err)
+		}
 		ls.updateTipMetrics()
 		return nil
 	})
 	if err != nil {
 		return err
 	}
+	ls.intersectCache.invalidateAfter(point)
 	// Reload tip
 	if err := ls.loadTip(); err != nil {
 		return fmt.Errorf("failed to load tip: %w", err)
@@ -426,6 +546,28 @@ func (ls *LedgerState) ledgerReadChain(resultCh chan readChainResult) {
 	}
 }
 
+func (ls *LedgerState) blockApplyBatchSize() int {
+	batchSize := DefaultBlockApplyBatchSize
+	if ls.config.BlockApplyBatchSize > 0 {
+		batchSize = ls.config.BlockApplyBatchSize
+	}
+	if ls.bulkSync.Load() {
+		batchSize = max(batchSize, bulkSyncBlockApplyBatchSize)
+	}
+	return batchSize
+}
+
+func (ls *LedgerState) blockApplyFlushInterval() time.Duration {
+	flushInterval := DefaultBlockApplyFlushInterval
+	if ls.config.BlockApplyFlushInterval > 0 {
+		flushInterval = ls.config.BlockApplyFlushInterval
+	}
+	if ls.bulkSync.Load() {
+		flushInterval = max(flushInterval, bulkSyncBlockApplyFlushInterval)
+	}
+	return flushInterval
+}
+
 func (ls *LedgerState) ledgerProcessBlocks() {
 	// Start chain reader goroutine
 	readChainResultCh := make(chan readChainResult)
@@ -439,11 +581,15 @@ func (ls *LedgerState) ledgerProcessBlocks() {
 	var nextBatch, cachedNextBatch []ledger.Block
 	var delta *LedgerDelta
 	var deltaBatch LedgerDeltaBatch
+	var appliedBlocks []ledger.Block
 	shouldValidate := ls.config.ValidateHistorical
 	for {
 		if needsEpochRollover {
 			ls.Lock()
 			needsEpochRollover = false
+			prevEra := ls.currentEra
+			prevEpoch := ls.currentEpoch
+			var pparamUpdateEvent *ProtocolParameterUpdateEvent
 			txn := ls.db.Transaction(true)
 			err := txn.Do(func(txn *database.Txn) error {
 				// Check for era change
@@ -456,11 +602,16 @@ func (ls *LedgerState) ledgerProcessBlocks() {
 					}
 				}
 				// Process epoch rollover
-				if err := ls.processEpochRollover(txn); err != nil {
+				tmpEvent, err := ls.processEpochRollover(txn)
+				if err != nil {
 					return err
 				}
+				pparamUpdateEvent = tmpEvent
 				return nil
 			})
+			newEra := ls.currentEra
+			newEpoch := ls.currentEpoch
+			newPParams := ls.currentPParams
 			ls.Unlock()
 			if err != nil {
 				ls.config.Logger.Error(
@@ -468,6 +619,59 @@ func (ls *LedgerState) ledgerProcessBlocks() {
 				)
 				return
 			}
+			// Generate era transition event
+			if newEra.Id != prevEra.Id {
+				ls.config.EventBus.Publish(
+					EraTransitionEventType,
+					event.NewEvent(
+						EraTransitionEventType,
+						EraTransitionEvent{
+							OldEraId:   prevEra.Id,
+							OldEraName: prevEra.Name,
+							NewEraId:   newEra.Id,
+							NewEraName: newEra.Name,
+							StartSlot:  newEpoch.StartSlot,
+							StartEpoch: newEpoch.EpochId,
+						},
+					),
+				)
+			}
+			// Generate epoch transition event. The initial epoch won't have a length set before rollover
+			if newEpoch.EpochId != prevEpoch.EpochId ||
+				prevEpoch.LengthInSlots == 0 {
+				ls.config.EventBus.Publish(
+					EpochTransitionEventType,
+					event.NewEvent(
+						EpochTransitionEventType,
+						EpochTransitionEvent{
+							EpochId:       newEpoch.EpochId,
+							StartSlot:     newEpoch.StartSlot,
+							LengthInSlots: newEpoch.LengthInSlots,
+							EraId:         newEpoch.EraId,
+						},
+					),
+				)
+			}
+			// Generate pparam update event if an update was enacted
+			if pparamUpdateEvent != nil {
+				ls.config.EventBus.Publish(
+					ProtocolParameterUpdateEventType,
+					event.NewEvent(
+						ProtocolParameterUpdateEventType,
+						*pparamUpdateEvent,
+					),
+				)
+			}
+			// Start computing the stake distribution for the new epoch in the background. We skip this while bulk
+			// syncing, since epochs go by faster than it can be computed, and compute it on demand instead
+			if newEpoch.EpochId != prevEpoch.EpochId && !ls.bulkSync.Load() {
+				ls.startStakeDistribution(newEpoch.EpochId)
+			}
+			// Calculate rewards for the epoch that just ended in the background
+			if newEpoch.EpochId != prevEpoch.EpochId &&
+				prevEpoch.LengthInSlots > 0 {
+				ls.queueRewardCalculation(prevEpoch, newEpoch, newPParams)
+			}
 		}
 		if cachedNextBatch != nil {
 			// Use cached block batch
@@ -494,17 +698,25 @@ func (ls *LedgerState) ledgerProcessBlocks() {
 				continue
 			}
 		}
-		// Process batch in groups of 50 to stay under DB txn limits
-		for i = 0; i < len(nextBatch); i += 50 {
+		// Process batch in groups, each in its own DB transaction. The tip and sync cursor are written in the
+		// same transaction as the blocks, so a crash mid-batch leaves the ledger at the last committed group
+		for i = 0; i < len(nextBatch); i = end {
+			batchSize := ls.blockApplyBatchSize()
+			flushInterval := ls.blockApplyFlushInterval()
 			ls.Lock()
-			end = min(
-				len(nextBatch),
-				i+50,
-			)
+			end = i
 			txn = ls.db.Transaction(true)
 			err = txn.Do(func(txn *database.Txn) error {
 				deltaBatch = LedgerDeltaBatch{}
-				for offset, next := range nextBatch[i:end] {
+				appliedBlocks = appliedBlocks[:0]
+				groupStart := time.Now()
+				nearTip := false
+				for offset, next := range nextBatch[i:min(len(nextBatch), i+batchSize)] {
+					// Commit what we have so far if we're near the tip or have hit the time threshold
+					if offset > 0 &&
+						(nearTip || time.Since(groupStart) >= flushInterval) {
+						break
+					}
 					tmpPoint := ocommon.Point{
 						Slot: next.SlotNumber(),
 						Hash: next.Hash().Bytes(),
@@ -518,8 +730,7 @@ func (ls *LedgerState) ledgerProcessBlocks() {
 						cachedNextBatch = nextBatch[i+offset:]
 						break
 					}
-					// Enable validation if we're getting near current tip
-					if !shouldValidate && i == 0 {
+					if offset == 0 {
 						// Determine wall time for next block slot
 						slotTime, err := ls.SlotToTime(tmpPoint.Slot)
 						if err != nil {
@@ -530,14 +741,19 @@ func (ls *LedgerState) ledgerProcessBlocks() {
 						}
 						// Check difference from current time
 						timeDiff := time.Since(slotTime)
-						if timeDiff < validateHistoricalThreshold {
+						// Enable validation if we're getting near current tip
+						if !shouldValidate && i == 0 &&
+							timeDiff < validateHistoricalThreshold {
 							shouldValidate = true
 							ls.config.Logger.Debug(
 								"enabling validation as we approach tip",
 							)
 						}
+						// Commit blocks individually near the tip for durability
+						nearTip = timeDiff < blockApplyTipThreshold
 					}
 					// Process block
+					applyStart := time.Now()
 					delta, err = ls.ledgerProcessBlock(
 						txn,
 						tmpPoint,
@@ -547,6 +763,10 @@ func (ls *LedgerState) ledgerProcessBlocks() {
 					if err != nil {
 						return err
 					}
+					eraName := next.Era().Name
+					ls.metrics.blockApplyDuration.WithLabelValues(eraName).
+						Observe(time.Since(applyStart).Seconds())
+					ls.metrics.blocksApplied.WithLabelValues(eraName).Inc()
 					if delta != nil {
 						deltaBatch.addDelta(delta)
 					}
@@ -583,6 +803,8 @@ func (ls *LedgerState) ledgerProcessBlocks() {
 					}
 					// Update tip block nonce
 					ls.currentTipBlockNonce = blockNonce
+					appliedBlocks = append(appliedBlocks, next)
+					end = i + offset + 1
 				}
 				// Apply delta batch
 				if err := deltaBatch.apply(ls, txn); err != nil {
@@ -592,6 +814,17 @@ func (ls *LedgerState) ledgerProcessBlocks() {
 				if err := ls.db.SetTip(ls.currentTip, txn); err != nil {
 					return fmt.Errorf("failed to set tip: %w", err)
 				}
+				// Record tip in chainsync cursor
+				if err := ls.db.AddSyncCursorPoint(ls.currentTip.Point, txn); err != nil {
+					return fmt.Errorf("failed to update sync cursor: %w", err)
+				}
+				ls.syncCursorUpdates++
+				if ls.syncCursorUpdates >= syncCursorTrimInterval {
+					if err := ls.db.TrimSyncCursor(syncCursorPointCount, txn); err != nil {
+						return fmt.Errorf("failed to trim sync cursor: %w", err)
+					}
+					ls.syncCursorUpdates = 0
+				}
 				ls.updateTipMetrics()
 				return nil
 			})
@@ -603,6 +836,27 @@ func (ls *LedgerState) ledgerProcessBlocks() {
 				return
 			}
 			ls.Unlock()
+			// Generate events for applied blocks
+			for _, tmpBlock := range appliedBlocks {
+				ls.config.EventBus.Publish(
+					BlockEventType,
+					event.NewEvent(
+						BlockEventType,
+						BlockEvent{
+							Point: ocommon.NewPoint(
+								tmpBlock.SlotNumber(),
+								tmpBlock.Hash().Bytes(),
+							),
+							BlockNumber: tmpBlock.BlockNumber(),
+							Type:        uint(tmpBlock.Type()), // #nosec G115
+							Cbor:        tmpBlock.Cbor(),
+							Block:       tmpBlock,
+						},
+					),
+				)
+				ls.publishCertificateEvents(tmpBlock)
+				ls.publishProtocolParameterUpdateEvents(tmpBlock)
+			}
 			if needsEpochRollover {
 				break
 			}
@@ -627,6 +881,29 @@ func (ls *LedgerState) ledgerProcessBlocks() {
 	}
 }
 
+// publishCertificateEvents generates a CertificateEvent for each certificate in the valid transactions of an applied block
+func (ls *LedgerState) publishCertificateEvents(block ledger.Block) {
+	blockPoint := ocommon.NewPoint(
+		block.SlotNumber(),
+		block.Hash().Bytes(),
+	)
+	for _, tx := range block.Transactions() {
+		// Certificates in invalid transactions are not applied
+		if !tx.IsValid() {
+			continue
+		}
+		for _, cert := range tx.Certificates() {
+			ls.config.EventBus.Publish(
+				CertificateEventType,
+				event.NewEvent(
+					CertificateEventType,
+					newCertificateEvent(blockPoint, tx.Hash(), cert),
+				),
+			)
+		}
+	}
+}
+
 func (ls *LedgerState) ledgerProcessBlock(
 	txn *database.Txn,
 	point ocommon.Point,
@@ -648,6 +925,9 @@ func (ls *LedgerState) ledgerProcessBlock(
 			)
 		}
 	}
+	if ls.config.VerifyCborRoundtrip {
+		ls.verifyBlockCborRoundtrip(block)
+	}
 	// Process transactions
 	var delta *LedgerDelta
 	for _, tx := range block.Transactions() {
@@ -694,6 +974,32 @@ func (ls *LedgerState) ledgerProcessBlock(
 	return delta, nil
 }
 
+// verifyBlockCborRoundtrip re-encodes a decoded block and logs a warning if the result doesn't match the original CBOR,
+// which indicates a decoder/encoder mismatch that could otherwise go unnoticed
+func (ls *LedgerState) verifyBlockCborRoundtrip(block ledger.Block) {
+	origCbor := block.Cbor()
+	newCbor, err := cbor.Encode(block)
+	if err != nil {
+		ls.config.Logger.Warn(
+			"failed to re-encode block for CBOR round-trip check: "+err.Error(),
+			"component", "ledger",
+			"block", block.Hash().String(),
+			"slot", block.SlotNumber(),
+		)
+		return
+	}
+	if !bytes.Equal(origCbor, newCbor) {
+		ls.config.Logger.Warn(
+			"block CBOR round-trip mismatch",
+			"component", "ledger",
+			"block", block.Hash().String(),
+			"slot", block.SlotNumber(),
+			"original_size", len(origCbor),
+			"encoded_size", len(newCbor),
+		)
+	}
+}
+
 func (ls *LedgerState) updateTipMetrics() {
 	// Update metrics
 	ls.metrics.blockNum.Set(float64(ls.currentTip.BlockNumber))
@@ -763,17 +1069,60 @@ func (ls *LedgerState) GetBlock(point ocommon.Point) (*database.Block, error) {
 // RecentChainPoints returns the requested count of recent chain points in descending order. This is used mostly
 // for building a set of intersect points when acting as a chainsync client
 func (ls *LedgerState) RecentChainPoints(count int) ([]ocommon.Point, error) {
-	tmpBlocks, err := database.BlocksRecent(ls.db, count)
+	ret := []ocommon.Point{}
+	if count <= 0 {
+		return ret, nil
+	}
+	blockStore := ls.config.ChainManager.BlockStore()
+	tmpBlock, err := blockStore.Tip(nil)
 	if err != nil {
+		if errors.Is(err, database.ErrBlockNotFound) {
+			return ret, nil
+		}
 		return nil, err
 	}
-	ret := []ocommon.Point{}
-	var tmpBlock database.Block
-	for _, tmpBlock = range tmpBlocks {
+	for {
 		ret = append(
 			ret,
 			ocommon.NewPoint(tmpBlock.Slot, tmpBlock.Hash),
 		)
+		if len(ret) >= count || tmpBlock.ID <= database.BlockInitialIndex {
+			break
+		}
+		tmpBlock, err = blockStore.GetByIndex(tmpBlock.ID-1, nil)
+		if err != nil {
+			if errors.Is(err, database.ErrBlockNotFound) {
+				break
+			}
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// SyncCursorPoints returns the persisted chainsync cursor points in descending order. These are recorded independently of
+// the ledger and can be used to resume chainsync when no recent chain points are available. Points that are no longer
+// in the block store are skipped, since we can't extend the chain from them
+func (ls *LedgerState) SyncCursorPoints() ([]ocommon.Point, error) {
+	cursorPoints, err := ls.db.GetSyncCursor(nil)
+	if err != nil {
+		return nil, err
+	}
+	blockStore := ls.config.ChainManager.BlockStore()
+	ret := make([]ocommon.Point, 0, len(cursorPoints))
+	for _, point := range cursorPoints {
+		if _, err := blockStore.Get(point, nil); err != nil {
+			if errors.Is(err, database.ErrBlockNotFound) {
+				ls.config.Logger.Debug(
+					"skipping sync cursor point missing from block store",
+					"component", "ledger",
+					"slot", point.Slot,
+				)
+				continue
+			}
+			return nil, err
+		}
+		ret = append(ret, point)
 	}
 	return ret, nil
 }
@@ -782,6 +1131,13 @@ func (ls *LedgerState) RecentChainPoints(count int) ([]ocommon.Point, error) {
 func (ls *LedgerState) GetIntersectPoint(
 	points []ocommon.Point,
 ) (*ocommon.Point, error) {
+	// Check for a recently resolved intersect for the same points
+	cacheKey := intersectCacheKey(points)
+	if cachedPoint, ok := ls.intersectCache.get(cacheKey); ok {
+		return &cachedPoint, nil
+	}
+	// Record the cache generation before the lookup, so that we don't cache a result that was rolled back during it
+	cacheGeneration := ls.intersectCache.currentGeneration()
 	tip := ls.Tip()
 	var ret ocommon.Point
 	var tmpBlock database.Block
@@ -821,6 +1177,7 @@ func (ls *LedgerState) GetIntersectPoint(
 		return nil, err
 	}
 	if ret.Slot > 0 || foundOrigin {
+		ls.intersectCache.add(cacheKey, ret, cacheGeneration)
 		return &ret, nil
 	}
 	return nil, nil
@@ -840,11 +1197,36 @@ func (ls *LedgerState) Tip() ochainsync.Tip {
 	return ls.currentTip
 }
 
+// CurrentEpoch returns the current epoch
+func (ls *LedgerState) CurrentEpoch() database.Epoch {
+	return ls.currentEpoch
+}
+
 // GetCurrentPParams returns the currentPParams value
 func (ls *LedgerState) GetCurrentPParams() lcommon.ProtocolParameters {
 	return ls.currentPParams
 }
 
+// CostModels returns the Plutus cost models from the current protocol parameters, keyed by Plutus language version
+func (ls *LedgerState) CostModels() (map[string][]int64, error) {
+	var costModels map[uint][]int64
+	switch pparams := ls.currentPParams.(type) {
+	case *alonzo.AlonzoProtocolParameters:
+		costModels = pparams.CostModels
+	case *babbage.BabbageProtocolParameters:
+		costModels = pparams.CostModels
+	case *conway.ConwayProtocolParameters:
+		costModels = pparams.CostModels
+	default:
+		return nil, ErrCostModelsNotAvailable
+	}
+	ret := make(map[string][]int64, len(costModels))
+	for version, costModel := range costModels {
+		ret[fmt.Sprintf("PlutusV%d", version+1)] = slices.Clone(costModel)
+	}
+	return ret, nil
+}
+
 // UtxoByRef returns a single UTxO by reference
 func (ls *LedgerState) UtxoByRef(
 	txId []byte,
@@ -0,0 +1,68 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ledger
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/dingo/database"
+	ocommon "github.com/blinklabs-io/gouroboros/protocol/common"
+)
+
+func TestSyncCursorPointsSkipsMissingBlocks(t *testing.T) {
+	db, err := database.New(nil, nil, "", 0)
+	if err != nil {
+		t.Fatalf("unexpected error creating database: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	testBlock := database.Block{
+		ID:   database.BlockInitialIndex,
+		Slot: 10,
+		Hash: bytes.Repeat([]byte{0x01}, 32),
+		Cbor: []byte{0xa1},
+	}
+	if err := db.BlockCreate(testBlock, nil); err != nil {
+		t.Fatalf("unexpected error adding block: %s", err)
+	}
+	cm, err := chain.NewManager(db, nil)
+	if err != nil {
+		t.Fatalf("unexpected error creating chain manager: %s", err)
+	}
+	ls := &LedgerState{
+		config: LedgerStateConfig{
+			Logger:       slog.New(slog.NewJSONHandler(io.Discard, nil)),
+			ChainManager: cm,
+		},
+		db: db,
+	}
+	knownPoint := ocommon.NewPoint(testBlock.Slot, testBlock.Hash)
+	missingPoint := ocommon.NewPoint(20, bytes.Repeat([]byte{0x02}, 32))
+	for _, point := range []ocommon.Point{knownPoint, missingPoint} {
+		if err := db.AddSyncCursorPoint(point, nil); err != nil {
+			t.Fatalf("unexpected error adding sync cursor point: %s", err)
+		}
+	}
+	points, err := ls.SyncCursorPoints()
+	if err != nil {
+		t.Fatalf("unexpected error getting sync cursor points: %s", err)
+	}
+	if len(points) != 1 || points[0].Slot != knownPoint.Slot {
+		t.Fatalf("did not get expected sync cursor points: %v", points)
+	}
+}
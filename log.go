@@ -0,0 +1,107 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+)
+
+// componentLogHandler is a slog.Handler wrapper that filters records by their "component" attribute against
+// per-component log levels. Records for components without a configured level are passed through to the wrapped
+// handler unchanged
+type componentLogHandler struct {
+	handler   slog.Handler
+	levels    map[string]slog.Level
+	minLevel  slog.Level
+	component string
+}
+
+func newComponentLogHandler(
+	handler slog.Handler,
+	levels map[string]slog.Level,
+) *componentLogHandler {
+	h := &componentLogHandler{
+		handler: handler,
+		levels:  levels,
+	}
+	first := true
+	for _, level := range levels {
+		if first || level < h.minLevel {
+			h.minLevel = level
+			first = false
+		}
+	}
+	return h
+}
+
+func (h *componentLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	// We don't know the component until the record is handled, so allow anything that may be enabled for some component
+	if level >= h.minLevel {
+		return true
+	}
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h *componentLogHandler) Handle(ctx context.Context, record slog.Record) error {
+	component := h.component
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == "component" {
+			component = attr.Value.String()
+			return false
+		}
+		return true
+	})
+	// The component level overrides the level of the wrapped handler
+	if level, ok := h.levels[component]; ok {
+		if record.Level < level {
+			return nil
+		}
+	} else if !h.handler.Enabled(ctx, record.Level) {
+		return nil
+	}
+	return h.handler.Handle(ctx, record)
+}
+
+func (h *componentLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	ret := *h
+	ret.handler = h.handler.WithAttrs(attrs)
+	for _, attr := range attrs {
+		if attr.Key == "component" {
+			ret.component = attr.Value.String()
+		}
+	}
+	return &ret
+}
+
+func (h *componentLogHandler) WithGroup(name string) slog.Handler {
+	ret := *h
+	ret.handler = h.handler.WithGroup(name)
+	return &ret
+}
+
+// configWrapLogger wraps the configured logger to apply any per-component log levels
+func (n *Node) configWrapLogger() {
+	if len(n.config.componentLogLevels) == 0 {
+		return
+	}
+	n.config.logger = slog.New(
+		newComponentLogHandler(
+			n.config.logger.Handler(),
+			maps.Clone(n.config.componentLogLevels),
+		),
+	)
+}
@@ -23,12 +23,15 @@ type MempoolConsumer struct {
 	nextTxIdx  int
 	cache      map[string]*MempoolTransaction
 	cacheMutex sync.Mutex
+	// TXs already returned when prioritizing by fee. This is only modified with the mempool lock held
+	announced map[string]struct{}
 }
 
 func newConsumer(mempool *Mempool) *MempoolConsumer {
 	return &MempoolConsumer{
-		mempool: mempool,
-		cache:   make(map[string]*MempoolTransaction),
+		mempool:   mempool,
+		cache:     make(map[string]*MempoolTransaction),
+		announced: make(map[string]struct{}),
 	}
 }
 
@@ -36,6 +39,9 @@ func (m *MempoolConsumer) NextTx(blocking bool) *MempoolTransaction {
 	if m == nil {
 		return nil
 	}
+	if m.mempool.config.PrioritizeByFee {
+		return m.nextPriorityTx(blocking)
+	}
 	m.mempool.RLock()
 	defer m.mempool.RUnlock()
 	if m.nextTxIdx >= len(m.mempool.transactions) {
@@ -96,3 +102,46 @@ func (m *MempoolConsumer) RemoveTxFromCache(hash string) {
 		delete(m.cache, hash)
 	}
 }
+
+// nextPriorityTx returns the not yet announced TX with the highest fee per byte
+func (m *MempoolConsumer) nextPriorityTx(blocking bool) *MempoolTransaction {
+	m.mempool.RLock()
+	defer m.mempool.RUnlock()
+	nextTx := m.highestFeeRateTx()
+	if nextTx == nil {
+		if !blocking {
+			return nil
+		}
+		// Wait for TX to be added to mempool
+		addTxSubId, addTxChan := m.mempool.eventBus.Subscribe(
+			AddTransactionEventType,
+		)
+		m.mempool.RUnlock()
+		<-addTxChan
+		m.mempool.eventBus.Unsubscribe(AddTransactionEventType, addTxSubId)
+		m.mempool.RLock()
+		nextTx = m.highestFeeRateTx()
+		if nextTx == nil {
+			return nil
+		}
+	}
+	m.announced[nextTx.Hash] = struct{}{}
+	// Add transaction to cache
+	m.cacheMutex.Lock()
+	m.cache[nextTx.Hash] = nextTx
+	m.cacheMutex.Unlock()
+	return nextTx
+}
+
+func (m *MempoolConsumer) highestFeeRateTx() *MempoolTransaction {
+	var ret *MempoolTransaction
+	for _, tx := range m.mempool.transactions {
+		if _, ok := m.announced[tx.Hash]; ok {
+			continue
+		}
+		if ret == nil || tx.feeRateGreater(ret) {
+			ret = tx
+		}
+	}
+	return ret
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/dingo/database"
 	"github.com/blinklabs-io/dingo/event"
 	"github.com/blinklabs-io/dingo/ledger"
 	ouroboros "github.com/blinklabs-io/gouroboros"
@@ -38,57 +39,89 @@ const (
 type AddTransactionEvent struct {
 	Hash string
 	Body []byte
-	Type uint
+	Type uint // Transaction type, which corresponds to the era ID
+	Size int  // Size of the transaction CBOR in bytes
 }
 
 type RemoveTransactionEvent struct {
-	Hash string
+	Hash   string
+	Reason RemoveReason
 }
 
+// RemoveReason describes why a transaction left the mempool
+type RemoveReason string
+
+const (
+	ReasonConfirmed RemoveReason = "confirmed" // Included in an applied block
+	ReasonEvicted   RemoveReason = "evicted"   // Removed to make room or by request
+	ReasonExpired   RemoveReason = "expired"   // Validity interval has passed
+	ReasonInvalid   RemoveReason = "invalid"   // Failed decoding or re-validation against the current ledger state
+)
+
 type MempoolTransaction struct {
 	Hash     string
 	Type     uint
 	Cbor     []byte
+	Fee      uint64
 	LastSeen time.Time
 }
 
+// feeRateGreater returns whether the transaction has a higher fee per byte than the other transaction
+func (t *MempoolTransaction) feeRateGreater(other *MempoolTransaction) bool {
+	// Cross-multiply to avoid floating point math
+	return t.Fee*uint64(len(other.Cbor)) > other.Fee*uint64(len(t.Cbor))
+}
+
+type MempoolConfig struct {
+	Logger       *slog.Logger
+	EventBus     *event.EventBus
+	PromRegistry prometheus.Registerer
+	LedgerState  *ledger.LedgerState
+	// PrioritizeByFee causes transactions to be announced to peers in order of fee per byte, highest first,
+	// rather than the order they were added. The fee is taken from the decoded transaction body, which
+	// provides it for all supported eras. Byron transactions don't carry an explicit fee and will sort last
+	PrioritizeByFee bool
+}
+
 type Mempool struct {
 	sync.RWMutex
+	config         MempoolConfig
 	logger         *slog.Logger
 	eventBus       *event.EventBus
 	ledgerState    *ledger.LedgerState
 	consumers      map[ouroboros.ConnectionId]*MempoolConsumer
 	consumersMutex sync.Mutex
 	transactions   []*MempoolTransaction
+	pendingEvents  []event.Event // events to publish once the mempool lock is released
 	metrics        struct {
 		txsProcessedNum prometheus.Counter
 		txsInMempool    prometheus.Gauge
 		mempoolBytes    prometheus.Gauge
+		txsAdded        prometheus.Counter
+		txsRemoved      *prometheus.CounterVec
+		oldestTxAge     prometheus.GaugeFunc
+		collectors      []prometheus.Collector
 	}
 }
 
-func NewMempool(
-	logger *slog.Logger,
-	eventBus *event.EventBus,
-	promRegistry prometheus.Registerer,
-	ledgerState *ledger.LedgerState,
-) *Mempool {
+func NewMempool(cfg MempoolConfig) *Mempool {
 	m := &Mempool{
-		eventBus:    eventBus,
+		config:      cfg,
+		eventBus:    cfg.EventBus,
 		consumers:   make(map[ouroboros.ConnectionId]*MempoolConsumer),
-		ledgerState: ledgerState,
+		ledgerState: cfg.LedgerState,
 	}
-	if logger == nil {
+	if cfg.Logger == nil {
 		// Create logger to throw away logs
 		// We do this so we don't have to add guards around every log operation
 		m.logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
 	} else {
-		m.logger = logger
+		m.logger = cfg.Logger
 	}
 	// Subscribe to chain update events
 	go m.processChainEvents()
 	// Init metrics
-	promautoFactory := promauto.With(promRegistry)
+	promautoFactory := promauto.With(cfg.PromRegistry)
 	m.metrics.txsProcessedNum = promautoFactory.NewCounter(
 		prometheus.CounterOpts{
 			Name: "cardano_node_metrics_txsProcessedNum_int",
@@ -103,9 +136,61 @@ func NewMempool(
 		Name: "cardano_node_metrics_mempoolBytes_int",
 		Help: "current size of mempool transactions in bytes",
 	})
+	m.metrics.txsAdded = promautoFactory.NewCounter(prometheus.CounterOpts{
+		Name: "mempool_txs_added_total",
+		Help: "total transactions added to the mempool",
+	})
+	m.metrics.txsRemoved = promautoFactory.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "mempool_txs_removed_total",
+			Help: "total transactions removed from the mempool, by reason (confirmed, evicted, expired, invalid)",
+		},
+		[]string{"reason"},
+	)
+	m.metrics.oldestTxAge = promautoFactory.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "mempool_oldest_tx_age_seconds",
+			Help: "time since the least recently seen mempool transaction was last seen",
+		},
+		m.oldestTxAge,
+	)
+	m.metrics.collectors = []prometheus.Collector{
+		m.metrics.txsProcessedNum,
+		m.metrics.txsInMempool,
+		m.metrics.mempoolBytes,
+		m.metrics.txsAdded,
+		m.metrics.txsRemoved,
+		m.metrics.oldestTxAge,
+	}
 	return m
 }
 
+// Close unregisters the mempool metrics, which allows creating a new mempool against the same registry
+func (m *Mempool) Close() {
+	if m.config.PromRegistry == nil {
+		return
+	}
+	for _, collector := range m.metrics.collectors {
+		m.config.PromRegistry.Unregister(collector)
+	}
+}
+
+// oldestTxAge returns the number of seconds since the least recently seen transaction was last seen
+func (m *Mempool) oldestTxAge() float64 {
+	m.RLock()
+	defer m.RUnlock()
+	var oldest time.Time
+	for _, tx := range m.transactions {
+		if oldest.IsZero() || tx.LastSeen.Before(oldest) {
+			oldest = tx.LastSeen
+		}
+	}
+	if oldest.IsZero() {
+		return 0
+	}
+	return time.Since(oldest).Seconds()
+}
+
 func (m *Mempool) AddConsumer(connId ouroboros.ConnectionId) *MempoolConsumer {
 	// Create consumer
 	m.consumersMutex.Lock()
@@ -135,13 +220,20 @@ func (m *Mempool) processChainEvents() {
 		m.eventBus.Unsubscribe(chain.ChainUpdateEventType, chainUpdateSubId)
 	}()
 	lastValidationTime := time.Now()
+	var evt event.Event
 	var ok bool
 	for {
 		// Wait for chain event
-		_, ok = <-chainUpdateChan
+		evt, ok = <-chainUpdateChan
 		if !ok {
 			return
 		}
+		switch e := evt.Data.(type) {
+		case chain.ChainBlockEvent:
+			m.removeBlockTransactions(e.Block)
+		case chain.ChainRollbackEvent:
+			m.restoreBlockTransactions(e.RolledBackBlocks)
+		}
 		// Only purge once every 30 seconds when there are more blocks available
 		if time.Since(lastValidationTime) < 30*time.Second &&
 			len(chainUpdateChan) > 0 {
@@ -155,7 +247,7 @@ func (m *Mempool) processChainEvents() {
 			// Decode transaction
 			tmpTx, err := gledger.NewTransactionFromCbor(tx.Type, tx.Cbor)
 			if err != nil {
-				m.removeTransactionByIndex(i)
+				m.removeTransactionByIndex(i, ReasonInvalid)
 				m.logger.Error(
 					"removed transaction after decode failure",
 					"component", "mempool",
@@ -166,7 +258,7 @@ func (m *Mempool) processChainEvents() {
 			}
 			// Validate transaction
 			if err := m.ledgerState.ValidateTx(tmpTx); err != nil {
-				m.removeTransactionByIndex(i)
+				m.removeTransactionByIndex(i, ReasonInvalid)
 				m.logger.Debug(
 					"removed transaction after re-validation failure",
 					"component", "mempool",
@@ -175,7 +267,77 @@ func (m *Mempool) processChainEvents() {
 				)
 			}
 		}
-		m.Unlock()
+		m.unlockAndPublish()
+	}
+}
+
+// removeBlockTransactions removes any transactions included in the specified block from the mempool
+func (m *Mempool) removeBlockTransactions(block database.Block) {
+	m.RLock()
+	mempoolEmpty := len(m.transactions) == 0
+	m.RUnlock()
+	if mempoolEmpty {
+		return
+	}
+	tmpBlock, err := block.Decode()
+	if err != nil {
+		m.logger.Error(
+			"failed to decode block",
+			"component", "mempool",
+			"error", err,
+		)
+		return
+	}
+	m.Lock()
+	defer m.unlockAndPublish()
+	for _, tx := range tmpBlock.Transactions() {
+		txHash := tx.Hash().String()
+		if m.removeTransaction(txHash, ReasonConfirmed) {
+			m.logger.Debug(
+				"removed transaction included in block",
+				"component", "mempool",
+				"tx_hash", txHash,
+			)
+		}
+	}
+}
+
+// restoreBlockTransactions adds transactions from rolled-back blocks back to the mempool so that they can be
+// resubmitted. The ledger won't have processed the rollback yet, so the transactions are not validated here.
+// Any that are no longer valid will be removed by the re-validation on the next chain update
+func (m *Mempool) restoreBlockTransactions(blocks []database.Block) {
+	m.Lock()
+	m.consumersMutex.Lock()
+	defer func() {
+		m.consumersMutex.Unlock()
+		m.unlockAndPublish()
+	}()
+	// Blocks are provided newest first
+	for i := len(blocks) - 1; i >= 0; i-- {
+		tmpBlock, err := blocks[i].Decode()
+		if err != nil {
+			m.logger.Error(
+				"failed to decode rolled-back block",
+				"component", "mempool",
+				"error", err,
+			)
+			continue
+		}
+		for _, tx := range tmpBlock.Transactions() {
+			// Skip transactions that failed phase-2 validation
+			if !tx.IsValid() {
+				continue
+			}
+			m.addTransaction(
+				MempoolTransaction{
+					Hash:     tx.Hash().String(),
+					Type:     uint(tx.Type()), // #nosec G115
+					Cbor:     tx.Cbor(),
+					Fee:      tx.Fee(),
+					LastSeen: time.Now(),
+				},
+			)
+		}
 	}
 }
 
@@ -195,24 +357,30 @@ func (m *Mempool) AddTransaction(txType uint, txBytes []byte) error {
 		Hash:     txHash,
 		Type:     txType,
 		Cbor:     txBytes,
+		Fee:      tmpTx.Fee(),
 		LastSeen: time.Now(),
 	}
 	m.Lock()
 	m.consumersMutex.Lock()
 	defer func() {
 		m.consumersMutex.Unlock()
-		m.Unlock()
+		m.unlockAndPublish()
 	}()
+	m.addTransaction(tx)
+	return nil
+}
+
+func (m *Mempool) addTransaction(tx MempoolTransaction) {
 	// Update last seen for existing TX
 	existingTx := m.getTransaction(tx.Hash)
 	if existingTx != nil {
-		tx.LastSeen = time.Now()
+		existingTx.LastSeen = time.Now()
 		m.logger.Debug(
 			"updated last seen for transaction",
 			"component", "mempool",
 			"tx_hash", tx.Hash,
 		)
-		return nil
+		return
 	}
 	// Add transaction record
 	m.transactions = append(m.transactions, &tx)
@@ -222,21 +390,22 @@ func (m *Mempool) AddTransaction(txType uint, txBytes []byte) error {
 		"tx_hash", tx.Hash,
 	)
 	m.metrics.txsProcessedNum.Inc()
+	m.metrics.txsAdded.Inc()
 	m.metrics.txsInMempool.Inc()
 	m.metrics.mempoolBytes.Add(float64(len(tx.Cbor)))
 	// Generate event
-	m.eventBus.Publish(
-		AddTransactionEventType,
+	m.pendingEvents = append(
+		m.pendingEvents,
 		event.NewEvent(
 			AddTransactionEventType,
 			AddTransactionEvent{
 				Hash: tx.Hash,
 				Type: tx.Type,
 				Body: tx.Cbor,
+				Size: len(tx.Cbor),
 			},
 		),
 	)
-	return nil
 }
 
 func (m *Mempool) GetTransaction(txHash string) (MempoolTransaction, bool) {
@@ -268,28 +437,32 @@ func (m *Mempool) getTransaction(txHash string) *MempoolTransaction {
 	return nil
 }
 
-func (m *Mempool) RemoveTransaction(txHash string) {
+func (m *Mempool) RemoveTransaction(txHash string, reason RemoveReason) {
 	m.Lock()
-	defer m.Unlock()
-	if m.removeTransaction(txHash) {
+	defer m.unlockAndPublish()
+	if m.removeTransaction(txHash, reason) {
 		m.logger.Debug(
 			"removed transaction",
 			"component", "mempool",
 			"tx_hash", txHash,
+			"reason", string(reason),
 		)
 	}
 }
 
-func (m *Mempool) removeTransaction(txHash string) bool {
+func (m *Mempool) removeTransaction(txHash string, reason RemoveReason) bool {
 	for txIdx, tx := range m.transactions {
 		if tx.Hash == txHash {
-			return m.removeTransactionByIndex(txIdx)
+			return m.removeTransactionByIndex(txIdx, reason)
 		}
 	}
 	return false
 }
 
-func (m *Mempool) removeTransactionByIndex(txIdx int) bool {
+func (m *Mempool) removeTransactionByIndex(
+	txIdx int,
+	reason RemoveReason,
+) bool {
 	if txIdx >= len(m.transactions) {
 		return false
 	}
@@ -301,22 +474,36 @@ func (m *Mempool) removeTransactionByIndex(txIdx int) bool {
 	)
 	m.metrics.txsInMempool.Dec()
 	m.metrics.mempoolBytes.Sub(float64(len(tx.Cbor)))
+	m.metrics.txsRemoved.WithLabelValues(string(reason)).Inc()
 	// Update consumer indexes to reflect removed TX
 	for _, consumer := range m.consumers {
 		// Decrement consumer index if the consumer has reached the removed TX
 		if consumer.nextTxIdx > txIdx {
 			consumer.nextTxIdx--
 		}
+		delete(consumer.announced, tx.Hash)
 	}
 	// Generate event
-	m.eventBus.Publish(
-		RemoveTransactionEventType,
+	m.pendingEvents = append(
+		m.pendingEvents,
 		event.NewEvent(
 			RemoveTransactionEventType,
 			RemoveTransactionEvent{
-				Hash: tx.Hash,
+				Hash:   tx.Hash,
+				Reason: reason,
 			},
 		),
 	)
 	return true
 }
+
+// unlockAndPublish releases the mempool lock and then publishes any events generated while it was held.
+// Publishing outside the lock prevents subscribers that call back into the mempool from deadlocking
+func (m *Mempool) unlockAndPublish() {
+	pendingEvents := m.pendingEvents
+	m.pendingEvents = nil
+	m.Unlock()
+	for _, evt := range pendingEvents {
+		m.eventBus.Publish(evt.Type, evt)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	metricsReadHeaderTimeout = 10 * time.Second
+	metricsShutdownTimeout   = 5 * time.Second
+)
+
+// Valid metric namespaces, based on the prometheus metric name format
+var metricsNamespaceRegexp = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+
+type nodeMetrics struct {
+	chainsyncLagSlots prometheus.Gauge
+}
+
+// configWrapPromRegistry applies the configured namespace, if any, to all metrics registered by the node and its
+// subsystems. The original registry is kept for gathering metrics
+func (n *Node) configWrapPromRegistry() {
+	if n.config.promRegistry == nil {
+		return
+	}
+	if gatherer, ok := n.config.promRegistry.(prometheus.Gatherer); ok {
+		n.config.promGatherer = gatherer
+	}
+	if n.config.metricsNamespace == "" {
+		return
+	}
+	n.config.promRegistry = prometheus.WrapRegistererWithPrefix(
+		n.config.metricsNamespace+"_",
+		n.config.promRegistry,
+	)
+}
+
+func (n *Node) initMetrics(promRegistry prometheus.Registerer) {
+	promautoFactory := promauto.With(promRegistry)
+	n.metrics = &nodeMetrics{}
+	n.metrics.chainsyncLagSlots = promautoFactory.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "chainsync_lag_slots",
+			Help: "number of slots the local chain is behind the upstream peer tip",
+		},
+	)
+}
+
+// updateChainsyncLag records the difference between the upstream tip slot and the specified local slot
+func (n *Node) updateChainsyncLag(tipSlot uint64, localSlot uint64) {
+	if n.metrics == nil {
+		return
+	}
+	// Guard against underflow if we are somehow ahead of the upstream tip
+	var lag uint64
+	if tipSlot > localSlot {
+		lag = tipSlot - localSlot
+	}
+	n.metrics.chainsyncLagSlots.Set(float64(lag))
+}
+
+// startMetricsServer starts an HTTP server serving the configured prometheus registry at /metrics, if configured. The
+// server is stopped gracefully on node shutdown. This only gathers from the registry, so it's safe to use alongside
+// another metrics server for the same registry
+func (n *Node) startMetricsServer() error {
+	if n.config.metricsListenAddress == "" {
+		return nil
+	}
+	if n.config.promGatherer == nil {
+		return errors.New(
+			"metrics listener requires a prometheus registry that is also a prometheus.Gatherer",
+		)
+	}
+	mux := http.NewServeMux()
+	mux.Handle(
+		"GET /metrics",
+		promhttp.HandlerFor(n.config.promGatherer, promhttp.HandlerOpts{}),
+	)
+	server := &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: metricsReadHeaderTimeout,
+	}
+	listener, err := net.Listen("tcp", n.config.metricsListenAddress)
+	if err != nil {
+		return fmt.Errorf("failed to start metrics listener: %w", err)
+	}
+	n.config.logger.Info(
+		"serving prometheus metrics on "+listener.Addr().String(),
+		"component", "node",
+	)
+	go func() {
+		if err := server.Serve(listener); err != nil &&
+			!errors.Is(err, http.ErrServerClosed) {
+			n.config.logger.Error(
+				fmt.Sprintf("metrics server failed: %s", err),
+				"component", "node",
+			)
+		}
+	}()
+	n.shutdownFuncs = append(
+		n.shutdownFuncs,
+		func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, metricsShutdownTimeout)
+			defer cancel()
+			return server.Shutdown(ctx)
+		},
+	)
+	return nil
+}
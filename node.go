@@ -18,20 +18,26 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
 	"slices"
+	"sync"
+	"sync/atomic"
 
 	"github.com/blinklabs-io/dingo/chain"
 	"github.com/blinklabs-io/dingo/chainsync"
 	"github.com/blinklabs-io/dingo/connmanager"
 	"github.com/blinklabs-io/dingo/database"
+	"github.com/blinklabs-io/dingo/database/immutable"
 	"github.com/blinklabs-io/dingo/event"
 	"github.com/blinklabs-io/dingo/ledger"
 	"github.com/blinklabs-io/dingo/mempool"
 	"github.com/blinklabs-io/dingo/peergov"
+	"github.com/blinklabs-io/dingo/topology"
 	"github.com/blinklabs-io/dingo/utxorpc"
 	ouroboros "github.com/blinklabs-io/gouroboros"
 	oblockfetch "github.com/blinklabs-io/gouroboros/protocol/blockfetch"
 	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+	okeepalive "github.com/blinklabs-io/gouroboros/protocol/keepalive"
 	olocalstatequery "github.com/blinklabs-io/gouroboros/protocol/localstatequery"
 	olocaltxmonitor "github.com/blinklabs-io/gouroboros/protocol/localtxmonitor"
 	olocaltxsubmission "github.com/blinklabs-io/gouroboros/protocol/localtxsubmission"
@@ -39,6 +45,9 @@ import (
 	otxsubmission "github.com/blinklabs-io/gouroboros/protocol/txsubmission"
 )
 
+// ErrNodeNotRunning is returned when an operation requires the node to be running
+var ErrNodeNotRunning = errors.New("node is not running")
+
 type Node struct {
 	config         Config
 	connManager    *connmanager.ConnectionManager
@@ -48,23 +57,51 @@ type Node struct {
 	mempool        *mempool.Mempool
 	chainManager   *chain.ChainManager
 	db             *database.Database
+	immutableDb    *immutable.ImmutableDb
 	ledgerState    *ledger.LedgerState
 	utxorpc        *utxorpc.Utxorpc
-	shutdownFuncs  []func(context.Context) error
+	metrics        *nodeMetrics
+	// Per-peer blockfetch stats used for peer selection
+	blockfetchPeers *blockfetchPeers
+	// Highest slot that block bodies have been pruned before. This is persisted in the metadata DB
+	blockPrunedSlot atomic.Uint64
+	blockPruneMutex sync.Mutex
+	shutdownFuncs   []func(context.Context) error
+	// Chainsync client pause state
+	chainsyncPauseMutex sync.Mutex
+	chainsyncPauseChan  chan struct{}
+	// Connections with an outstanding peer-sharing request
+	peersharingMutex    sync.Mutex
+	peersharingInflight map[ouroboros.ConnectionId]struct{}
 }
 
 func New(cfg Config) (*Node, error) {
-	eventBus := event.NewEventBus(cfg.promRegistry)
 	n := &Node{
-		config:   cfg,
-		eventBus: eventBus,
+		config:          cfg,
+		blockfetchPeers: newBlockfetchPeers(cfg.blockfetchStrategy),
 	}
+	n.configWrapLogger()
 	if err := n.configPopulateNetworkMagic(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
+	if err := n.configLoadGenesisFiles(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	if err := n.configPopulateListeners(); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
+	}
 	if err := n.configValidate(); err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
+	n.configWrapPromRegistry()
+	n.eventBus = event.NewEventBus(n.config.promRegistry)
+	n.eventBus.SetLogger(n.config.logger)
+	// Late subscribers to these events need the current era and epoch
+	n.eventBus.SetSticky(ledger.EraTransitionEventType)
+	n.eventBus.SetSticky(ledger.EpochTransitionEventType)
+	if n.config.promRegistry != nil {
+		n.initMetrics(n.config.promRegistry)
+	}
 	return n, nil
 }
 
@@ -78,6 +115,22 @@ func (n *Node) Run() error {
 	// Load database
 	dbNeedsRecovery := false
 	db, err := database.New(n.config.logger, n.config.promRegistry, n.config.dataDir, n.config.badgerCacheSize)
+	// Rebuild the metadata store from the chain if it couldn't be recovered in place
+	var metadataErr database.MetadataInitError
+	if errors.As(err, &metadataErr) {
+		if !n.config.metadataAutoRecover {
+			return fmt.Errorf("failed to open database (metadata auto-recovery is disabled): %w", err)
+		}
+		n.config.logger.Warn(
+			"rebuilding metadata store from chain store",
+			"error",
+			err,
+		)
+		if err := database.ResetMetadata(n.config.dataDir, n.config.logger); err != nil {
+			return fmt.Errorf("failed to reset metadata store: %w", err)
+		}
+		db, err = database.New(n.config.logger, n.config.promRegistry, n.config.dataDir, n.config.badgerCacheSize)
+	}
 	if db == nil {
 		n.config.logger.Error(
 			"failed to create database",
@@ -99,25 +152,60 @@ func (n *Node) Run() error {
 		)
 		dbNeedsRecovery = true
 	}
+	if n.config.metadataReadReplica {
+		if err := n.db.Metadata().EnableReadReplica(); err != nil {
+			return fmt.Errorf("failed to open database: %w", err)
+		}
+	}
+	// Open ImmutableDB for serving immutable blocks, if configured
+	if n.config.immutableDbPath != "" {
+		immutableDb, err := immutable.New(n.config.immutableDbPath)
+		if err != nil {
+			return fmt.Errorf("failed to open immutable DB: %w", err)
+		}
+		n.immutableDb = immutableDb
+	}
 	// Load chain manager
-	cm, err := chain.NewManager(
+	blockStore := n.config.blockStore
+	if blockStore == nil {
+		blockStore = chain.NewDatabaseBlockStore(n.db)
+	}
+	cm, err := chain.NewManagerWithBlockStore(
 		n.db,
+		blockStore,
 		n.eventBus,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to load chain manager: %w", err)
 	}
 	n.chainManager = cm
+	// Restore the block prune boundary from the last pruning run
+	blockPrunedSlot, err := n.db.GetBlockPrunedSlot(nil)
+	if err != nil {
+		return fmt.Errorf("failed to load block prune boundary: %w", err)
+	}
+	n.blockPrunedSlot.Store(blockPrunedSlot)
+	// Use the security parameter (k) for the immutable chain boundary and rollback protection
+	n.chainManager.SetSecurityParam(uint64(n.SecurityParam()))
 	// Load state
 	state, err := ledger.NewLedgerState(
 		ledger.LedgerStateConfig{
-			ChainManager:               n.chainManager,
-			Database:                   n.db,
-			EventBus:                   n.eventBus,
-			Logger:                     n.config.logger,
-			CardanoNodeConfig:          n.config.cardanoNodeConfig,
-			PromRegistry:               n.config.promRegistry,
-			BlockfetchRequestRangeFunc: n.blockfetchClientRequestRange,
+			ChainManager:                n.chainManager,
+			Database:                    n.db,
+			EventBus:                    n.eventBus,
+			Logger:                      n.config.logger,
+			CardanoNodeConfig:           n.config.cardanoNodeConfig,
+			PromRegistry:                n.config.promRegistry,
+			BlockfetchBatchSize:         n.blockfetchBatchSize(),
+			BlockfetchMaxInflightBytes:  n.config.blockfetchMaxBytes,
+			RewardCalculator:            n.config.rewardCalculator,
+			VerifyCborRoundtrip:         n.config.verifyCborRoundtrip,
+			BlockApplyBatchSize:         n.config.ledgerBatchSize,
+			BlockApplyFlushInterval:     n.config.ledgerFlushInterval,
+			BulkSync:                    n.config.bulkSync,
+			BulkSyncSlotThreshold:       n.config.bulkSyncThreshold,
+			BlockfetchRequestRangeFunc:  n.blockfetchClientRequestRange,
+			BlockfetchConnectionIdsFunc: n.blockfetchClientConnectionIds,
 		},
 	)
 	if err != nil {
@@ -131,6 +219,21 @@ func (n *Node) Run() error {
 		},
 	)
 	n.ledgerState = state
+	// Make sure the ledger and chain are in sync, since the stores could have been restored from different backups
+	if !dbNeedsRecovery {
+		if err := n.ledgerState.CheckTipConsistency(); err != nil {
+			var tipErr ledger.TipMismatchError
+			if !errors.As(err, &tipErr) {
+				return fmt.Errorf("failed to check database consistency: %w", err)
+			}
+			n.config.logger.Warn(
+				"database consistency check failed, needs recovery",
+				"error",
+				err,
+			)
+			dbNeedsRecovery = true
+		}
+	}
 	// Run DB recovery if needed
 	if dbNeedsRecovery {
 		if err := n.ledgerState.RecoverCommitTimestampConflict(); err != nil {
@@ -143,16 +246,27 @@ func (n *Node) Run() error {
 	}
 	// Initialize mempool
 	n.mempool = mempool.NewMempool(
-		n.config.logger,
-		n.eventBus,
-		n.config.promRegistry,
-		n.ledgerState,
+		mempool.MempoolConfig{
+			Logger:          n.config.logger,
+			EventBus:        n.eventBus,
+			PromRegistry:    n.config.promRegistry,
+			LedgerState:     n.ledgerState,
+			PrioritizeByFee: n.config.mempoolFeePriority,
+		},
 	)
 	// Initialize chainsync state
 	n.chainsyncState = chainsync.NewState(
 		n.eventBus,
 		n.ledgerState,
+		n.config.promRegistry,
 	)
+	n.chainsyncState.SetClientRateLimit(
+		n.config.chainsyncBlockRate,
+		n.config.chainsyncByteRate,
+	)
+	n.chainsyncState.SetMaxClients(n.config.chainsyncMaxClients)
+	n.chainsyncState.SetLogger(n.config.logger)
+	n.chainsyncState.SetRetentionStartSlotFunc(n.blockRetentionStartSlot)
 	// Configure connection manager
 	if err := n.configureConnManager(); err != nil {
 		return err
@@ -160,9 +274,12 @@ func (n *Node) Run() error {
 	// Configure peer governor
 	n.peerGov = peergov.NewPeerGovernor(
 		peergov.PeerGovernorConfig{
-			Logger:      n.config.logger,
-			EventBus:    n.eventBus,
-			ConnManager: n.connManager,
+			Logger:               n.config.logger,
+			EventBus:             n.eventBus,
+			ConnManager:          n.connManager,
+			ConnEventSink:        n.config.connEventSink,
+			MaxReconnectAttempts: n.config.maxReconnectAttempts,
+			TargetOutboundPeers:  n.config.targetOutboundPeers,
 		},
 	)
 	n.eventBus.SubscribeFunc(
@@ -175,6 +292,10 @@ func (n *Node) Run() error {
 	if err := n.peerGov.Start(); err != nil {
 		return err
 	}
+	// Start requesting peers from connected peers
+	if n.config.peerSharing {
+		n.peersharingStartRequests()
+	}
 	// Configure UTxO RPC
 	n.utxorpc = utxorpc.NewUtxorpc(
 		utxorpc.UtxorpcConfig{
@@ -188,11 +309,178 @@ func (n *Node) Run() error {
 	if err := n.utxorpc.Start(); err != nil {
 		return err
 	}
+	// Configure admin API
+	if err := n.startAdminApi(); err != nil {
+		return err
+	}
+	// Configure metrics listener
+	if err := n.startMetricsServer(); err != nil {
+		return err
+	}
+	// Start pruning of old block bodies
+	n.startBlockPruning()
 
 	// Wait forever
 	select {}
 }
 
+// OutboundPeers returns information about all peers with an active outbound connection
+func (n *Node) OutboundPeers() []PeerInfo {
+	if n.peerGov == nil {
+		return nil
+	}
+	return n.peerGov.OutboundPeers()
+}
+
+// InboundPeers returns information about all peers with an active inbound connection
+func (n *Node) InboundPeers() []PeerInfo {
+	if n.peerGov == nil {
+		return nil
+	}
+	return n.peerGov.InboundPeers()
+}
+
+// AddPeer adds an outbound peer with the specified address (host:port) and connects to it
+func (n *Node) AddPeer(address string) error {
+	if n.peerGov == nil {
+		return ErrNodeNotRunning
+	}
+	return n.peerGov.AddPeer(address)
+}
+
+// RemovePeer disconnects from the peer with the specified address and stops reconnecting to it
+func (n *Node) RemovePeer(address string) error {
+	if n.peerGov == nil {
+		return ErrNodeNotRunning
+	}
+	return n.peerGov.RemovePeer(address)
+}
+
+// QueryPeerVersions asks the peer at the specified address (host:port) which NtN protocol versions it supports
+// without establishing a full session. This is useful for troubleshooting topology issues
+func (n *Node) QueryPeerVersions(
+	address string,
+) ([]uint, NodeToNodeVersionData, error) {
+	if n.connManager == nil {
+		return nil, NodeToNodeVersionData{}, ErrNodeNotRunning
+	}
+	return n.connManager.QueryPeerVersions(address)
+}
+
+// WaitForPeers blocks until at least minPeers outbound connections are established or the context is cancelled. This is
+// useful as a readiness signal that the node has upstream connectivity
+func (n *Node) WaitForPeers(ctx context.Context, minPeers int) error {
+	if n.peerGov == nil {
+		return ErrNodeNotRunning
+	}
+	return n.peerGov.WaitForOutboundPeers(ctx, minPeers)
+}
+
+// ReloadTopology applies a new topology config without restarting the node. Peers added to the topology are
+// dialed, peers removed from it are disconnected, and unchanged peers keep their existing connections
+func (n *Node) ReloadTopology(topologyConfig *topology.TopologyConfig) error {
+	if topologyConfig == nil {
+		return errors.New("topology config must not be nil")
+	}
+	if n.peerGov == nil {
+		return ErrNodeNotRunning
+	}
+	n.peerGov.ReloadTopologyConfig(topologyConfig)
+	return nil
+}
+
+// Tip returns the current tip of the ledger
+func (n *Node) Tip() (ochainsync.Tip, error) {
+	if n.ledgerState == nil {
+		return ochainsync.Tip{}, ErrNodeNotRunning
+	}
+	n.ledgerState.RLock()
+	defer n.ledgerState.RUnlock()
+	return n.ledgerState.Tip(), nil
+}
+
+// SecurityParam returns the security parameter (k) from the genesis config, which is the max number of blocks that can be
+// rolled back. It determines the immutable chain boundary and the max rollback accepted from peers. It returns 0 if no
+// genesis config is available
+func (n *Node) SecurityParam() uint {
+	if n.config.cardanoNodeConfig == nil {
+		return 0
+	}
+	return n.config.cardanoNodeConfig.SecurityParam()
+}
+
+// CheckMetadataIntegrity runs an integrity check on the metadata database and returns the reported problems. An
+// empty list means that no problems were found
+func (n *Node) CheckMetadataIntegrity() ([]string, error) {
+	if n.db == nil {
+		return nil, ErrNodeNotRunning
+	}
+	return n.db.Metadata().IntegrityCheck()
+}
+
+// MempoolTransactions returns a snapshot of the transactions currently in the mempool
+func (n *Node) MempoolTransactions() []MempoolTransaction {
+	if n.mempool == nil {
+		return nil
+	}
+	return n.mempool.Transactions()
+}
+
+// CostModels returns the Plutus cost models from the current protocol parameters, keyed by Plutus language version
+// (PlutusV1, PlutusV2, etc.). This is useful for script execution budget estimation
+func (n *Node) CostModels() (map[string][]int64, error) {
+	if n.ledgerState == nil {
+		return nil, ErrNodeNotRunning
+	}
+	n.ledgerState.RLock()
+	defer n.ledgerState.RUnlock()
+	return n.ledgerState.CostModels()
+}
+
+// StakeDistribution returns the live stake delegated to each pool, keyed by bech32 pool ID. The result is computed from
+// delegation certificates and UTxO values in the background at each epoch boundary and cached for the rest of the
+// epoch, so it does not reflect changes made after it was computed. This blocks if it hasn't been computed yet
+func (n *Node) StakeDistribution() (map[string]uint64, error) {
+	if n.ledgerState == nil {
+		return nil, ErrNodeNotRunning
+	}
+	return n.ledgerState.StakeDistribution()
+}
+
+// SetMaxInboundConnections adjusts the maximum number of concurrent inbound connections at runtime. A value of 0 means
+// no limit
+func (n *Node) SetMaxInboundConnections(maxConns int) {
+	n.config.maxInboundConns = maxConns
+	if n.connManager != nil {
+		n.connManager.SetMaxInboundConnections(maxConns)
+	}
+}
+
+// SetInboundAccessLists replaces the CIDR allow and deny lists used for inbound node-to-node connections at runtime
+func (n *Node) SetInboundAccessLists(allow, deny []string) error {
+	if n.connManager != nil {
+		if err := n.connManager.SetInboundAccessLists(allow, deny); err != nil {
+			return err
+		}
+	}
+	n.config.inboundAllowList = allow
+	n.config.inboundDenyList = deny
+	return nil
+}
+
+// CloseConnection shuts down the specified connection. The resulting connection closed event will carry a
+// ClosedByOperatorError with the provided reason, and the peer is dropped from the peer governor rather than being
+// automatically reconnected
+func (n *Node) CloseConnection(
+	connId ouroboros.ConnectionId,
+	reason string,
+) error {
+	if n.connManager == nil {
+		return connmanager.ErrConnectionNotFound
+	}
+	return n.connManager.CloseConnection(connId, reason)
+}
+
 func (n *Node) Stop() error {
 	return n.shutdown()
 }
@@ -200,6 +488,20 @@ func (n *Node) Stop() error {
 func (n *Node) shutdown() error {
 	ctx := context.TODO()
 	var err error
+	// Close listeners
+	if n.connManager != nil {
+		err = errors.Join(err, n.connManager.Stop())
+	}
+	// Release any paused chainsync client callbacks
+	n.ResumeChainSync()
+	// Release chainsync clients waiting on new blocks
+	if n.chainsyncState != nil {
+		n.chainsyncState.Close()
+	}
+	// Release mempool metrics
+	if n.mempool != nil {
+		n.mempool.Close()
+	}
 	// Shutdown ledger
 	err = errors.Join(err, n.ledgerState.Close())
 	// Call shutdown functions
@@ -210,6 +512,39 @@ func (n *Node) shutdown() error {
 	return err
 }
 
+// localRootIPs returns the IP addresses of topology local roots. Hostnames are resolved on a best-effort basis
+func (n *Node) localRootIPs() []string {
+	if n.config.topologyConfig == nil {
+		return nil
+	}
+	var ret []string
+	for _, localRoot := range n.config.topologyConfig.LocalRoots {
+		for _, ap := range localRoot.AccessPoints {
+			if ip := net.ParseIP(ap.Address); ip != nil {
+				ret = append(ret, ip.String())
+				continue
+			}
+			addrs, err := net.LookupHost(ap.Address)
+			if err != nil {
+				n.config.logger.Warn(
+					fmt.Sprintf(
+						"failed to resolve local root address %s: %s",
+						ap.Address,
+						err,
+					),
+				)
+				continue
+			}
+			for _, addr := range addrs {
+				if ip := net.ParseIP(addr); ip != nil {
+					ret = append(ret, ip.String())
+				}
+			}
+		}
+	}
+	return ret
+}
+
 func (n *Node) configureConnManager() error {
 	// Configure listeners
 	tmpListeners := make([]ListenerConfig, len(n.config.listeners))
@@ -242,10 +577,14 @@ func (n *Node) configureConnManager() error {
 			)
 		} else {
 			// Node-to-node config
+			if n.config.proxyProtocol {
+				l.UseProxyProtocol = true
+			}
 			l.ConnectionOpts = append(
 				l.ConnectionOpts,
 				ouroboros.WithPeerSharing(n.config.peerSharing),
 				ouroboros.WithNetworkMagic(n.config.networkMagic),
+				ouroboros.WithKeepAliveConfig(n.keepAliveConfig()),
 				ouroboros.WithPeerSharingConfig(
 					opeersharing.NewConfig(
 						n.peersharingServerConnOpts()...,
@@ -273,14 +612,28 @@ func (n *Node) configureConnManager() error {
 	// Create connection manager
 	n.connManager = connmanager.NewConnectionManager(
 		connmanager.ConnectionManagerConfig{
-			Logger:             n.config.logger,
-			EventBus:           n.eventBus,
-			Listeners:          tmpListeners,
-			OutboundSourcePort: n.config.outboundSourcePort,
+			Logger:                       n.config.logger,
+			EventBus:                     n.eventBus,
+			ConnEventSink:                n.config.connEventSink,
+			Listeners:                    tmpListeners,
+			OutboundSourcePort:           n.config.outboundSourcePort,
+			OutboundSourcePortRangeStart: uint(n.config.outboundPortStart), // #nosec G115
+			OutboundSourcePortRangeEnd:   uint(n.config.outboundPortEnd),   // #nosec G115
+			OutboundAddressFamily:        n.config.outboundAddressFamily,
+			NetworkMagic:                 n.config.networkMagic,
+			PeerIdleTimeout:              n.config.peerIdleTimeout,
+			BandwidthLimit:               n.config.bandwidthLimit,
+			MaxInboundConns:              n.config.maxInboundConns,
+			MaxInboundConnsPerIP:         n.config.maxInboundConnsPerIP,
+			InboundIPLimitExempt:         n.localRootIPs(),
+			InboundAllowList:             n.config.inboundAllowList,
+			InboundDenyList:              n.config.inboundDenyList,
+			PromRegistry:                 n.config.promRegistry,
 			OutboundConnOpts: []ouroboros.ConnectionOptionFunc{
 				ouroboros.WithNetworkMagic(n.config.networkMagic),
 				ouroboros.WithNodeToNode(true),
 				ouroboros.WithKeepAlive(true),
+				ouroboros.WithKeepAliveConfig(n.keepAliveConfig()),
 				ouroboros.WithFullDuplex(true),
 				ouroboros.WithPeerSharing(n.config.peerSharing),
 				ouroboros.WithPeerSharingConfig(
@@ -330,6 +683,18 @@ func (n *Node) configureConnManager() error {
 	return nil
 }
 
+// keepAliveConfig returns the keep-alive protocol config for node-to-node connections
+func (n *Node) keepAliveConfig() okeepalive.Config {
+	var opts []okeepalive.KeepAliveOptionFunc
+	if n.config.keepAliveInterval > 0 {
+		opts = append(opts, okeepalive.WithPeriod(n.config.keepAliveInterval))
+	}
+	if n.config.keepAliveTimeout > 0 {
+		opts = append(opts, okeepalive.WithTimeout(n.config.keepAliveTimeout))
+	}
+	return okeepalive.NewConfig(opts...)
+}
+
 func (n *Node) handleConnClosedEvent(evt event.Event) {
 	e := evt.Data.(connmanager.ConnectionClosedEvent)
 	connId := e.ConnectionId
@@ -337,6 +702,8 @@ func (n *Node) handleConnClosedEvent(evt event.Event) {
 	n.chainsyncState.RemoveClient(connId)
 	// Remove mempool consumer
 	n.mempool.RemoveConsumer(connId)
+	// Remove blockfetch peer stats
+	n.blockfetchPeers.remove(connId)
 	// Release chainsync client
 	n.chainsyncState.RemoveClientConnId(connId)
 }
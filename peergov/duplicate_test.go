@@ -0,0 +1,85 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package peergov
+
+import (
+	"net"
+	"testing"
+
+	"github.com/blinklabs-io/dingo/connmanager"
+	"github.com/blinklabs-io/dingo/event"
+	ouroboros "github.com/blinklabs-io/gouroboros"
+)
+
+// The connection manager publishes the DuplicateConnectionEvent before closing the duplicate connection, but the
+// handlers run asynchronously, so the ConnectionClosedEvent for the duplicate can be handled first
+func TestDuplicateConnectionClosedBeforeDuplicateEvent(t *testing.T) {
+	p := NewPeerGovernor(
+		PeerGovernorConfig{
+			ConnManager: connmanager.NewConnectionManager(
+				connmanager.ConnectionManagerConfig{},
+			),
+		},
+	)
+	remoteAddr := &net.TCPAddr{IP: net.ParseIP("192.0.2.1"), Port: 3001}
+	localAddr := &net.TCPAddr{IP: net.ParseIP("192.0.2.2"), Port: 3001}
+	dropConnId := ouroboros.ConnectionId{
+		LocalAddr:  localAddr,
+		RemoteAddr: remoteAddr,
+	}
+	keepConnId := ouroboros.ConnectionId{
+		LocalAddr:  &net.TCPAddr{IP: localAddr.IP, Port: 3002},
+		RemoteAddr: remoteAddr,
+	}
+	peer := &Peer{
+		Address: remoteAddr.String(),
+		Source:  PeerSourceTopologyLocalRoot,
+		Connection: &PeerConnection{
+			Id:       dropConnId,
+			Outbound: true,
+		},
+	}
+	p.peers = append(p.peers, peer)
+	p.handleConnectionClosedEvent(
+		event.NewEvent(
+			connmanager.ConnectionClosedEventType,
+			connmanager.ConnectionClosedEvent{
+				ConnectionId: dropConnId,
+				Error: connmanager.DuplicateConnectionError{
+					KeptConnectionId: keepConnId,
+				},
+			},
+		),
+	)
+	// The peer is left for the DuplicateConnectionEvent handler to move to the kept connection
+	if len(p.peers) != 1 || peer.removed || peer.Connection == nil {
+		t.Fatalf("peer was changed by closing duplicate connection: got %+v", p.peers)
+	}
+	p.handleDuplicateConnectionEvent(
+		event.NewEvent(
+			connmanager.DuplicateConnectionEventType,
+			connmanager.DuplicateConnectionEvent{
+				ConnectionId:     dropConnId,
+				KeptConnectionId: keepConnId,
+			},
+		),
+	)
+	if len(p.peers) != 1 || p.peers[0] != peer {
+		t.Fatalf("peer was not kept after closing duplicate connection: got %+v", p.peers)
+	}
+	if peer.removed {
+		t.Fatalf("peer was marked as removed after closing duplicate connection")
+	}
+}
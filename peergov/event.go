@@ -20,8 +20,17 @@ import (
 
 const (
 	OutboundConnectionEventType = "peergov.outbound-conn"
+	PeerAbandonedEventType      = "peergov.peer-abandoned"
 )
 
 type OutboundConnectionEvent struct {
 	ConnectionId ouroboros.ConnectionId
 }
+
+// PeerAbandonedEvent is generated when we stop trying to reconnect to an outbound peer
+type PeerAbandonedEvent struct {
+	Address        string
+	Source         PeerSource
+	ReconnectCount int
+	Error          error // Error from the last connection attempt
+}
@@ -0,0 +1,109 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package peergov
+
+import (
+	"container/list"
+	"time"
+)
+
+const (
+	defaultMaxKnownPeers = 1000
+)
+
+// KnownPeer represents a peer discovered via peer sharing that can be used as a candidate for outbound connections
+type KnownPeer struct {
+	Address      string
+	LastSeen     time.Time
+	SuccessCount int
+	FailureCount int
+}
+
+// knownPeerStore is a bounded store of known peers with least-recently-seen eviction
+type knownPeerStore struct {
+	maxSize int
+	entries map[string]*list.Element
+	lru     *list.List
+}
+
+func newKnownPeerStore(maxSize int) *knownPeerStore {
+	if maxSize <= 0 {
+		maxSize = defaultMaxKnownPeers
+	}
+	return &knownPeerStore{
+		maxSize: maxSize,
+		entries: make(map[string]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// add records a sighting of the specified peer address, evicting the least recently seen peer if the store is full
+func (s *knownPeerStore) add(address string) {
+	if elem, ok := s.entries[address]; ok {
+		elem.Value.(*KnownPeer).LastSeen = time.Now()
+		s.lru.MoveToFront(elem)
+		return
+	}
+	if s.lru.Len() >= s.maxSize {
+		oldest := s.lru.Back()
+		if oldest != nil {
+			s.lru.Remove(oldest)
+			delete(s.entries, oldest.Value.(*KnownPeer).Address)
+		}
+	}
+	s.entries[address] = s.lru.PushFront(
+		&KnownPeer{
+			Address:  address,
+			LastSeen: time.Now(),
+		},
+	)
+}
+
+func (s *knownPeerStore) recordSuccess(address string) {
+	if elem, ok := s.entries[address]; ok {
+		elem.Value.(*KnownPeer).SuccessCount++
+	}
+}
+
+func (s *knownPeerStore) recordFailure(address string) {
+	if elem, ok := s.entries[address]; ok {
+		elem.Value.(*KnownPeer).FailureCount++
+	}
+}
+
+// remove forgets the specified peer address
+func (s *knownPeerStore) remove(address string) {
+	if elem, ok := s.entries[address]; ok {
+		s.lru.Remove(elem)
+		delete(s.entries, address)
+	}
+}
+
+// list returns copies of known peers, ordered from most to least recently seen, skipping any for which the
+// exclude function returns true. A count of 0 or less returns all matching peers
+func (s *knownPeerStore) list(count int, exclude func(string) bool) []KnownPeer {
+	ret := []KnownPeer{}
+	for elem := s.lru.Front(); elem != nil; elem = elem.Next() {
+		if count > 0 && len(ret) >= count {
+			break
+		}
+		knownPeer := elem.Value.(*KnownPeer)
+		if exclude != nil && exclude(knownPeer.Address) {
+			continue
+		}
+		ret = append(ret, *knownPeer)
+	}
+	return ret
+}
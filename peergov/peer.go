@@ -31,6 +31,7 @@ const (
 	PeerSourceP2PLedger             = 4
 	PeerSourceP2PGossip             = 5
 	PeerSourceInboundConn           = 6
+	PeerSourceManual                = 7
 )
 
 type Peer struct {
@@ -40,6 +41,17 @@ type Peer struct {
 	Sharable       bool
 	ReconnectCount int
 	ReconnectDelay time.Duration
+	// Number of consecutive outbound connection attempts that failed during the handshake
+	HandshakeFailureCount int
+	// Set when the peer is removed by a topology reload, RemovePeer, or an operator-closed connection to stop reconnect
+	// attempts
+	removed bool
+}
+
+func (p *Peer) isTopologyPeer() bool {
+	return p.Source == PeerSourceTopologyBootstrapPeer ||
+		p.Source == PeerSourceTopologyLocalRoot ||
+		p.Source == PeerSourceTopologyPublicRoot
 }
 
 func (p *Peer) setConnection(conn *ouroboros.Connection, outbound bool) {
@@ -49,6 +61,8 @@ func (p *Peer) setConnection(conn *ouroboros.Connection, outbound bool) {
 		Id:              connId,
 		ProtocolVersion: uint(protoVersion),
 		VersionData:     versionData,
+		Outbound:        outbound,
+		ConnectedSince:  time.Now(),
 	}
 	// Determine whether connection can be used as a client
 	// This should be true for any outbound connections and any inbound
@@ -64,4 +78,14 @@ type PeerConnection struct {
 	ProtocolVersion uint
 	VersionData     oprotocol.VersionData
 	IsClient        bool
+	Outbound        bool
+	ConnectedSince  time.Time
+}
+
+// PeerInfo is a point-in-time snapshot of a connected peer
+type PeerInfo struct {
+	Address        string
+	ConnectionId   ouroboros.ConnectionId
+	Sharable       bool
+	ConnectedSince time.Time
 }
@@ -15,6 +15,8 @@
 package peergov
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -27,24 +29,60 @@ import (
 	"github.com/blinklabs-io/dingo/event"
 	"github.com/blinklabs-io/dingo/topology"
 	ouroboros "github.com/blinklabs-io/gouroboros"
+	opeersharing "github.com/blinklabs-io/gouroboros/protocol/peersharing"
 )
 
 const (
 	initialReconnectDelay  = 1 * time.Second
 	maxReconnectDelay      = 128 * time.Second
 	reconnectBackoffFactor = 2
+
+	// Peers that fail the handshake (wrong network, unsupported version) are unlikely to recover quickly,
+	// so we wait much longer before retrying and eventually give up on them
+	handshakeFailureReconnectDelay = 10 * time.Minute
+	maxHandshakeFailures           = 3
+
+	// Default amount of time to wait for a local root to connect before dialing other peers
+	defaultLocalRootTimeout = 10 * time.Second
+
+	// Default number of outbound peers to maintain, using known peers to make up for any shortfall in configured peers
+	defaultTargetOutboundPeers = 20
+)
+
+var (
+	ErrPeerExists   = errors.New("peer already exists")
+	ErrPeerNotFound = errors.New("peer not found")
 )
 
 type PeerGovernor struct {
-	mu     sync.Mutex
-	config PeerGovernorConfig
-	peers  []*Peer
+	mu                   sync.Mutex
+	config               PeerGovernorConfig
+	peers                []*Peer
+	knownPeers           *knownPeerStore
+	localRootConnected   chan struct{}
+	localRootConnectOnce sync.Once
+	outboundConnChan     chan struct{}
+	selfAddresses        map[string]struct{}
+	started              bool
 }
 
 type PeerGovernorConfig struct {
 	Logger      *slog.Logger
 	EventBus    *event.EventBus
 	ConnManager *connmanager.ConnectionManager
+	// ConnEventSink receives structured reconnect events
+	ConnEventSink connmanager.ConnEventSinkFunc
+	// MaxKnownPeers is the maximum number of peers discovered via peer sharing to remember. This defaults to 1000
+	MaxKnownPeers int
+	// LocalRootTimeout is the amount of time to wait for a local root peer to connect before dialing bootstrap
+	// and public root peers. This defaults to 10s
+	LocalRootTimeout time.Duration
+	// MaxReconnectAttempts is the number of failed reconnect attempts after which an outbound peer is abandoned.
+	// A value of 0 means unlimited
+	MaxReconnectAttempts int
+	// TargetOutboundPeers is the number of outbound peers to maintain. When there are fewer configured peers than
+	// this, known peers discovered via peer sharing are dialed to make up the difference. This defaults to 20
+	TargetOutboundPeers int
 }
 
 func NewPeerGovernor(cfg PeerGovernorConfig) *PeerGovernor {
@@ -52,8 +90,18 @@ func NewPeerGovernor(cfg PeerGovernorConfig) *PeerGovernor {
 		cfg.Logger = slog.New(slog.NewJSONHandler(io.Discard, nil))
 	}
 	cfg.Logger = cfg.Logger.With("component", "peergov")
+	if cfg.LocalRootTimeout == 0 {
+		cfg.LocalRootTimeout = defaultLocalRootTimeout
+	}
+	if cfg.TargetOutboundPeers <= 0 {
+		cfg.TargetOutboundPeers = defaultTargetOutboundPeers
+	}
 	return &PeerGovernor{
-		config: cfg,
+		config:             cfg,
+		knownPeers:         newKnownPeerStore(cfg.MaxKnownPeers),
+		localRootConnected: make(chan struct{}),
+		outboundConnChan:   make(chan struct{}),
+		selfAddresses:      make(map[string]struct{}),
 	}
 }
 
@@ -67,7 +115,14 @@ func (p *PeerGovernor) Start() error {
 		connmanager.ConnectionClosedEventType,
 		p.handleConnectionClosedEvent,
 	)
+	p.config.EventBus.SubscribeFunc(
+		connmanager.DuplicateConnectionEventType,
+		p.handleDuplicateConnectionEvent,
+	)
 	// Start outbound connections
+	p.mu.Lock()
+	p.started = true
+	p.mu.Unlock()
 	p.startOutboundConnections()
 	return nil
 }
@@ -77,12 +132,83 @@ func (p *PeerGovernor) LoadTopologyConfig(
 ) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
+	p.loadTopologyConfig(topologyConfig)
+}
+
+// ReloadTopologyConfig applies a new topology config at runtime. Newly added peers are dialed, removed peers
+// are disconnected and no longer retried, and peers in both the old and new config are left alone
+func (p *PeerGovernor) ReloadTopologyConfig(
+	topologyConfig *topology.TopologyConfig,
+) {
+	p.mu.Lock()
+	oldPeers := make(map[string]*Peer)
+	for _, tmpPeer := range p.peers {
+		if tmpPeer.isTopologyPeer() {
+			oldPeers[tmpPeer.Address] = tmpPeer
+		}
+	}
+	p.loadTopologyConfig(topologyConfig)
+	// Give addresses previously detected as ourselves another chance
+	clear(p.selfAddresses)
+	var newPeers []*Peer
+	for idx, tmpPeer := range p.peers {
+		if !tmpPeer.isTopologyPeer() {
+			continue
+		}
+		oldPeer, ok := oldPeers[tmpPeer.Address]
+		if !ok {
+			newPeers = append(newPeers, tmpPeer)
+			continue
+		}
+		// Keep the existing peer and its connection state
+		oldPeer.Source = tmpPeer.Source
+		oldPeer.Sharable = tmpPeer.Sharable
+		p.peers[idx] = oldPeer
+		delete(oldPeers, tmpPeer.Address)
+	}
+	// Anything left over was removed from the topology
+	var closeConnIds []ouroboros.ConnectionId
+	for _, oldPeer := range oldPeers {
+		oldPeer.removed = true
+		if oldPeer.Connection != nil {
+			closeConnIds = append(closeConnIds, oldPeer.Connection.Id)
+		}
+	}
+	started := p.started
+	p.mu.Unlock()
+	p.config.Logger.Info(
+		fmt.Sprintf(
+			"reloaded topology: %d peers added, %d peers removed",
+			len(newPeers),
+			len(oldPeers),
+		),
+	)
+	for _, connId := range closeConnIds {
+		if err := p.config.ConnManager.CloseConnection(connId, "removed from topology"); err != nil &&
+			!errors.Is(err, connmanager.ErrConnectionNotFound) {
+			p.config.Logger.Warn(
+				fmt.Sprintf(
+					"failed to close connection for removed peer: %s",
+					err,
+				),
+				"connection_id", connId.String(),
+			)
+		}
+	}
+	if started {
+		for _, tmpPeer := range newPeers {
+			go p.createOutboundConnection(tmpPeer)
+		}
+	}
+}
+
+func (p *PeerGovernor) loadTopologyConfig(
+	topologyConfig *topology.TopologyConfig,
+) {
 	// Remove peers originally sourced from the topology
 	tmpPeers := []*Peer{}
 	for _, tmpPeer := range p.peers {
-		if tmpPeer.Source == PeerSourceTopologyBootstrapPeer ||
-			tmpPeer.Source == PeerSourceTopologyLocalRoot ||
-			tmpPeer.Source == PeerSourceTopologyPublicRoot {
+		if tmpPeer.isTopologyPeer() {
 			continue
 		}
 		tmpPeers = append(tmpPeers, tmpPeer)
@@ -160,6 +286,258 @@ func (p *PeerGovernor) GetPeers() []Peer {
 	return ret
 }
 
+// AddPeer adds an outbound peer with the specified address (host:port) and connects to it if the governor has been
+// started. Peers added this way are not affected by topology reloads
+func (p *PeerGovernor) AddPeer(address string) error {
+	p.mu.Lock()
+	if p.peerIndexByAddress(address) != -1 {
+		p.mu.Unlock()
+		return ErrPeerExists
+	}
+	tmpPeer := &Peer{
+		Address: address,
+		Source:  PeerSourceManual,
+	}
+	p.peers = append(p.peers, tmpPeer)
+	started := p.started
+	p.mu.Unlock()
+	if started {
+		go p.createOutboundConnection(tmpPeer)
+	}
+	return nil
+}
+
+// RemovePeer removes the peer with the specified address, closes any active connection to it, and stops further
+// reconnect attempts
+func (p *PeerGovernor) RemovePeer(address string) error {
+	p.mu.Lock()
+	peerIdx := p.peerIndexByAddress(address)
+	if peerIdx == -1 {
+		p.mu.Unlock()
+		return ErrPeerNotFound
+	}
+	tmpPeer := p.peers[peerIdx]
+	tmpPeer.removed = true
+	p.peers = append(p.peers[:peerIdx], p.peers[peerIdx+1:]...)
+	var connId *ouroboros.ConnectionId
+	if tmpPeer.Connection != nil {
+		connId = &tmpPeer.Connection.Id
+	}
+	p.mu.Unlock()
+	if connId != nil {
+		if err := p.config.ConnManager.CloseConnection(*connId, "peer removed"); err != nil &&
+			!errors.Is(err, connmanager.ErrConnectionNotFound) {
+			return err
+		}
+	}
+	return nil
+}
+
+// OutboundPeers returns a snapshot of all peers with an active outbound connection
+func (p *PeerGovernor) OutboundPeers() []PeerInfo {
+	return p.connectedPeers(true)
+}
+
+// InboundPeers returns a snapshot of all peers with an active inbound connection
+func (p *PeerGovernor) InboundPeers() []PeerInfo {
+	return p.connectedPeers(false)
+}
+
+// WaitForOutboundPeers blocks until at least minPeers outbound connections are established or the context is cancelled
+func (p *PeerGovernor) WaitForOutboundPeers(ctx context.Context, minPeers int) error {
+	for {
+		p.mu.Lock()
+		count := 0
+		for _, peer := range p.peers {
+			if peer.Connection != nil && peer.Connection.Outbound {
+				count++
+			}
+		}
+		waitChan := p.outboundConnChan
+		p.mu.Unlock()
+		if count >= minPeers {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-waitChan:
+		}
+	}
+}
+
+func (p *PeerGovernor) connectedPeers(outbound bool) []PeerInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ret := []PeerInfo{}
+	for _, peer := range p.peers {
+		if peer.Connection == nil || peer.Connection.Outbound != outbound {
+			continue
+		}
+		ret = append(
+			ret,
+			PeerInfo{
+				Address:        peer.Address,
+				ConnectionId:   peer.Connection.Id,
+				Sharable:       peer.Sharable,
+				ConnectedSince: peer.Connection.ConnectedSince,
+			},
+		)
+	}
+	return ret
+}
+
+// SharablePeers returns up to the specified number of peers that are eligible to be shared with other nodes via
+// peer sharing. Only peers explicitly marked as sharable are returned, and peers learned from inbound connections
+// are never shared
+func (p *PeerGovernor) SharablePeers(amount int) []Peer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ret := []Peer{}
+	for _, peer := range p.peers {
+		if len(ret) >= amount {
+			break
+		}
+		if !peer.Sharable || peer.Source == PeerSourceInboundConn {
+			continue
+		}
+		ret = append(ret, *peer)
+	}
+	return ret
+}
+
+// ClientConnectionIds returns the IDs of connections that we can act as a client on
+func (p *PeerGovernor) ClientConnectionIds() []ouroboros.ConnectionId {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var ret []ouroboros.ConnectionId
+	for _, peer := range p.peers {
+		if peer.Connection == nil || !peer.Connection.IsClient {
+			continue
+		}
+		ret = append(ret, peer.Connection.Id)
+	}
+	return ret
+}
+
+// PeerSharingConnectionIds returns the IDs of connections that we can act as a client on and whose peer advertised
+// willingness to share peers
+func (p *PeerGovernor) PeerSharingConnectionIds() []ouroboros.ConnectionId {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var ret []ouroboros.ConnectionId
+	for _, peer := range p.peers {
+		if peer.Connection == nil || !peer.Connection.IsClient {
+			continue
+		}
+		if peer.Connection.VersionData == nil ||
+			!peer.Connection.VersionData.PeerSharing() {
+			continue
+		}
+		ret = append(ret, peer.Connection.Id)
+	}
+	return ret
+}
+
+// AddKnownPeers records peer addresses discovered via peer sharing. Addresses matching configured topology peers are ignored
+func (p *PeerGovernor) AddKnownPeers(addresses []string) {
+	p.mu.Lock()
+	for _, address := range addresses {
+		peerIdx := p.peerIndexByAddress(address)
+		if peerIdx != -1 && p.peers[peerIdx].isTopologyPeer() {
+			continue
+		}
+		if _, ok := p.selfAddresses[address]; ok {
+			continue
+		}
+		p.knownPeers.add(address)
+	}
+	p.mu.Unlock()
+	// Use the new known peers if we're short of outbound peers
+	p.fillOutboundPeers()
+}
+
+// AddSharedPeers records peers received in a peer sharing response as known peers, which are dialed if we're short
+// of outbound peers
+func (p *PeerGovernor) AddSharedPeers(peers []opeersharing.PeerAddress) {
+	addresses := make([]string, 0, len(peers))
+	for _, peer := range peers {
+		addresses = append(
+			addresses,
+			net.JoinHostPort(
+				peer.IP.String(),
+				strconv.FormatUint(uint64(peer.Port), 10),
+			),
+		)
+	}
+	p.AddKnownPeers(addresses)
+}
+
+// KnownPeers returns all known peers discovered via peer sharing, ordered from most to least recently seen
+func (p *PeerGovernor) KnownPeers() []KnownPeer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.knownPeers.list(0, nil)
+}
+
+// KnownPeerCandidates returns up to the specified number of known peers that are not already tracked by the peer
+// governor, for use as fallback outbound connection candidates
+func (p *PeerGovernor) KnownPeerCandidates(count int) []KnownPeer {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.knownPeerCandidates(count)
+}
+
+func (p *PeerGovernor) knownPeerCandidates(count int) []KnownPeer {
+	return p.knownPeers.list(
+		count,
+		func(address string) bool {
+			if _, ok := p.selfAddresses[address]; ok {
+				return true
+			}
+			return p.peerIndexByAddress(address) != -1
+		},
+	)
+}
+
+// fillOutboundPeers dials known peers to make up any shortfall between the outbound peers we're tracking and the
+// target number of outbound peers. Known peers that fail to connect are forgotten, which lets us churn through
+// candidates until the target is reached or we run out
+func (p *PeerGovernor) fillOutboundPeers() {
+	p.mu.Lock()
+	if !p.started {
+		p.mu.Unlock()
+		return
+	}
+	outboundCount := 0
+	for _, tmpPeer := range p.peers {
+		if tmpPeer.Source != PeerSourceInboundConn {
+			outboundCount++
+		}
+	}
+	if outboundCount >= p.config.TargetOutboundPeers {
+		p.mu.Unlock()
+		return
+	}
+	var newPeers []*Peer
+	for _, knownPeer := range p.knownPeerCandidates(p.config.TargetOutboundPeers - outboundCount) {
+		tmpPeer := &Peer{
+			Address: knownPeer.Address,
+			Source:  PeerSourceP2PGossip,
+		}
+		p.peers = append(p.peers, tmpPeer)
+		newPeers = append(newPeers, tmpPeer)
+	}
+	p.mu.Unlock()
+	for _, tmpPeer := range newPeers {
+		p.config.Logger.Debug(
+			"outbound: dialing known peer "+tmpPeer.Address,
+			"role", "client",
+		)
+		go p.createOutboundConnection(tmpPeer)
+	}
+}
+
 func (p *PeerGovernor) peerIndexByAddress(address string) int {
 	for idx, tmpPeer := range p.peers {
 		if tmpPeer.Address == address {
@@ -186,18 +564,80 @@ func (p *PeerGovernor) startOutboundConnections() {
 		"starting connections",
 		"role", "client",
 	)
+	// Dial local roots first, since they are trusted and generally low latency. The first
+	// outbound connection is used for chainsync, so we give them a head start before dialing
+	// bootstrap and public root peers
+	var localRoots, otherPeers []*Peer
 	for _, tmpPeer := range p.peers {
+		if tmpPeer.Source == PeerSourceTopologyLocalRoot {
+			localRoots = append(localRoots, tmpPeer)
+		} else {
+			otherPeers = append(otherPeers, tmpPeer)
+		}
+	}
+	for _, tmpPeer := range localRoots {
 		go p.createOutboundConnection(tmpPeer)
 	}
+	if len(localRoots) == 0 {
+		for _, tmpPeer := range otherPeers {
+			go p.createOutboundConnection(tmpPeer)
+		}
+		p.fillOutboundPeers()
+		return
+	}
+	go func() {
+		select {
+		case <-p.localRootConnected:
+		case <-time.After(p.config.LocalRootTimeout):
+			p.config.Logger.Warn(
+				fmt.Sprintf(
+					"no local root connected within %s, dialing other peers",
+					p.config.LocalRootTimeout,
+				),
+				"role", "client",
+			)
+		}
+		for _, tmpPeer := range otherPeers {
+			go p.createOutboundConnection(tmpPeer)
+		}
+		p.fillOutboundPeers()
+	}()
 }
 
 func (p *PeerGovernor) createOutboundConnection(peer *Peer) {
 	for {
+		// Stop trying if the peer was removed from the topology
+		p.mu.Lock()
+		removed := peer.removed
+		p.mu.Unlock()
+		if removed {
+			return
+		}
 		conn, err := p.config.ConnManager.CreateOutboundConn(peer.Address)
 		if err == nil {
 			connId := conn.Id()
+			p.mu.Lock()
+			if peer.removed {
+				p.mu.Unlock()
+				_ = p.config.ConnManager.CloseConnection(
+					connId,
+					"removed from topology",
+				)
+				return
+			}
 			peer.ReconnectCount = 0
+			peer.HandshakeFailureCount = 0
 			peer.setConnection(conn, true)
+			p.knownPeers.recordSuccess(peer.Address)
+			// Notify anything waiting on outbound connections
+			close(p.outboundConnChan)
+			p.outboundConnChan = make(chan struct{})
+			p.mu.Unlock()
+			if peer.Source == PeerSourceTopologyLocalRoot {
+				p.localRootConnectOnce.Do(func() {
+					close(p.localRootConnected)
+				})
+			}
 			// Generate event
 			if p.config.EventBus != nil {
 				p.config.EventBus.Publish(
@@ -212,6 +652,33 @@ func (p *PeerGovernor) createOutboundConnection(peer *Peer) {
 			}
 			return
 		}
+		// Use the existing inbound connection from the peer instead
+		var dupErr connmanager.DuplicateConnectionError
+		if errors.As(err, &dupErr) {
+			p.mu.Lock()
+			if conn := p.config.ConnManager.GetConnectionById(dupErr.KeptConnectionId); conn != nil {
+				peer.ReconnectCount = 0
+				peer.HandshakeFailureCount = 0
+				peer.setConnection(conn, false)
+			}
+			p.mu.Unlock()
+			return
+		}
+		// Don't keep dialing ourselves
+		if errors.Is(err, connmanager.ErrSelfConnection) {
+			p.config.Logger.Warn(
+				fmt.Sprintf(
+					"outbound: self-connection detected for %s, excluding address until topology reload",
+					peer.Address,
+				),
+			)
+			p.mu.Lock()
+			p.selfAddresses[peer.Address] = struct{}{}
+			p.knownPeers.remove(peer.Address)
+			p.mu.Unlock()
+			p.abandonPeer(peer, err)
+			return
+		}
 		p.config.Logger.Error(
 			fmt.Sprintf(
 				"outbound: failed to establish connection to %s: %s",
@@ -219,12 +686,55 @@ func (p *PeerGovernor) createOutboundConnection(peer *Peer) {
 				err,
 			),
 		)
-		if peer.ReconnectDelay == 0 {
-			peer.ReconnectDelay = initialReconnectDelay
-		} else if peer.ReconnectDelay < maxReconnectDelay {
-			peer.ReconnectDelay = peer.ReconnectDelay * reconnectBackoffFactor
+		p.mu.Lock()
+		p.knownPeers.recordFailure(peer.Address)
+		// Known peers are only fallback candidates, so we move on to another instead of retrying
+		if peer.Source == PeerSourceP2PGossip {
+			p.knownPeers.remove(peer.Address)
+			p.mu.Unlock()
+			p.abandonPeer(peer, err)
+			return
+		}
+		p.mu.Unlock()
+		if connmanager.IsHandshakeError(err) {
+			peer.HandshakeFailureCount += 1
+			if peer.HandshakeFailureCount >= maxHandshakeFailures {
+				p.config.Logger.Warn(
+					fmt.Sprintf(
+						"outbound: giving up on %s after %d consecutive handshake failures",
+						peer.Address,
+						peer.HandshakeFailureCount,
+					),
+				)
+				p.abandonPeer(peer, err)
+				return
+			}
+			peer.ReconnectDelay = handshakeFailureReconnectDelay
+		} else {
+			// Resume normal backoff after a transient failure
+			if peer.HandshakeFailureCount > 0 {
+				peer.HandshakeFailureCount = 0
+				peer.ReconnectDelay = 0
+			}
+			if peer.ReconnectDelay == 0 {
+				peer.ReconnectDelay = initialReconnectDelay
+			} else if peer.ReconnectDelay < maxReconnectDelay {
+				peer.ReconnectDelay = peer.ReconnectDelay * reconnectBackoffFactor
+			}
 		}
 		peer.ReconnectCount += 1
+		if p.config.MaxReconnectAttempts > 0 &&
+			peer.ReconnectCount > p.config.MaxReconnectAttempts {
+			p.config.Logger.Warn(
+				fmt.Sprintf(
+					"outbound: giving up on %s after %d reconnect attempts",
+					peer.Address,
+					p.config.MaxReconnectAttempts,
+				),
+			)
+			p.abandonPeer(peer, err)
+			return
+		}
 		p.config.Logger.Info(
 			fmt.Sprintf(
 				"outbound: delaying %s (retry %d) before reconnecting to %s",
@@ -233,10 +743,51 @@ func (p *PeerGovernor) createOutboundConnection(peer *Peer) {
 				peer.Address,
 			),
 		)
+		if p.config.ConnEventSink != nil {
+			p.config.ConnEventSink(
+				connmanager.ConnEvent{
+					Type:           connmanager.ConnEventTypeReconnect,
+					Timestamp:      time.Now(),
+					Address:        peer.Address,
+					Error:          err,
+					ReconnectCount: peer.ReconnectCount,
+					ReconnectDelay: peer.ReconnectDelay,
+				},
+			)
+		}
 		time.Sleep(peer.ReconnectDelay)
 	}
 }
 
+// abandonPeer removes a peer from the outbound set and generates a PeerAbandonedEvent. Abandoned topology
+// peers are added back when the topology config is reloaded
+func (p *PeerGovernor) abandonPeer(peer *Peer, err error) {
+	p.mu.Lock()
+	for i, tmpPeer := range p.peers {
+		if tmpPeer == peer {
+			p.peers = append(p.peers[:i], p.peers[i+1:]...)
+			break
+		}
+	}
+	p.mu.Unlock()
+	if p.config.EventBus != nil {
+		p.config.EventBus.Publish(
+			PeerAbandonedEventType,
+			event.NewEvent(
+				PeerAbandonedEventType,
+				PeerAbandonedEvent{
+					Address:        peer.Address,
+					Source:         peer.Source,
+					ReconnectCount: peer.ReconnectCount,
+					Error:          err,
+				},
+			),
+		)
+	}
+	// Replace the abandoned peer with a known peer
+	p.fillOutboundPeers()
+}
+
 func (p *PeerGovernor) handleInboundConnectionEvent(evt event.Event) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
@@ -263,10 +814,38 @@ func (p *PeerGovernor) handleInboundConnectionEvent(evt event.Event) {
 	}
 }
 
+// handleDuplicateConnectionEvent points the peer for a closed duplicate connection at the connection that was kept
+func (p *PeerGovernor) handleDuplicateConnectionEvent(evt event.Event) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	e := evt.Data.(connmanager.DuplicateConnectionEvent)
+	peerIdx := p.peerIndexByConnId(e.ConnectionId)
+	if peerIdx == -1 && e.ConnectionId.RemoteAddr != nil {
+		peerIdx = p.peerIndexByAddress(e.ConnectionId.RemoteAddr.String())
+	}
+	if peerIdx == -1 {
+		return
+	}
+	conn := p.config.ConnManager.GetConnectionById(e.KeptConnectionId)
+	if conn == nil {
+		return
+	}
+	// The kept connection is outbound if the closed one was inbound
+	p.peers[peerIdx].setConnection(conn, e.Inbound)
+}
+
 func (p *PeerGovernor) handleConnectionClosedEvent(evt event.Event) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 	e := evt.Data.(connmanager.ConnectionClosedEvent)
+	// The peer is moved to the kept connection by the DuplicateConnectionEvent handler, which may run either before or
+	// after this one, so there's nothing to do here
+	if errors.Is(e.Error, connmanager.ErrDuplicateConnection) {
+		p.config.Logger.Info("duplicate connection closed",
+			"connection_id", e.ConnectionId.String(),
+		)
+		return
+	}
 	if e.Error != nil {
 		p.config.Logger.Error(
 			fmt.Sprintf(
@@ -283,6 +862,13 @@ func (p *PeerGovernor) handleConnectionClosedEvent(evt event.Event) {
 	peerIdx := p.peerIndexByConnId(e.ConnectionId)
 	if peerIdx != -1 {
 		p.peers[peerIdx].Connection = nil
+		// Stop tracking peers that were deliberately disconnected, so that we don't reconnect to them
+		var closedErr connmanager.ClosedByOperatorError
+		if errors.As(e.Error, &closedErr) {
+			p.peers[peerIdx].removed = true
+			p.peers = append(p.peers[:peerIdx], p.peers[peerIdx+1:]...)
+			return
+		}
 		if p.peers[peerIdx].Source != PeerSourceInboundConn {
 			go p.createOutboundConnection(p.peers[peerIdx])
 		}
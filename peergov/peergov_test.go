@@ -0,0 +1,263 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package peergov_test
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/blinklabs-io/dingo/connmanager"
+	"github.com/blinklabs-io/dingo/event"
+	"github.com/blinklabs-io/dingo/peergov"
+	"github.com/blinklabs-io/dingo/topology"
+	opeersharing "github.com/blinklabs-io/gouroboros/protocol/peersharing"
+)
+
+func TestSharablePeersExcludesNonSharable(t *testing.T) {
+	pg := peergov.NewPeerGovernor(peergov.PeerGovernorConfig{})
+	pg.LoadTopologyConfig(
+		&topology.TopologyConfig{
+			LocalRoots: []topology.TopologyConfigP2PLocalRoot{
+				{
+					AccessPoints: []topology.TopologyConfigP2PAccessPoint{
+						{Address: "10.0.0.1", Port: 3001},
+					},
+					Advertise: false,
+				},
+			},
+			PublicRoots: []topology.TopologyConfigP2PPublicRoot{
+				{
+					AccessPoints: []topology.TopologyConfigP2PAccessPoint{
+						{Address: "192.0.2.1", Port: 3001},
+					},
+					Advertise: true,
+				},
+			},
+			BootstrapPeers: []topology.TopologyConfigP2PBootstrapPeer{
+				{Address: "192.0.2.2", Port: 3001},
+			},
+		},
+	)
+	peers := pg.SharablePeers(10)
+	if len(peers) != 1 {
+		t.Fatalf(
+			"did not get expected number of sharable peers: got %d, expected 1",
+			len(peers),
+		)
+	}
+	if peers[0].Address != "192.0.2.1:3001" {
+		t.Fatalf(
+			"did not get expected sharable peer: got %s, expected %s",
+			peers[0].Address,
+			"192.0.2.1:3001",
+		)
+	}
+	for _, peer := range peers {
+		if peer.Address == "10.0.0.1:3001" {
+			t.Fatalf("non-advertised local root was returned as sharable")
+		}
+	}
+}
+
+func TestKnownPeersEvictionAndDedup(t *testing.T) {
+	pg := peergov.NewPeerGovernor(
+		peergov.PeerGovernorConfig{
+			MaxKnownPeers: 2,
+		},
+	)
+	pg.LoadTopologyConfig(
+		&topology.TopologyConfig{
+			BootstrapPeers: []topology.TopologyConfigP2PBootstrapPeer{
+				{Address: "192.0.2.1", Port: 3001},
+			},
+		},
+	)
+	pg.AddKnownPeers(
+		[]string{
+			"192.0.2.1:3001",
+			"198.51.100.1:3001",
+			"198.51.100.2:3001",
+			"198.51.100.3:3001",
+		},
+	)
+	expected := []string{
+		"198.51.100.3:3001",
+		"198.51.100.2:3001",
+	}
+	knownPeers := pg.KnownPeers()
+	if len(knownPeers) != len(expected) {
+		t.Fatalf(
+			"did not get expected number of known peers: got %d, expected %d",
+			len(knownPeers),
+			len(expected),
+		)
+	}
+	for idx, knownPeer := range knownPeers {
+		if knownPeer.Address != expected[idx] {
+			t.Fatalf(
+				"did not get expected known peer: got %s, expected %s",
+				knownPeer.Address,
+				expected[idx],
+			)
+		}
+	}
+}
+
+func TestAddRemovePeer(t *testing.T) {
+	pg := peergov.NewPeerGovernor(peergov.PeerGovernorConfig{})
+	if err := pg.AddPeer("192.0.2.1:3001"); err != nil {
+		t.Fatalf("unexpected error adding peer: %s", err)
+	}
+	if err := pg.AddPeer("192.0.2.1:3001"); !errors.Is(err, peergov.ErrPeerExists) {
+		t.Fatalf("did not get expected error adding duplicate peer: got %v", err)
+	}
+	peers := pg.GetPeers()
+	if len(peers) != 1 || peers[0].Source != peergov.PeerSourceManual {
+		t.Fatalf("did not get expected manual peer: got %+v", peers)
+	}
+	if err := pg.RemovePeer("192.0.2.1:3001"); err != nil {
+		t.Fatalf("unexpected error removing peer: %s", err)
+	}
+	if len(pg.GetPeers()) != 0 {
+		t.Fatalf("peer was not removed")
+	}
+	if err := pg.RemovePeer("192.0.2.1:3001"); !errors.Is(err, peergov.ErrPeerNotFound) {
+		t.Fatalf("did not get expected error removing unknown peer: got %v", err)
+	}
+}
+
+// newTestDialListener returns a listener and a channel that receives a value for each accepted connection. We don't
+// complete the handshake, so the dial fails once the connection is closed, which happens immediately if closeConns is
+// set and otherwise when the test finishes
+func newTestDialListener(t *testing.T, closeConns bool) (net.Listener, <-chan struct{}) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("unexpected error creating listener: %s", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	acceptChan := make(chan struct{}, 10)
+	var connsMutex sync.Mutex
+	var conns []net.Conn
+	t.Cleanup(func() {
+		connsMutex.Lock()
+		defer connsMutex.Unlock()
+		for _, conn := range conns {
+			conn.Close()
+		}
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			if closeConns {
+				conn.Close()
+			} else {
+				connsMutex.Lock()
+				conns = append(conns, conn)
+				connsMutex.Unlock()
+			}
+			acceptChan <- struct{}{}
+		}
+	}()
+	return listener, acceptChan
+}
+
+func newTestStartedPeerGovernor(t *testing.T) *peergov.PeerGovernor {
+	eventBus := event.NewEventBus(nil)
+	pg := peergov.NewPeerGovernor(
+		peergov.PeerGovernorConfig{
+			EventBus: eventBus,
+			ConnManager: connmanager.NewConnectionManager(
+				connmanager.ConnectionManagerConfig{
+					EventBus: eventBus,
+				},
+			),
+			TargetOutboundPeers: 2,
+		},
+	)
+	if err := pg.Start(); err != nil {
+		t.Fatalf("unexpected error starting peer governor: %s", err)
+	}
+	return pg
+}
+
+func TestKnownPeerDialed(t *testing.T) {
+	listener, acceptChan := newTestDialListener(t, true)
+	pg := newTestStartedPeerGovernor(t)
+	pg.AddKnownPeers([]string{listener.Addr().String()})
+	select {
+	case <-acceptChan:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("known peer was not dialed")
+	}
+	// A known peer that fails to connect is forgotten and dropped from the outbound peers
+	deadline := time.Now().Add(5 * time.Second)
+	for len(pg.KnownPeers()) > 0 || len(pg.GetPeers()) > 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("failed known peer was not removed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestKnownPeersRespectOutboundTarget(t *testing.T) {
+	configuredListener, _ := newTestDialListener(t, false)
+	knownListener1, _ := newTestDialListener(t, false)
+	knownListener2, _ := newTestDialListener(t, false)
+	pg := newTestStartedPeerGovernor(t)
+	if err := pg.AddPeer(configuredListener.Addr().String()); err != nil {
+		t.Fatalf("unexpected error adding peer: %s", err)
+	}
+	pg.AddKnownPeers(
+		[]string{
+			knownListener1.Addr().String(),
+			knownListener2.Addr().String(),
+		},
+	)
+	// One configured peer and one known peer make up the target of 2
+	var gossipPeers int
+	for _, peer := range pg.GetPeers() {
+		if peer.Source == peergov.PeerSourceP2PGossip {
+			gossipPeers++
+		}
+	}
+	if gossipPeers != 1 {
+		t.Fatalf("did not get expected number of known peers dialed: got %d, expected 1", gossipPeers)
+	}
+}
+
+func TestSharedPeerDialed(t *testing.T) {
+	listener, acceptChan := newTestDialListener(t, true)
+	pg := newTestStartedPeerGovernor(t)
+	listenerAddr := listener.Addr().(*net.TCPAddr)
+	pg.AddSharedPeers(
+		[]opeersharing.PeerAddress{
+			{
+				IP:   listenerAddr.IP,
+				Port: uint16(listenerAddr.Port), // #nosec G115
+			},
+		},
+	)
+	select {
+	case <-acceptChan:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("shared peer was not dialed")
+	}
+}
@@ -15,12 +15,22 @@
 package dingo
 
 import (
+	"context"
 	"net"
 	"strconv"
+	"time"
 
+	"github.com/blinklabs-io/dingo/event"
+	"github.com/blinklabs-io/dingo/peergov"
+	ouroboros "github.com/blinklabs-io/gouroboros"
 	opeersharing "github.com/blinklabs-io/gouroboros/protocol/peersharing"
 )
 
+const (
+	defaultPeerSharingRequestInterval = 5 * time.Minute
+	defaultPeerSharingRequestAmount   = 10
+)
+
 func (n *Node) peersharingServerConnOpts() []opeersharing.PeerSharingOptionFunc {
 	return []opeersharing.PeerSharingOptionFunc{
 		opeersharing.WithShareRequestFunc(n.peersharingShareRequest),
@@ -37,34 +47,121 @@ func (n *Node) peersharingShareRequest(
 	amount int,
 ) ([]opeersharing.PeerAddress, error) {
 	peers := []opeersharing.PeerAddress{}
-	var cnt int
-	for _, peer := range n.peerGov.GetPeers() {
-		cnt++
-		if cnt > amount {
-			break
+	for _, peer := range n.peerGov.SharablePeers(amount) {
+		host, port, err := net.SplitHostPort(peer.Address)
+		if err != nil {
+			// Skip on error
+			n.config.logger.Debug("failed to split peer address, skipping")
+			continue
+		}
+		portNum, err := strconv.ParseUint(port, 10, 16)
+		if err != nil {
+			// Skip on error
+			n.config.logger.Debug("failed to parse peer port, skipping")
+			continue
 		}
-		if peer.Sharable {
-			host, port, err := net.SplitHostPort(peer.Address)
-			if err != nil {
-				// Skip on error
-				n.config.logger.Debug("failed to split peer address, skipping")
-				continue
+		ip := net.ParseIP(host)
+		if ip == nil {
+			// Skip on error
+			n.config.logger.Debug("peer address is not an IP, skipping")
+			continue
+		}
+		n.config.logger.Debug(
+			"adding peer for sharing: " + peer.Address,
+		)
+		peers = append(peers, opeersharing.PeerAddress{
+			IP:   ip,
+			Port: uint16(portNum),
+		},
+		)
+	}
+	return peers, nil
+}
+
+// peersharingStartRequests periodically requests peers from connected peers that are willing to share them, as well as
+// from each new outbound connection, and adds the results to the known peers
+func (n *Node) peersharingStartRequests() {
+	interval := n.config.peerSharingInterval
+	if interval == 0 {
+		interval = defaultPeerSharingRequestInterval
+	}
+	n.peersharingMutex.Lock()
+	n.peersharingInflight = make(map[ouroboros.ConnectionId]struct{})
+	n.peersharingMutex.Unlock()
+	n.eventBus.SubscribeFunc(
+		peergov.OutboundConnectionEventType,
+		func(evt event.Event) {
+			e := evt.Data.(peergov.OutboundConnectionEvent)
+			go n.peersharingRequestPeers(e.ConnectionId)
+		},
+	)
+	doneChan := make(chan struct{})
+	n.shutdownFuncs = append(
+		n.shutdownFuncs,
+		func(_ context.Context) error {
+			close(doneChan)
+			return nil
+		},
+	)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-doneChan:
+				return
+			case <-ticker.C:
 			}
-			portNum, err := strconv.ParseUint(port, 10, 16)
-			if err != nil {
-				// Skip on error
-				n.config.logger.Debug("failed to parse peer port, skipping")
-				continue
+			for _, connId := range n.peerGov.PeerSharingConnectionIds() {
+				go n.peersharingRequestPeers(connId)
 			}
-			n.config.logger.Debug(
-				"adding peer for sharing: " + peer.Address,
-			)
-			peers = append(peers, opeersharing.PeerAddress{
-				IP:   net.ParseIP(host),
-				Port: uint16(portNum),
-			},
-			)
 		}
+	}()
+}
+
+// peersharingRequestPeers requests peers from the specified connection if it supports peer sharing. Only one request
+// is outstanding per connection at a time
+func (n *Node) peersharingRequestPeers(connId ouroboros.ConnectionId) {
+	conn := n.connManager.GetConnectionById(connId)
+	if conn == nil {
+		return
 	}
-	return peers, nil
+	_, versionData := conn.ProtocolVersion()
+	if versionData == nil || !versionData.PeerSharing() {
+		return
+	}
+	peerSharing := conn.PeerSharing()
+	if peerSharing == nil || peerSharing.Client == nil {
+		return
+	}
+	n.peersharingMutex.Lock()
+	if _, ok := n.peersharingInflight[connId]; ok {
+		n.peersharingMutex.Unlock()
+		return
+	}
+	n.peersharingInflight[connId] = struct{}{}
+	n.peersharingMutex.Unlock()
+	defer func() {
+		n.peersharingMutex.Lock()
+		delete(n.peersharingInflight, connId)
+		n.peersharingMutex.Unlock()
+	}()
+	amount := n.config.peerSharingAmount
+	if amount == 0 {
+		amount = defaultPeerSharingRequestAmount
+	}
+	peers, err := peerSharing.Client.GetPeers(amount)
+	if err != nil {
+		n.config.logger.Debug(
+			"failed to request peers: "+err.Error(),
+			"connection_id", connId.String(),
+		)
+		return
+	}
+	n.config.logger.Debug(
+		"received shared peers",
+		"connection_id", connId.String(),
+		"count", len(peers),
+	)
+	n.peerGov.AddSharedPeers(peers)
 }
@@ -0,0 +1,245 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/dingo/database"
+)
+
+const (
+	// Max number of metadata records to delete in a single DB transaction
+	pruneMetadataBatchSize = 10000
+
+	// Max number of block bodies to prune in a single DB transaction
+	pruneBlockBatchSize = 1000
+
+	// Interval between block pruning runs when block retention is enabled
+	pruneBlocksInterval = 10 * time.Minute
+)
+
+// ErrPointTooOld is returned when a requested point is before the retained block window
+var ErrPointTooOld = errors.New("point too old")
+
+// PruneMetadataBefore deletes consumed UTxOs and non-checkpoint block nonces from the metadata store that are older
+// than the specified slot, and then returns free space to the filesystem. The slot is clamped so that data needed to
+// serve the configured number of chainsync intersect points is retained. Records are deleted in batches, and the
+// ledger is only locked for each batch so that block processing can continue. It returns the number of records deleted
+func (n *Node) PruneMetadataBefore(slot uint64) (int, error) {
+	if n.ledgerState == nil || n.db == nil {
+		return 0, ErrNodeNotRunning
+	}
+	// Keep everything within the chainsync intersect window
+	intersectPointCount := n.config.intersectPointCount
+	if intersectPointCount == 0 {
+		intersectPointCount = defaultChainsyncIntersectPointCount
+	}
+	recentPoints, err := n.ledgerState.RecentChainPoints(intersectPointCount)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get recent chain points: %w", err)
+	}
+	if len(recentPoints) > 0 {
+		// Recent points are in descending order
+		slot = min(slot, recentPoints[len(recentPoints)-1].Slot)
+	}
+	// Consumed UTxOs are needed to undo volatile blocks on rollback, so keep everything after the immutable tip
+	if n.chainManager != nil && n.chainManager.SecurityParam() > 0 {
+		immutableTip, err := n.chainManager.PrimaryChain().ImmutableTip()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get immutable tip: %w", err)
+		}
+		slot = min(slot, immutableTip.Point.Slot)
+	}
+	var deleted int
+	for {
+		count, err := n.pruneMetadataBatch(
+			func(txn *database.Txn) (int, error) {
+				return n.db.DeleteBlockNoncesBeforeSlotWithoutCheckpoints(
+					slot,
+					pruneMetadataBatchSize,
+					txn,
+				)
+			},
+		)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete block nonces: %w", err)
+		}
+		if count == 0 {
+			break
+		}
+		deleted += count
+	}
+	for {
+		count, err := n.pruneMetadataBatch(
+			func(txn *database.Txn) (int, error) {
+				return n.db.UtxosDeleteConsumed(
+					slot,
+					pruneMetadataBatchSize,
+					txn,
+				)
+			},
+		)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete consumed UTxOs: %w", err)
+		}
+		if count == 0 {
+			break
+		}
+		deleted += count
+	}
+	if err := n.db.Metadata().IncrementalVacuum(); err != nil {
+		n.config.logger.Warn(
+			"failed to run incremental vacuum after pruning",
+			"component", "node",
+			"error", err,
+		)
+	}
+	return deleted, nil
+}
+
+// pruneMetadataBatch runs a single bounded delete in its own transaction while holding the ledger lock. The lock is
+// released between batches
+func (n *Node) pruneMetadataBatch(
+	deleteFunc func(*database.Txn) (int, error),
+) (int, error) {
+	n.ledgerState.Lock()
+	defer n.ledgerState.Unlock()
+	var count int
+	txn := n.db.Transaction(true)
+	err := txn.Do(func(txn *database.Txn) error {
+		var err error
+		count, err = deleteFunc(txn)
+		return err
+	})
+	return count, err
+}
+
+// PruneBlocksBefore removes the bodies of blocks older than the specified slot from the block store, keeping the
+// block index and metadata needed for chainsync intersection. The slot is clamped so that volatile blocks and blocks
+// from the current epoch, which are needed for rollbacks and reward calculation, are retained. It returns the number of
+// blocks pruned
+func (n *Node) PruneBlocksBefore(slot uint64) (int, error) {
+	if n.ledgerState == nil || n.chainManager == nil || n.db == nil {
+		return 0, ErrNodeNotRunning
+	}
+	pruner, ok := n.chainManager.BlockStore().(chain.BlockPruner)
+	if !ok {
+		return 0, errors.New("block store does not support pruning")
+	}
+	n.ledgerState.RLock()
+	slot = min(slot, n.ledgerState.CurrentEpoch().StartSlot)
+	n.ledgerState.RUnlock()
+	if n.chainManager.SecurityParam() > 0 {
+		immutableTip, err := n.chainManager.PrimaryChain().ImmutableTip()
+		if err != nil {
+			return 0, fmt.Errorf("failed to get immutable tip: %w", err)
+		}
+		slot = min(slot, immutableTip.Point.Slot)
+	}
+	n.blockPruneMutex.Lock()
+	defer n.blockPruneMutex.Unlock()
+	// Resume from the end of the previous pruning run
+	startSlot := n.blockPrunedSlot.Load()
+	if slot <= startSlot {
+		return 0, nil
+	}
+	var pruned int
+	for {
+		count, nextSlot, err := pruner.PruneBefore(
+			startSlot,
+			slot,
+			pruneBlockBatchSize,
+			nil,
+		)
+		if err != nil {
+			return pruned, fmt.Errorf("failed to prune blocks: %w", err)
+		}
+		if count == 0 {
+			break
+		}
+		pruned += count
+		startSlot = nextSlot
+	}
+	// Record the new start of the retained window
+	if err := n.db.SetBlockPrunedSlot(slot, nil); err != nil {
+		return pruned, fmt.Errorf("failed to save block prune boundary: %w", err)
+	}
+	n.blockPrunedSlot.Store(slot)
+	return pruned, nil
+}
+
+// blockRetentionStartSlot returns the first slot of the retained block window. Blocks before this slot may have been
+// pruned and can't be served to peers
+func (n *Node) blockRetentionStartSlot() uint64 {
+	startSlot := n.blockPrunedSlot.Load()
+	if n.config.blockRetention == 0 || n.ledgerState == nil {
+		return startSlot
+	}
+	tipSlot := n.ledgerState.Tip().Point.Slot
+	if tipSlot > n.config.blockRetention {
+		startSlot = max(startSlot, tipSlot-n.config.blockRetention)
+	}
+	return startSlot
+}
+
+// startBlockPruning periodically prunes block bodies outside of the configured retention window
+func (n *Node) startBlockPruning() {
+	if n.config.blockRetention == 0 {
+		return
+	}
+	doneChan := make(chan struct{})
+	n.shutdownFuncs = append(
+		n.shutdownFuncs,
+		func(_ context.Context) error {
+			close(doneChan)
+			return nil
+		},
+	)
+	go func() {
+		ticker := time.NewTicker(pruneBlocksInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-doneChan:
+				return
+			case <-ticker.C:
+			}
+			tipSlot := n.ledgerState.Tip().Point.Slot
+			if tipSlot <= n.config.blockRetention {
+				continue
+			}
+			pruned, err := n.PruneBlocksBefore(tipSlot - n.config.blockRetention)
+			if err != nil {
+				n.config.logger.Error(
+					"failed to prune blocks",
+					"component", "node",
+					"error", err,
+				)
+				continue
+			}
+			if pruned > 0 {
+				n.config.logger.Debug(
+					fmt.Sprintf("pruned %d block(s)", pruned),
+					"component", "node",
+				)
+			}
+		}
+	}()
+}
@@ -0,0 +1,87 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/blinklabs-io/dingo/chain"
+	"github.com/blinklabs-io/dingo/event"
+	ochainsync "github.com/blinklabs-io/gouroboros/protocol/chainsync"
+)
+
+// TipUpdates returns a channel that receives the new chain tip whenever the local chain moves forward or backward,
+// along with a function to cancel the subscription and close the channel. Updates are coalesced, so a slow consumer
+// only receives the latest tip and any intermediate tips are dropped. A closed channel is returned if the node is not
+// running
+func (n *Node) TipUpdates() (<-chan ochainsync.Tip, func()) {
+	// We only ever hold the latest undelivered tip
+	retCh := make(chan ochainsync.Tip, 1)
+	if n.chainManager == nil {
+		close(retCh)
+		return retCh, func() {}
+	}
+	doneCh := make(chan struct{})
+	// We read the current tip on each event, so it's safe to drop events when we fall behind
+	subId, evtCh := n.eventBus.Subscribe(
+		chain.ChainUpdateEventType,
+		event.WithOverflowPolicy(event.OverflowDrop),
+	)
+	go func() {
+		defer close(retCh)
+		var lastTip ochainsync.Tip
+		for {
+			select {
+			case <-doneCh:
+				return
+			case <-evtCh:
+			}
+			// Events are also generated for fork chains, so we check the primary chain tip directly
+			tip := n.chainManager.PrimaryChain().Tip()
+			if tip.Point.Slot == lastTip.Point.Slot &&
+				bytes.Equal(tip.Point.Hash, lastTip.Point.Hash) {
+				continue
+			}
+			lastTip = tip
+			select {
+			case retCh <- tip:
+			default:
+				// Replace the undelivered tip with the latest. We're the only sender, so there's room after this
+				select {
+				case <-retCh:
+				default:
+				}
+				retCh <- tip
+			}
+		}
+	}()
+	var cancelOnce sync.Once
+	cancelFunc := func() {
+		cancelOnce.Do(func() {
+			n.eventBus.Unsubscribe(chain.ChainUpdateEventType, subId)
+			close(doneCh)
+			// Drain any pending events so publishers don't block on our channel
+			for {
+				select {
+				case <-evtCh:
+				default:
+					return
+				}
+			}
+		})
+	}
+	return retCh, cancelFunc
+}
@@ -20,12 +20,14 @@ import (
 	"os"
 )
 
-// TopologyConfig represents a cardano-node topology config
+// TopologyConfig represents a cardano-node P2P topology config
 type TopologyConfig struct {
-	LocalRoots         []TopologyConfigP2PLocalRoot     `json:"localRoots"`
-	PublicRoots        []TopologyConfigP2PPublicRoot    `json:"publicRoots"`
-	BootstrapPeers     []TopologyConfigP2PBootstrapPeer `json:"bootstrapPeers"`
-	UseLedgerAfterSlot int64                            `json:"useLedgerAfterSlot"`
+	LocalRoots     []TopologyConfigP2PLocalRoot     `json:"localRoots"`
+	PublicRoots    []TopologyConfigP2PPublicRoot    `json:"publicRoots"`
+	BootstrapPeers []TopologyConfigP2PBootstrapPeer `json:"bootstrapPeers"`
+	// Slot after which ledger peers may be used. A negative value disables ledger peers
+	UseLedgerAfterSlot int64  `json:"useLedgerAfterSlot"`
+	PeerSnapshotFile   string `json:"peerSnapshotFile,omitempty"`
 }
 
 type TopologyConfigP2PAccessPoint struct {
@@ -37,7 +39,13 @@ type TopologyConfigP2PLocalRoot struct {
 	AccessPoints []TopologyConfigP2PAccessPoint `json:"accessPoints"`
 	Advertise    bool                           `json:"advertise"`
 	Trustable    bool                           `json:"trustable"`
-	Valency      uint                           `json:"valency"`
+	// Valency is the legacy name for HotValency. Both are populated with the same value when loading a config that
+	// only specifies one of them
+	Valency     uint `json:"valency"`
+	HotValency  uint `json:"hotValency"`
+	WarmValency uint `json:"warmValency"`
+	// Diffusion mode for connections to these peers, either "InitiatorOnly" or "InitiatorAndResponder"
+	DiffusionMode string `json:"diffusionMode,omitempty"`
 }
 
 type TopologyConfigP2PPublicRoot struct {
@@ -53,6 +61,7 @@ func NewTopologyConfigFromFile(path string) (*TopologyConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	defer dataFile.Close() //nolint:errcheck
 	return NewTopologyConfigFromReader(dataFile)
 }
 
@@ -65,5 +74,18 @@ func NewTopologyConfigFromReader(r io.Reader) (*TopologyConfig, error) {
 	if err := json.Unmarshal(data, t); err != nil {
 		return nil, err
 	}
+	// Apply the cardano-node defaults for local root valency
+	for idx := range t.LocalRoots {
+		localRoot := &t.LocalRoots[idx]
+		if localRoot.HotValency == 0 {
+			localRoot.HotValency = localRoot.Valency
+		}
+		if localRoot.Valency == 0 {
+			localRoot.Valency = localRoot.HotValency
+		}
+		if localRoot.WarmValency == 0 {
+			localRoot.WarmValency = localRoot.HotValency
+		}
+	}
 	return t, nil
 }
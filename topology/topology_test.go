@@ -15,6 +15,7 @@
 package topology_test
 
 import (
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -67,6 +68,8 @@ var topologyTests = []topologyTestDefinition{
 					AccessPoints: []topology.TopologyConfigP2PAccessPoint{},
 					Advertise:    false,
 					Valency:      1,
+					HotValency:   1,
+					WarmValency:  1,
 				},
 			},
 			PublicRoots: []topology.TopologyConfigP2PPublicRoot{
@@ -133,6 +136,8 @@ var topologyTests = []topologyTestDefinition{
 					Advertise:    false,
 					Trustable:    false,
 					Valency:      1,
+					HotValency:   1,
+					WarmValency:  1,
 				},
 			},
 			PublicRoots: []topology.TopologyConfigP2PPublicRoot{
@@ -177,3 +182,83 @@ func TestParseTopologyConfig(t *testing.T) {
 		}
 	}
 }
+
+var topologyFileTests = map[string]*topology.TopologyConfig{
+	"mainnet-topology.json": {
+		LocalRoots: []topology.TopologyConfigP2PLocalRoot{
+			{
+				AccessPoints: []topology.TopologyConfigP2PAccessPoint{},
+				Valency:      1,
+				HotValency:   1,
+				WarmValency:  1,
+			},
+		},
+		PublicRoots: []topology.TopologyConfigP2PPublicRoot{
+			{
+				AccessPoints: []topology.TopologyConfigP2PAccessPoint{},
+			},
+		},
+		BootstrapPeers: []topology.TopologyConfigP2PBootstrapPeer{
+			{Address: "backbone.cardano.iog.io", Port: 3001},
+			{Address: "backbone.mainnet.emurgornd.com", Port: 3001},
+			{Address: "backbone.mainnet.cardanofoundation.org", Port: 3001},
+		},
+		UseLedgerAfterSlot: 128908821,
+		PeerSnapshotFile:   "peer-snapshot.json",
+	},
+	"preview-topology.json": {
+		LocalRoots: []topology.TopologyConfigP2PLocalRoot{
+			{
+				AccessPoints: []topology.TopologyConfigP2PAccessPoint{},
+				Valency:      1,
+				HotValency:   1,
+				WarmValency:  1,
+			},
+		},
+		PublicRoots: []topology.TopologyConfigP2PPublicRoot{
+			{
+				AccessPoints: []topology.TopologyConfigP2PAccessPoint{},
+			},
+		},
+		BootstrapPeers: []topology.TopologyConfigP2PBootstrapPeer{
+			{Address: "preview-node.play.dev.cardano.org", Port: 3001},
+		},
+		UseLedgerAfterSlot: 73267000,
+	},
+	"block-producer-topology.json": {
+		LocalRoots: []topology.TopologyConfigP2PLocalRoot{
+			{
+				AccessPoints: []topology.TopologyConfigP2PAccessPoint{
+					{Address: "relay1.example.com", Port: 3001},
+					{Address: "10.0.0.2", Port: 3001},
+				},
+				Trustable:     true,
+				Valency:       2,
+				HotValency:    2,
+				WarmValency:   3,
+				DiffusionMode: "InitiatorOnly",
+			},
+		},
+		PublicRoots:        []topology.TopologyConfigP2PPublicRoot{},
+		UseLedgerAfterSlot: -1,
+	},
+}
+
+func TestParseTopologyConfigFile(t *testing.T) {
+	for fileName, expected := range topologyFileTests {
+		topology, err := topology.NewTopologyConfigFromFile(
+			filepath.Join("testdata", fileName),
+		)
+		if err != nil {
+			t.Fatalf("failed to load TopologyConfig from file %s: %s", fileName, err)
+		}
+		if !reflect.DeepEqual(topology, expected) {
+			t.Fatalf(
+				"did not get expected object for %s\n  got:\n    %#v\n  wanted:\n    %#v",
+				fileName,
+				topology,
+				expected,
+			)
+		}
+	}
+}
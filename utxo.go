@@ -0,0 +1,79 @@
+// Copyright 2025 Blink Labs Software
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dingo
+
+import (
+	"bytes"
+	"fmt"
+
+	gledger "github.com/blinklabs-io/gouroboros/ledger"
+	lcommon "github.com/blinklabs-io/gouroboros/ledger/common"
+)
+
+// Utxo represents an unspent transaction output and the input reference that identifies it
+type Utxo = lcommon.Utxo
+
+// UtxosByAddress returns all unspent outputs at the specified address, which may be provided in bech32 (Shelley) or
+// base58 (Byron) form. For a stake address, all outputs delegated to its stake credential are returned
+func (n *Node) UtxosByAddress(addr string) ([]Utxo, error) {
+	if n.ledgerState == nil {
+		return nil, ErrNodeNotRunning
+	}
+	tmpAddr, err := gledger.NewAddress(addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %w", err)
+	}
+	addrBytes, err := tmpAddr.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("invalid address: %w", err)
+	}
+	// The ledger query matches either the payment or stake component, so we need to filter the results for an exact
+	// match unless a stake address was provided
+	isStakeAddr := tmpAddr.Type() == lcommon.AddressTypeNoneKey ||
+		tmpAddr.Type() == lcommon.AddressTypeNoneScript
+	n.ledgerState.RLock()
+	utxos, err := n.ledgerState.UtxosByAddress(tmpAddr)
+	n.ledgerState.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]Utxo, 0, len(utxos))
+	for _, utxo := range utxos {
+		output, err := utxo.Decode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode UTxO: %w", err)
+		}
+		if !isStakeAddr {
+			outputAddrBytes, err := output.Address().Bytes()
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode UTxO address: %w", err)
+			}
+			if !bytes.Equal(outputAddrBytes, addrBytes) {
+				continue
+			}
+		}
+		ret = append(
+			ret,
+			Utxo{
+				Id: gledger.ShelleyTransactionInput{
+					TxId:        lcommon.NewBlake2b256(utxo.TxId),
+					OutputIndex: utxo.OutputIdx,
+				},
+				Output: output,
+			},
+		)
+	}
+	return ret, nil
+}
@@ -117,6 +117,7 @@ func (s *submitServiceServer) WaitForTx(
 			len(ref),
 		),
 	)
+	// Confirmations are best-effort, so we drop events rather than hold up blockfetch if we fall behind
 	s.utxorpc.config.EventBus.SubscribeFunc(
 		ledger.BlockfetchEventType,
 		func(evt event.Event) {
@@ -156,6 +157,7 @@ func (s *submitServiceServer) WaitForTx(
 				}
 			}
 		},
+		event.WithOverflowPolicy(event.OverflowDrop),
 	)
 	return nil
 }